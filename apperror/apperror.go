@@ -0,0 +1,160 @@
+// Package apperror is the error model of the API. Handlers return or respond
+// with an *Error, which carries a stable machine-readable code, an HTTP
+// status and optional per-field details, and is written as an RFC 7807
+// application/problem+json document.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code identifies a kind of error. Codes are part of the API contract: new
+// ones can be added but existing ones are never renamed.
+type Code string
+
+const (
+	CodeBadRequest         Code = "bad_request"
+	CodeInvalidJSON        Code = "invalid_json"
+	CodeValidationFailed   Code = "validation_failed"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeMethodNotAllowed   Code = "method_not_allowed"
+	CodeConflict           Code = "conflict"
+	CodePayloadTooLarge    Code = "payload_too_large"
+	CodeUnprocessable      Code = "unprocessable_entity"
+	CodeRateLimited        Code = "rate_limited"
+	CodeInternal           Code = "internal_error"
+	CodeBadGateway         Code = "bad_gateway"
+	CodeServiceUnavailable Code = "service_unavailable"
+
+	CodeInvalidToken            Code = "invalid_token"
+	CodeInvalidAPIKey           Code = "invalid_api_key"
+	CodeInsufficientScope       Code = "insufficient_scope"
+	CodeTwoFactorRequired       Code = "two_factor_enrollment_required"
+	CodeAccountLocked           Code = "account_locked"
+	CodeIdempotencyKeyReused    Code = "idempotency_key_reused"
+	CodeIdempotencyKeyInFlight  Code = "idempotency_key_in_progress"
+	CodeInsufficientStock       Code = "insufficient_stock"
+	CodeInvalidStatusTransition Code = "invalid_status_transition"
+)
+
+// statusCodes is the code used for a status when no more specific one is given
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusBadGateway:            CodeBadGateway,
+	http.StatusServiceUnavailable:    CodeServiceUnavailable,
+}
+
+// FieldError describes what is wrong with one field of the request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is an error that knows how it is presented to the client. Cause is
+// logged but never sent.
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	Fields  []FieldError
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithCode replaces the code derived from the status
+func (e *Error) WithCode(code Code) *Error {
+	e.Code = code
+	return e
+}
+
+// WithCause records the underlying error for the logs
+func (e *Error) WithCause(err error) *Error {
+	e.Cause = err
+	return e
+}
+
+// New returns an error with the code that matches status
+func New(status int, message string) *Error {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeInternal
+		if status < http.StatusInternalServerError {
+			code = CodeBadRequest
+		}
+	}
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, message)
+}
+
+func PayloadTooLarge(message string) *Error {
+	return New(http.StatusRequestEntityTooLarge, message)
+}
+
+func Unprocessable(message string) *Error {
+	return New(http.StatusUnprocessableEntity, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(http.StatusTooManyRequests, message)
+}
+
+func Internal(message string) *Error {
+	return New(http.StatusInternalServerError, message)
+}
+
+func BadGateway(message string) *Error {
+	return New(http.StatusBadGateway, message)
+}
+
+// From converts any error into an *Error. Errors that are not one become an
+// internal error that keeps them as the cause.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal("Internal Server Error").WithCause(err)
+}
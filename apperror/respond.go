@@ -0,0 +1,74 @@
+package apperror
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem document. success and message are kept
+// from the earlier error format so existing clients keep working.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Success   bool         `json:"success"`
+	Message   string       `json:"message"`
+}
+
+// TypeURI names the documentation type of a code
+func TypeURI(code Code) string {
+	return "urn:ecommerce-api:error:" + string(code)
+}
+
+// NewProblem builds the document for err as seen by the request in c
+func NewProblem(c *gin.Context, err *Error) Problem {
+	return Problem{
+		Type:      TypeURI(err.Code),
+		Title:     http.StatusText(err.Status),
+		Status:    err.Status,
+		Detail:    err.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      err.Code,
+		RequestID: c.GetString("requestid"),
+		Errors:    err.Fields,
+		Success:   false,
+		Message:   err.Message,
+	}
+}
+
+// Respond writes err as a problem document and aborts the request. Server
+// errors are logged with their cause and request ID.
+func Respond(c *gin.Context, err error) {
+	appErr := From(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		log.Printf("[%s] %s %s: %v", c.GetString("requestid"), c.Request.Method, c.Request.URL.Path, appErr)
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(appErr.Status, NewProblem(c, appErr))
+}
+
+// NoRoute answers requests for paths the API does not have
+func NoRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		Respond(c, NotFound("Route not found"))
+	}
+}
+
+// Recovery turns a panic into an internal error response instead of a
+// dropped connection
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		Respond(c, Internal("Internal Server Error").WithCause(fmt.Errorf("panic: %v", recovered)))
+	})
+}
@@ -0,0 +1,103 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// JSONTagName makes validator report fields by their JSON names. Register it
+// with RegisterTagNameFunc on every validator whose errors reach clients.
+func JSONTagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath drops the struct name validator puts in front of the field
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min", "gte":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return "must have at least " + fe.Param() + " items or characters"
+		}
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return "must have at most " + fe.Param() + " items or characters"
+		}
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "len":
+		return "must have length " + fe.Param()
+	case "url":
+		return "must be a valid URL"
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
+
+// Validation describes why a request body could not be bound or validated.
+// Validation failures list every bad field; malformed JSON is reported as
+// such. message is the summary shown to the client.
+func Validation(err error, message string) *Error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, len(validationErrors))
+		for i, fe := range validationErrors {
+			fields[i] = FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Code:    fe.Tag(),
+				Message: validationMessage(fe),
+			}
+		}
+		return &Error{Status: http.StatusBadRequest, Code: CodeValidationFailed, Message: message, Fields: fields, Cause: err}
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		field := typeError.Field
+		if field == "" {
+			field = "body"
+		}
+		return &Error{
+			Status:  http.StatusBadRequest,
+			Code:    CodeValidationFailed,
+			Message: message,
+			Fields:  []FieldError{{Field: field, Code: "type", Message: "must be a " + typeError.Type.String()}},
+			Cause:   err,
+		}
+	}
+
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return BadRequest(message).WithCode(CodeInvalidJSON).WithCause(err)
+	}
+	return BadRequest(message).WithCause(err)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/seed"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+	"gorm.io/gorm"
+)
+
+// command is a subcommand of the binary
+type command struct {
+	usage   string
+	summary string
+	run     func(args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"serve":          {"serve", "run the HTTP API (the default)", serve},
+		"migrate":        {"migrate up|down [steps]|status|create <name>", "manage the database schema", runMigrate},
+		"create-admin":   {"create-admin -name <name> -email <email> [-password <password>]", "create an admin account", createAdmin},
+		"reset-password": {"reset-password -email <email> [-password <password>]", "set a user's password and lift any lockout", resetPassword},
+		"seed":           {"seed [-seed n] [-users n] [-products n] [-orders n] [-password p]", "fill the database with demo data; safe to run again", runSeed},
+		"recalc-stock":   {"recalc-stock [-dry-run]", "reset stock levels that disagree with the stock ledger", recalcStock},
+	}
+}
+
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("usage: ecommerce-api [command]\n\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%s\n", commands[name].usage, commands[name].summary)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// connect opens the database the same way the server does and builds the
+// services on top of it
+func connect() (*services.Services, error) {
+	if err := database.ConnectDB(); err != nil {
+		return nil, err
+	}
+	return services.New(repository.NewStore(database.DB)), nil
+}
+
+// readPassword returns password, or reads one line from stdin when it is
+// empty so the password stays out of the shell history
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Password: ")
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password given")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func createAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	name := flags.String("name", "", "display name")
+	email := flags.String("email", "", "email address to sign in with")
+	password := flags.String("password", "", "password; read from stdin when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	secret, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+	// Admins follow the same rules as customers signing up
+	input := controllers.SignupInput{Name: *name, Email: *email, Password: secret}
+	if err := validator.New().Struct(input); err != nil {
+		return err
+	}
+
+	app, err := connect()
+	if err != nil {
+		return err
+	}
+	user, err := app.Users.CreateAdmin(context.Background(), services.Registration{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: input.Password,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created admin %s (id %d)\n", user.Email, user.ID)
+	return nil
+}
+
+func resetPassword(args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	email := flags.String("email", "", "email address of the user")
+	password := flags.String("password", "", "new password; read from stdin when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	secret, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+	// The same rule as a password change
+	if err := validator.New().Var(secret, "required,min=6"); err != nil {
+		return fmt.Errorf("password: %w", err)
+	}
+
+	app, err := connect()
+	if err != nil {
+		return err
+	}
+	user, err := app.Users.ResetPassword(context.Background(), *email, secret)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Reset the password of %s (id %d)\n", user.Email, user.ID)
+	return nil
+}
+
+func runSeed(args []string) error {
+	options := seed.DefaultOptions
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.Int64Var(&options.Seed, "seed", options.Seed, "random seed; the same seed and volumes give the same data")
+	flags.IntVar(&options.Users, "users", options.Users, "number of demo customers")
+	flags.IntVar(&options.Products, "products", options.Products, "number of demo products, at least 2")
+	flags.IntVar(&options.Orders, "orders", options.Orders, "number of demo orders")
+	flags.StringVar(&options.Password, "password", options.Password, "password of every demo customer")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := connect()
+	if err != nil {
+		return err
+	}
+	report, err := seed.Run(context.Background(), database.DB, app, options)
+	fmt.Printf("Created %d users, %d products, %d orders and %d cart items\n",
+		report.Users, report.Products, report.Orders, report.CartItems)
+	return err
+}
+
+func recalcStock(args []string) error {
+	flags := flag.NewFlagSet("recalc-stock", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only report the differences")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if _, err := connect(); err != nil {
+		return err
+	}
+
+	var (
+		drifts          []helpers.StockDrift
+		warehouseDrifts []helpers.WarehouseStockDrift
+	)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		drifts, warehouseDrifts, err = helpers.ReconcileStock(tx, !*dryRun)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(drifts) == 0 && len(warehouseDrifts) == 0 {
+		fmt.Println("Every stock level matches the ledger")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PRODUCT\tWAREHOUSE\tSTOCK\tLEDGER")
+	for _, drift := range drifts {
+		fmt.Fprintf(w, "%d\t(total)\t%d\t%d\n", drift.ProductID, drift.Stock, drift.LedgerStock)
+	}
+	for _, drift := range warehouseDrifts {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\n", drift.ProductID, drift.WarehouseID, drift.Quantity, drift.LedgerStock)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Println("Dry run: nothing was changed")
+	} else {
+		fmt.Println("Stock levels were reset to the ledger")
+	}
+	return nil
+}
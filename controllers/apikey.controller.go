@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// APIKeyInput describes a key to issue. Keys without expires_at never
+// expire.
+type APIKeyInput struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// requireSession stops API keys from managing API keys, so a leaked key
+// cannot mint a broader one
+func requireSession(c *gin.Context) bool {
+	if _, usingKey := c.Get("apikeyid"); usingKey {
+		apperror.Respond(c, apperror.Forbidden("API keys cannot manage API keys"))
+		return false
+	}
+	return true
+}
+
+// AdminGetAPIKeyScopes lists the resources keys can be scoped to
+func AdminGetAPIKeyScopes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "API key scopes retrieved successfully",
+			"data": gin.H{
+				"resources": helpers.APIKeyResources,
+				"actions":   []string{helpers.ScopeRead, helpers.ScopeWrite, helpers.ScopeAll},
+			},
+		})
+	}
+}
+
+// AdminGetAPIKeys lists API keys, newest first
+func AdminGetAPIKeys(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		keys, err := apiKeyService.List(ctx, c.Query("active") == "true")
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "API keys retrieved successfully",
+			"data":    dto.NewAPIKeys(keys),
+		})
+	}
+}
+
+// AdminCreateAPIKey issues an API key acting as the calling admin. The key
+// is only shown in this response.
+func AdminCreateAPIKey(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		if !requireSession(c) {
+			return
+		}
+
+		var input APIKeyInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		adminID, _ := c.Get("userid")
+		apiKey, rawKey, err := apiKeyService.Create(ctx, input.Name, input.Scopes, input.ExpiresAt, adminID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "API key created. Store the key now, it will not be shown again",
+			"data": gin.H{
+				"api_key": dto.NewAPIKey(apiKey),
+				"key":     rawKey,
+			},
+		})
+	}
+}
+
+// AdminRevokeAPIKey stops an API key from working
+func AdminRevokeAPIKey(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		if !requireSession(c) {
+			return
+		}
+
+		apiKey, err := apiKeyService.Revoke(ctx, pathID(c, "keyId"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "API key revoked successfully",
+			"data":    dto.NewAPIKey(apiKey),
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 200
+)
+
+// parseTimeFilter accepts either an RFC 3339 timestamp or a YYYY-MM-DD date
+func parseTimeFilter(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// AdminGetAuditLogs lists audit log entries, newest first, filtered by
+// actor_id, action, entity_type, entity_id, request_id and a from/to range
+func AdminGetAuditLogs(auditService *services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := repository.AuditLogFilter{
+			Action:     c.Query("action"),
+			EntityType: c.Query("entity_type"),
+			RequestID:  c.Query("request_id"),
+		}
+		var ok bool
+		if filter.ActorID, ok = queryID(c, "actor_id"); !ok {
+			apperror.Respond(c, apperror.BadRequest("Invalid actor_id"))
+			return
+		}
+		if filter.EntityID, ok = queryID(c, "entity_id"); !ok {
+			apperror.Respond(c, apperror.BadRequest("Invalid entity_id"))
+			return
+		}
+		if from := c.Query("from"); from != "" {
+			fromTime, err := parseTimeFilter(from)
+			if err != nil {
+				apperror.Respond(c, apperror.BadRequest("Invalid from date"))
+				return
+			}
+			filter.From = fromTime
+		}
+		if to := c.Query("to"); to != "" {
+			toTime, err := parseTimeFilter(to)
+			if err != nil {
+				apperror.Respond(c, apperror.BadRequest("Invalid to date"))
+				return
+			}
+			filter.To = toTime
+		}
+
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid page"))
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditLogLimit)))
+		if err != nil || limit < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid limit"))
+			return
+		}
+		if limit > maxAuditLogLimit {
+			limit = maxAuditLogLimit
+		}
+
+		logs, total, err := auditService.Logs(ctx, filter, page, limit)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Audit logs retrieved successfully",
+			"data":    dto.NewAuditLogs(logs),
+			"pagination": gin.H{
+				"page":  page,
+				"limit": limit,
+				"total": total,
+			},
+		})
+	}
+}
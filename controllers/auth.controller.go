@@ -2,128 +2,150 @@ package controllers
 
 import (
 	"context"
-	"log"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/sajagsubedi/Ecommerce-Api/database"
-	"github.com/sajagsubedi/Ecommerce-Api/helpers"
-	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-var validate = validator.New()
+var validate = newValidator()
 
-func Signup() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		db := database.DB.WithContext(ctx)
+// newValidator reports fields by their JSON names so clients can match
+// errors to what they sent
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(apperror.JSONTagName)
+	return v
+}
 
-		user := new(models.User)
-		if err := c.BindJSON(user); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
-			return
-		}
+// pathID reads a record ID from the path. Anything that is not an ID reads
+// as 0, which matches no record, so it is answered like a missing one.
+func pathID(c *gin.Context, name string) uint {
+	id, _ := strconv.ParseUint(c.Param(name), 10, 64)
+	return uint(id)
+}
 
-		if err := validate.Struct(user); err != nil {
-			handleError(c, http.StatusBadRequest, err.(validator.ValidationErrors).Error())
-			return
-		}
+// queryID reads an optional record ID filter from the query string. A
+// missing filter reads as 0; ok is false when the value is not an ID.
+func queryID(c *gin.Context, name string) (id uint, ok bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	return uint(parsed), err == nil
+}
 
-		var existingUser []models.User
-		if err := db.Where("email = ?", user.Email).Find(&existingUser).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Internal Server Error")
-			return
-		}
+// SignupInput is the body of a registration
+type SignupInput struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Email    string `json:"email" validate:"email,required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
 
-		if len(existingUser) > 0 {
-			handleError(c, http.StatusConflict, "User with given email already exists!")
-			return
-		}
+func Signup(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
 
-		hashedPassword, err := user.HashPassword()
-		if err != nil {
-			log.Printf("Failed to hash password: %v", err)
-			handleError(c, http.StatusInternalServerError, "Failed to process password")
+		var input SignupInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
-		user.Password = hashedPassword
-		user.Role = "user"
 
-		if err := db.Create(user).Error; err != nil {
-			log.Printf("Failed to create user: %v", err)
-			handleError(c, http.StatusInternalServerError, "Failed to register user")
+		if err := validate.Struct(input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Validation failed"))
 			return
 		}
 
-		cart := models.Cart{
-			UserID: user.ID,
-			Items:  []models.CartItem{},
-		}
-		if err := db.Create(&cart).Error; err != nil {
-			log.Printf("Failed to create cart: %v", err)
-			handleError(c, http.StatusInternalServerError, "Failed to create cart")
+		user, err := userService.Register(ctx, services.Registration{
+			Name:     input.Name,
+			Email:    input.Email,
+			Password: input.Password,
+		})
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "User registered successfully",
-			"user": gin.H{
-				"id":    user.ID,
-				"name":  user.Name,
-				"email": user.Email,
-				"role":  user.Role,
-			},
+			"user":    dto.NewUser(user),
 		})
 	}
 }
 
-func Signin() gin.HandlerFunc {
+// SigninInput is the body of a password sign-in
+type SigninInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Signin signs a user in. Users with two-factor authentication get a
+// challenge token to pass to VerifyTwoFactor instead of a session. Repeated
+// wrong passwords lock the account for progressively longer.
+func Signin(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-
-		var user models.User
-		if err := c.BindJSON(&user); err != nil {
-			handleError(c, http.StatusInternalServerError, "Internal Server Error")
+		var user SigninInput
+		if err := c.ShouldBindJSON(&user); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
 		if user.Email == "" || user.Password == "" {
-			handleError(c, http.StatusBadRequest, "Please provide all fields!")
-			return
-		}
-
-		var existingUser models.User
-		if err := db.Where("email = ?", user.Email).First(&existingUser).Error; err != nil {
-			handleError(c, http.StatusUnauthorized, "Invalid credentials!")
+			apperror.Respond(c, apperror.BadRequest("Please provide all fields!"))
 			return
 		}
 
-		if !existingUser.ComparePassword(user.Password) {
-			handleError(c, http.StatusUnauthorized, "Invalid credentials!")
-			return
-		}
-
-		token, err := helpers.GenerateToken(existingUser.ID, existingUser.Role)
+		session, err := userService.SignIn(ctx, user.Email, user.Password)
 		if err != nil {
-			handleError(c, http.StatusInternalServerError, "Something went wrong!")
+			respondSignInError(c, err)
 			return
 		}
+		respondSession(c, session)
+	}
+}
 
-		c.SetCookie("Authorization", token, 60*60*24*7, "/", "", false, true)
+// respondSession answers a sign-in with the challenge token when a second
+// factor is still needed and with the session token otherwise
+func respondSession(c *gin.Context, session services.Session) {
+	if session.ChallengeToken != "" {
 		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Logged in successfully!",
-			"token":   token,
+			"success":             true,
+			"message":             "Two-factor authentication required",
+			"two_factor_required": true,
+			"challenge_token":     session.ChallengeToken,
 		})
+		return
+	}
+
+	c.SetCookie("Authorization", session.Token, 60*60*24*7, "/", "", false, true)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Logged in successfully!",
+		"token":   session.Token,
+	})
+}
+
+// respondSignInError tells locked out clients when to try again
+func respondSignInError(c *gin.Context, err error) {
+	var locked *services.AccountLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
 	}
+	apperror.Respond(c, err)
 }
 
 func Signout() gin.HandlerFunc {
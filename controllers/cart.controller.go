@@ -6,164 +6,102 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
 	"github.com/sajagsubedi/Ecommerce-Api/models"
-	"gorm.io/gorm"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-// Reusable error response
-func handleError(c *gin.Context, status int, message string) {
-	c.JSON(status, gin.H{"success": false, "message": message})
-}
-
-func GetCart() gin.HandlerFunc {
+func GetCart(cartService *services.CartService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, _ := c.Get("userid")
-		db := database.DB.WithContext(ctx)
-
-		var cart models.Cart
-		if err := db.Where("user_id = ?", userID).Preload("Items.Product").First(&cart).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Cart not found")
+		cart, err := cartService.Get(ctx, userID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "Cart fetched successfully",
-			"data":    cart,
+			"data":    dto.NewCart(cart),
 		})
 	}
 }
 
-func AddToCart() gin.HandlerFunc {
+func AddToCart(cartService *services.CartService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		userID, _ := c.Get("userid")
-		db := database.DB.WithContext(ctx)
-
 		var cartItem models.CartItem
 		if err := c.ShouldBindJSON(&cartItem); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
 		if err := validate.Struct(cartItem); err != nil {
-			handleError(c, http.StatusBadRequest, err.Error())
+			apperror.Respond(c, apperror.Validation(err, "Validation failed"))
 			return
 		}
 
-		var cart models.Cart
-		if err := db.Where("user_id = ?", userID).First(&cart).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Cart not found")
-			return
-		}
-
-		var existingItem models.CartItem
-		err := db.Where("cart_id = ? AND product_id = ?", cart.ID, cartItem.ProductID).First(&existingItem).Error
-
-		if err == nil {
-			existingItem.Quantity += cartItem.Quantity
-			if err := db.Save(&existingItem).Error; err != nil {
-				handleError(c, http.StatusInternalServerError, "Failed to update cart item")
-				return
-			}
-		} else if err == gorm.ErrRecordNotFound {
-			cartItem.CartID = cart.ID
-			if err := db.Create(&cartItem).Error; err != nil {
-				handleError(c, http.StatusInternalServerError, "Failed to add cart item")
-				return
-			}
-		} else {
-			handleError(c, http.StatusInternalServerError, "Database error")
-			return
-		}
-
-		// Return updated cart
-		var updatedCart models.Cart
-		if err := db.Where("user_id = ?", userID).Preload("Items.Product").First(&updatedCart).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to fetch updated cart")
+		userID, _ := c.Get("userid")
+		updatedCart, err := cartService.AddItem(ctx, userID.(uint), cartItem.ProductID, cartItem.Quantity)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
 			"success": true,
 			"message": "Product added to cart",
-			"data":    updatedCart,
+			"data":    dto.NewCart(updatedCart),
 		})
 	}
 }
 
-func UpdateCartItemQuantity() gin.HandlerFunc {
+// CartQuantityInput is the body of a cart item quantity change
+type CartQuantityInput struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+func UpdateCartItemQuantity(cartService *services.CartService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		cartItemID := c.Param("cartItemId")
-		userID, _ := c.Get("userid")
-		db := database.DB.WithContext(ctx)
-
-		var payload struct {
-			Quantity int `json:"quantity" binding:"required,min=1"`
-		}
+		var payload CartQuantityInput
 		if err := c.ShouldBindJSON(&payload); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
-		var cartItem models.CartItem
-		if err := db.Preload("Product").First(&cartItem, "id = ?", cartItemID).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Cart item not found")
-			return
-		}
-
-		var cart models.Cart
-		if err := db.First(&cart, "user_id = ?", userID).Error; err != nil || cart.ID != cartItem.CartID {
-			handleError(c, http.StatusForbidden, "Unauthorized access to cart item")
-			return
-		}
-
-		cartItem.Quantity = payload.Quantity
-		if err := db.Save(&cartItem).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to update cart item")
+		userID, _ := c.Get("userid")
+		cartItem, err := cartService.UpdateItemQuantity(ctx, userID.(uint), pathID(c, "cartItemId"), payload.Quantity)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "Cart item updated",
-			"data":    cartItem,
+			"data":    dto.NewCartItem(cartItem),
 		})
 	}
 }
 
-func DeleteCartItem() gin.HandlerFunc {
+func DeleteCartItem(cartService *services.CartService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		cartItemID := c.Param("cartItemId")
 		userID, _ := c.Get("userid")
-		db := database.DB.WithContext(ctx)
-
-		var cartItem models.CartItem
-		if err := db.First(&cartItem, "id = ?", cartItemID).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Cart item not found")
-			return
-		}
-
-		var cart models.Cart
-		if err := db.First(&cart, "user_id = ?", userID).Error; err != nil || cart.ID != cartItem.CartID {
-			handleError(c, http.StatusForbidden, "Unauthorized access to cart item")
-			return
-		}
-
-		if err := db.Delete(&cartItem).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to delete cart item")
+		if err := cartService.RemoveItem(ctx, userID.(uint), pathID(c, "id")); err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/jobs"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	maxImportFileSize      = 20 << 20
+	defaultImportSyncLimit = 200
+	exportBatchSize        = 500
+)
+
+// importSyncLimit is the number of rows above which an import runs in the
+// background; set with PRODUCT_IMPORT_SYNC_LIMIT
+func importSyncLimit() int {
+	if limit, err := strconv.Atoi(os.Getenv("PRODUCT_IMPORT_SYNC_LIMIT")); err == nil && limit >= 0 {
+		return limit
+	}
+	return defaultImportSyncLimit
+}
+
+// detectImportFormat picks the file format from the format query parameter,
+// then the file extension, then the content type
+func detectImportFormat(c *gin.Context, fileName, contentType string) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		if format == "jsonl" {
+			return helpers.ImportFormatNDJSON
+		}
+		return format
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return helpers.ImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return helpers.ImportFormatNDJSON
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return helpers.ImportFormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"), strings.HasPrefix(contentType, "application/jsonl"):
+		return helpers.ImportFormatNDJSON
+	}
+	return ""
+}
+
+// ImportProducts upserts products by SKU from a CSV or NDJSON file, sent
+// either as the "file" field of a multipart form or as the raw body. With
+// dry_run=true the file is only validated. Large files are processed in the
+// background and answered with 202 and the job to poll.
+func ImportProducts(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+		defer cancel()
+
+		userID, _ := c.Get("userid")
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+		var (
+			body        io.Reader
+			fileName    string
+			contentType = c.ContentType()
+		)
+		if strings.HasPrefix(contentType, "multipart/form-data") {
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				apperror.Respond(c, apperror.BadRequest("Missing import file"))
+				return
+			}
+			file, err := fileHeader.Open()
+			if err != nil {
+				apperror.Respond(c, apperror.BadRequest("Failed to read import file"))
+				return
+			}
+			defer file.Close()
+			body, fileName, contentType = file, fileHeader.Filename, fileHeader.Header.Get("Content-Type")
+		} else {
+			body = c.Request.Body
+		}
+
+		format := detectImportFormat(c, fileName, contentType)
+		if format != helpers.ImportFormatCSV && format != helpers.ImportFormatNDJSON {
+			apperror.Respond(c, apperror.BadRequest("Unsupported import format, use csv or ndjson"))
+			return
+		}
+
+		rows, rowErrors, err := helpers.ParseProductImport(body, format)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				apperror.Respond(c, apperror.PayloadTooLarge("Import file is too large"))
+				return
+			}
+			apperror.Respond(c, apperror.BadRequest("Invalid import file: "+err.Error()))
+			return
+		}
+
+		dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+		job := models.ImportJob{
+			Format:    format,
+			FileName:  fileName,
+			DryRun:    dryRun,
+			Status:    models.ImportStatusPending,
+			CreatedBy: userID.(uint),
+		}
+		if err := productService.CreateImportJob(ctx, &job); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		if dryRun {
+			if err := jobs.DryRunProductImport(ctx, &job, rows, rowErrors); err != nil {
+				apperror.Respond(c, apperror.Internal("Failed to validate import"))
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"message": "Import validated, no changes were made",
+				"data":    dto.NewImportJob(job),
+			})
+			return
+		}
+
+		if len(rows) > importSyncLimit() {
+			meta, _ := helpers.AuditMetaFromContext(c.Request.Context())
+			go jobs.RunProductImport(helpers.WithAuditMeta(context.Background(), meta), &job, rows, rowErrors)
+
+			c.JSON(http.StatusAccepted, gin.H{
+				"success":    true,
+				"message":    "Import started",
+				"job_id":     job.ID,
+				"status_url": fmt.Sprintf("/api/v1/admin/products/import/%d", job.ID),
+			})
+			return
+		}
+
+		jobs.RunProductImport(ctx, &job, rows, rowErrors)
+		if job.Status == models.ImportStatusFailed {
+			apperror.Respond(c, apperror.Internal(job.Message))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Import completed",
+			"data":    dto.NewImportJob(job),
+		})
+	}
+}
+
+// GetImportJob returns the status and row errors of an import job
+func GetImportJob(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		job, err := productService.ImportJob(ctx, pathID(c, "jobId"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Import job fetched successfully",
+			"data":    dto.NewImportJob(job),
+		})
+	}
+}
+
+// ExportProducts streams the full catalog as CSV or NDJSON in the same
+// shape ImportProducts accepts
+func ExportProducts(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := strings.ToLower(c.DefaultQuery("format", helpers.ImportFormatCSV))
+		if format == "jsonl" {
+			format = helpers.ImportFormatNDJSON
+		}
+
+		var contentType string
+		switch format {
+		case helpers.ImportFormatCSV:
+			contentType = "text/csv; charset=utf-8"
+		case helpers.ImportFormatNDJSON:
+			contentType = "application/x-ndjson"
+		default:
+			apperror.Respond(c, apperror.BadRequest("Unsupported export format, use csv or ndjson"))
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"products-%s.%s\"", time.Now().Format("20060102"), format))
+		c.Status(http.StatusOK)
+
+		csvWriter := csv.NewWriter(c.Writer)
+		encoder := json.NewEncoder(c.Writer)
+		if format == helpers.ImportFormatCSV {
+			csvWriter.Write(helpers.ProductImportColumns)
+		}
+
+		err := productService.Export(c.Request.Context(), exportBatchSize, func(products []models.Product) error {
+			for _, product := range products {
+				if format == helpers.ImportFormatCSV {
+					csvWriter.Write(helpers.ProductCSVRecord(product))
+					continue
+				}
+				if err := encoder.Encode(helpers.ProductImportRow{
+					SKU:         product.SKU,
+					Name:        product.Name,
+					Description: product.Description,
+					Price:       product.Price,
+					Category:    product.Category,
+					ImageURL:    product.ImageURL,
+					Stock:       product.Stock,
+					IsAvailable: product.IsAvailable,
+					Attributes:  product.Attributes,
+				}); err != nil {
+					return err
+				}
+			}
+			csvWriter.Flush()
+			c.Writer.Flush()
+			return csvWriter.Error()
+		})
+		if err != nil {
+			// Headers are already sent, so the best we can do is stop the stream
+			c.Error(err)
+		}
+	}
+}
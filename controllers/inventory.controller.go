@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	defaultMovementLimit = 50
+	maxMovementLimit     = 200
+)
+
+// StockAdjustmentInput represents a manual change to a product's stock.
+// Without a warehouse the default one is adjusted.
+type StockAdjustmentInput struct {
+	WarehouseID uint   `json:"warehouse_id"`
+	Delta       int    `json:"delta" binding:"required"`
+	Reason      string `json:"reason" binding:"required,oneof=adjustment return"`
+	Note        string `json:"note" binding:"max=500"`
+}
+
+// AdminAdjustStock records a manual stock movement for a product
+func AdminAdjustStock(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		productID, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+		if err != nil {
+			apperror.Respond(c, apperror.BadRequest("Invalid product id"))
+			return
+		}
+
+		var input StockAdjustmentInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		adminID, _ := c.Get("userid")
+		movement, err := productService.AdjustStock(ctx, helpers.StockChange{
+			ProductID:   uint(productID),
+			WarehouseID: input.WarehouseID,
+			Delta:       input.Delta,
+			Reason:      input.Reason,
+			Note:        input.Note,
+			ActorID:     adminID.(uint),
+		})
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "Stock adjusted successfully",
+			"data":    dto.NewStockMovement(movement),
+		})
+	}
+}
+
+// AdminGetStockMovements lists the ledger of a product, newest first
+func AdminGetStockMovements(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid page"))
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultMovementLimit)))
+		if err != nil || limit < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid limit"))
+			return
+		}
+		if limit > maxMovementLimit {
+			limit = maxMovementLimit
+		}
+
+		movements, total, err := productService.Movements(ctx, pathID(c, "productId"), c.Query("reason"), page, limit)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Stock movements retrieved successfully",
+			"data":    dto.NewStockMovements(movements),
+			"pagination": gin.H{
+				"page":  page,
+				"limit": limit,
+				"total": total,
+			},
+		})
+	}
+}
+
+// AdminGetLowStockProducts lists products at or below their low-stock threshold
+func AdminGetLowStockProducts(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		products, err := productService.LowStock(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Low stock products retrieved successfully",
+			"data":    dto.NewProducts(products),
+		})
+	}
+}
+
+// AdminReconcileStock compares every product's stock, and every warehouse
+// stock level, with the sum of its ledger. With apply=true, drifting values
+// are reset to the ledger value.
+func AdminReconcileStock(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+		defer cancel()
+
+		apply, _ := strconv.ParseBool(c.Query("apply"))
+		drifts, warehouseDrifts, err := productService.ReconcileStock(ctx, apply)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		message := "Stock reconciliation report generated"
+		if apply {
+			message = "Stock reconciled with the ledger"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"success":    true,
+			"message":    message,
+			"applied":    apply,
+			"data":       drifts,
+			"warehouses": warehouseDrifts,
+		})
+	}
+}
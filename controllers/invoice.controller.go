@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// renderOrderInvoice renders the invoice of an order. A non-zero userID
+// restricts it to that customer's orders.
+func renderOrderInvoice(c *gin.Context, orderService *services.OrderService, userID uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	branding := helpers.StoreBrandingFromEnv()
+	invoice, order, err := orderService.Invoice(ctx, pathID(c, "id"), userID, branding)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	document := helpers.RenderInvoicePDF(branding, invoice, &order, order.User.Name)
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", invoice.Number+".pdf"))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
+// GetUserOrderInvoice renders the invoice of one of the authenticated user's orders
+func GetUserOrderInvoice(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userid")
+		if !exists {
+			apperror.Respond(c, apperror.Unauthorized("User not authenticated"))
+			return
+		}
+		renderOrderInvoice(c, orderService, userID.(uint))
+	}
+}
+
+// AdminGetOrderInvoice renders the invoice of any order for admin users
+func AdminGetOrderInvoice(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		renderOrderInvoice(c, orderService, 0)
+	}
+}
+
+// AdminGetPackingSlip renders the packing slip of an order for admin users
+func AdminGetPackingSlip(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		order, err := orderService.Document(ctx, pathID(c, "id"), 0)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		document := helpers.RenderPackingSlipPDF(helpers.StoreBrandingFromEnv(), &order, order.User.Name)
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"packing-slip-%d.pdf\"", order.ID))
+		c.Data(http.StatusOK, "application/pdf", document)
+	}
+}
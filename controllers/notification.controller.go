@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	defaultEmailLimit = 50
+	maxEmailLimit     = 200
+)
+
+// NotificationPreferenceInput represents the emails a user wants. Fields
+// left out of the request are not changed.
+type NotificationPreferenceInput struct {
+	OrderEmails    *bool `json:"order_emails"`
+	ShippingEmails *bool `json:"shipping_emails"`
+}
+
+// GetNotificationPreferences returns the email preferences of the
+// authenticated user
+func GetNotificationPreferences(notificationService *services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userID, _ := c.Get("userid")
+		preference, err := notificationService.Preferences(ctx, userID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Notification preferences fetched successfully",
+			"data":    dto.NewNotificationPreference(preference),
+		})
+	}
+}
+
+// UpdateNotificationPreferences changes the email preferences of the
+// authenticated user
+func UpdateNotificationPreferences(notificationService *services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var input NotificationPreferenceInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		userID, _ := c.Get("userid")
+		preference, err := notificationService.UpdatePreferences(ctx, userID.(uint), services.NotificationChanges{
+			OrderEmails:    input.OrderEmails,
+			ShippingEmails: input.ShippingEmails,
+		})
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Notification preferences updated successfully",
+			"data":    dto.NewNotificationPreference(preference),
+		})
+	}
+}
+
+// AdminGetEmails lists the mail queue, newest first, filtered by status,
+// template and user_id
+func AdminGetEmails(notificationService *services.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userID, ok := queryID(c, "user_id")
+		if !ok {
+			apperror.Respond(c, apperror.BadRequest("Invalid user_id"))
+			return
+		}
+		filter := repository.EmailFilter{
+			Status:   c.Query("status"),
+			Template: c.Query("template"),
+			UserID:   userID,
+		}
+
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid page"))
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultEmailLimit)))
+		if err != nil || limit < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid limit"))
+			return
+		}
+		if limit > maxEmailLimit {
+			limit = maxEmailLimit
+		}
+
+		emails, total, err := notificationService.Emails(ctx, filter, page, limit)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Emails retrieved successfully",
+			"data":    dto.NewEmailMessages(emails),
+			"pagination": gin.H{
+				"page":  page,
+				"limit": limit,
+				"total": total,
+			},
+		})
+	}
+}
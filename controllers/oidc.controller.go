@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/oidc"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	oidcLoginLifetime = 10 * time.Minute
+	// oidcStateCookie ties a sign-in to the browser that started it, so a
+	// callback URL from someone else's sign-in is rejected
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc/"
+)
+
+// GetOIDCProviders lists the identity providers users can sign in with
+func GetOIDCProviders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Providers retrieved successfully",
+			"data":    oidc.Names(),
+		})
+	}
+}
+
+// OIDCLogin sends the user to the provider to sign in
+func OIDCLogin(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		provider, ok := oidc.Lookup(c.Param("provider"))
+		if !ok {
+			apperror.Respond(c, apperror.NotFound("Unknown identity provider"))
+			return
+		}
+
+		state, errState := oidc.RandomString()
+		nonce, errNonce := oidc.RandomString()
+		verifier, errVerifier := oidc.RandomString()
+		if err := errors.Join(errState, errNonce, errVerifier); err != nil {
+			apperror.Respond(c, apperror.Internal("Something went wrong!"))
+			return
+		}
+
+		login := models.OIDCLoginState{
+			State:     state,
+			Provider:  provider.Name,
+			Nonce:     nonce,
+			Verifier:  verifier,
+			ExpiresAt: time.Now().Add(oidcLoginLifetime),
+		}
+		if err := userService.StartOIDCLogin(ctx, &login); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		url, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+		if err != nil {
+			log.Printf("Failed to reach identity provider %s: %v", provider.Name, err)
+			apperror.Respond(c, apperror.BadGateway("Identity provider is unavailable"))
+			return
+		}
+		// Lax still sends the cookie on the provider's top-level redirect back
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(oidcStateCookie, state, int(oidcLoginLifetime.Seconds()), oidcCookiePath, "", c.Request.TLS != nil, true)
+		c.Redirect(http.StatusFound, url)
+	}
+}
+
+// OIDCCallback finishes a sign-in the provider sent back
+func OIDCCallback(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		if providerError := c.Query("error"); providerError != "" {
+			apperror.Respond(c, apperror.BadRequest(fmt.Sprintf("Sign-in was not completed: %s", providerError)))
+			return
+		}
+
+		provider, ok := oidc.Lookup(c.Param("provider"))
+		if !ok {
+			apperror.Respond(c, apperror.NotFound("Unknown identity provider"))
+			return
+		}
+
+		// Only the browser that started the sign-in may finish it
+		state := c.Query("state")
+		cookie, err := c.Cookie(oidcStateCookie)
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", c.Request.TLS != nil, true)
+		if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+			apperror.Respond(c, apperror.BadRequest("Sign-in was not started in this browser"))
+			return
+		}
+
+		login, err := userService.FinishOIDCLogin(ctx, provider.Name, state)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		claims, err := provider.Exchange(ctx, c.Query("code"), login.Verifier, login.Nonce)
+		if err != nil {
+			log.Printf("Failed to complete sign-in with %s: %v", provider.Name, err)
+			apperror.Respond(c, apperror.Unauthorized("Failed to verify identity with the provider"))
+			return
+		}
+
+		session, err := userService.SignInWithIdentity(ctx, services.Identity{
+			Provider:      provider.Name,
+			Subject:       claims.Subject,
+			Email:         claims.Email,
+			EmailVerified: claims.EmailVerified,
+			Name:          claims.Name,
+		})
+		if err != nil {
+			respondSignInError(c, err)
+			return
+		}
+		respondSession(c, session)
+	}
+}
+
+// GetUserIdentities lists the identity providers linked to the
+// authenticated user
+func GetUserIdentities(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userID, _ := c.Get("userid")
+
+		identities, err := userService.Identities(ctx, userID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Identities retrieved successfully",
+			"data":    dto.NewUserIdentities(identities),
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+)
+
+// GetOpenAPISpec serves the OpenAPI description of the API
+func GetOpenAPISpec(document *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, document)
+	}
+}
+
+// GetAPIDocs serves the bundled API reference page
+func GetAPIDocs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage)
+	}
+}
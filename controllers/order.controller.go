@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
 	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
 // OrderItemInput represents the input for an order item
@@ -34,116 +36,41 @@ type CreateOrderInput struct {
 }
 
 // CreateOrder handles the creation of a new order
-func CreateOrder() gin.HandlerFunc {
+func CreateOrder(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, exists := c.Get("userid")
 		if !exists {
-			handleError(c, http.StatusUnauthorized, "User not authenticated")
+			apperror.Respond(c, apperror.Unauthorized("User not authenticated"))
 			return
 		}
 
 		var input CreateOrderInput
 		if err := c.ShouldBindJSON(&input); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid input data")
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
 			return
 		}
 
-		db := database.DB.WithContext(ctx)
-		tx := db.Begin()
-		defer func() {
-			if r := recover(); r != nil {
-				tx.Rollback()
-				handleError(c, http.StatusInternalServerError, "Internal server error")
-			}
-		}()
-
-		// Create order
-		order := models.Order{
+		checkout := services.CheckoutInput{
 			ContactNumber: input.ContactNumber,
-			Status:        models.OrderStatusPending,
-			UserID:        userID.(uint),
-			TotalAmount:   0,
+			ShippingAddress: models.ShippingAddress{
+				Street:  input.ShippingAddress.Street,
+				City:    input.ShippingAddress.City,
+				State:   input.ShippingAddress.State,
+				Country: input.ShippingAddress.Country,
+				ZipCode: input.ShippingAddress.ZipCode,
+				Notes:   input.ShippingAddress.Notes,
+			},
 		}
-		if err := tx.Create(&order).Error; err != nil {
-			tx.Rollback()
-			handleError(c, http.StatusInternalServerError, "Failed to create order")
-			return
-		}
-
-		// Create shipping address
-		shippingAddress := models.ShippingAddress{
-			Street:  input.ShippingAddress.Street,
-			City:    input.ShippingAddress.City,
-			State:   input.ShippingAddress.State,
-			Country: input.ShippingAddress.Country,
-			ZipCode: input.ShippingAddress.ZipCode,
-			Notes:   input.ShippingAddress.Notes,
-			OrderID: order.ID,
-		}
-		if err := tx.Create(&shippingAddress).Error; err != nil {
-			tx.Rollback()
-			handleError(c, http.StatusInternalServerError, "Failed to create shipping address")
-			return
-		}
-
-		// Create order items and update product stock
-		var totalAmount float64
 		for _, item := range input.Items {
-			var product models.Product
-			if err := tx.First(&product, item.ProductID).Error; err != nil {
-				tx.Rollback()
-				handleError(c, http.StatusNotFound, "Product not found")
-				return
-			}
-
-			if !product.IsAvailable {
-				tx.Rollback()
-				handleError(c, http.StatusBadRequest, "Product is not available")
-				return
-			}
-
-			if product.Stock < item.Quantity {
-				tx.Rollback()
-				handleError(c, http.StatusBadRequest, "Insufficient stock for product")
-				return
-			}
-
-			product.Stock -= item.Quantity
-			if err := tx.Save(&product).Error; err != nil {
-				tx.Rollback()
-				handleError(c, http.StatusInternalServerError, "Failed to update product stock")
-				return
-			}
-
-			itemPrice := product.Price * float64(item.Quantity)
-			orderItem := models.OrderItem{
-				OrderID:   order.ID,
-				ProductID: item.ProductID,
-				Quantity:  item.Quantity,
-				Price:     itemPrice,
-			}
-			if err := tx.Create(&orderItem).Error; err != nil {
-				tx.Rollback()
-				handleError(c, http.StatusInternalServerError, "Failed to create order item")
-				return
-			}
-			totalAmount += itemPrice
+			checkout.Items = append(checkout.Items, services.CheckoutItem{ProductID: item.ProductID, Quantity: item.Quantity})
 		}
 
-		// Update order with total amount and shipping address
-		order.TotalAmount = totalAmount
-		if err := tx.Save(&order).Error; err != nil {
-			tx.Rollback()
-			handleError(c, http.StatusInternalServerError, "Failed to update order")
-			return
-		}
-
-		if err := tx.Commit().Error; err != nil {
-			tx.Rollback()
-			handleError(c, http.StatusInternalServerError, "Failed to commit transaction")
+		order, err := orderService.Checkout(ctx, userID.(uint), checkout)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
@@ -156,88 +83,76 @@ func CreateOrder() gin.HandlerFunc {
 }
 
 // GetUserOrders retrieves all orders for the authenticated user
-func GetUserOrders() gin.HandlerFunc {
+func GetUserOrders(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, exists := c.Get("userid")
 		if !exists {
-			handleError(c, http.StatusUnauthorized, "User not authenticated")
+			apperror.Respond(c, apperror.Unauthorized("User not authenticated"))
 			return
 		}
 
-		db := database.DB.WithContext(ctx)
-		var orders []models.Order
-		if err := db.Where("user_id = ?", userID).Preload("ShippingAddress").Preload("Items.Product").Find(&orders).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to fetch orders")
+		orders, err := orderService.UserOrders(ctx, userID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"data":    orders,
+			"data":    dto.NewOrders(orders),
 			"message": "Orders retrieved successfully",
 		})
 	}
 }
 
 // GetUserOrderByID retrieves a specific order by ID for the authenticated user
-func GetUserOrderByID() gin.HandlerFunc {
+func GetUserOrderByID(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, exists := c.Get("userid")
 		if !exists {
-			handleError(c, http.StatusUnauthorized, "User not authenticated")
+			apperror.Respond(c, apperror.Unauthorized("User not authenticated"))
 			return
 		}
 
-		orderID := c.Param("id")
-		db := database.DB.WithContext(ctx)
-		var order models.Order
-		if err := db.Where("user_id = ? AND id = ?", userID, orderID).Preload("ShippingAddress").Preload("Items.Product").First(&order).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Order not found")
+		order, err := orderService.UserOrder(ctx, userID.(uint), pathID(c, "id"))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
+		// Customers see their shipments through the tracking section only
+		tracking := buildOrderTracking(order)
+		order.Shipments = nil
+
 		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"data":    order,
-			"message": "Order retrieved successfully",
+			"success":  true,
+			"data":     dto.NewOrder(order),
+			"tracking": tracking,
+			"message":  "Order retrieved successfully",
 		})
 	}
 }
 
 // CancelUserOrder cancels a pending order for the authenticated user
-func CancelUserOrder() gin.HandlerFunc {
+func CancelUserOrder(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, exists := c.Get("userid")
 		if !exists {
-			handleError(c, http.StatusUnauthorized, "User not authenticated")
+			apperror.Respond(c, apperror.Unauthorized("User not authenticated"))
 			return
 		}
 
-		orderID := c.Param("id")
-		db := database.DB.WithContext(ctx)
-		var order models.Order
-		if err := db.Where("user_id = ? AND id = ?", userID, orderID).First(&order).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Order not found")
-			return
-		}
-
-		if order.Status != models.OrderStatusPending {
-			handleError(c, http.StatusBadRequest, "Only pending orders can be cancelled")
-			return
-		}
-
-		order.Status = models.OrderStatusCancelled
-		if err := db.Save(&order).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to cancel order")
+		if err := orderService.Cancel(ctx, userID.(uint), pathID(c, "id")); err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
@@ -249,138 +164,126 @@ func CancelUserOrder() gin.HandlerFunc {
 }
 
 // AdminGetAllOrders retrieves all orders for admin users
-func AdminGetAllOrders() gin.HandlerFunc {
+func AdminGetAllOrders(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		var orders []models.Order
-		if err := db.Preload("ShippingAddress").Preload("Items.Product").Find(&orders).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to fetch orders")
+		orders, err := orderService.List(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"data":    orders,
+			"data":    dto.NewOrders(orders),
 			"message": "Orders retrieved successfully",
 		})
 	}
 }
 
 // AdminGetOrderByID retrieves a specific order by ID for admin users
-func AdminGetOrderByID() gin.HandlerFunc {
+func AdminGetOrderByID(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		orderID := c.Param("id")
-		db := database.DB.WithContext(ctx)
-		var order models.Order
-		if err := db.Where("id = ?", orderID).Preload("ShippingAddress").Preload("Items.Product").First(&order).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Order not found")
+		order, err := orderService.Get(ctx, pathID(c, "id"))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"data":    order,
+			"data":    dto.NewOrder(order),
 			"message": "Order retrieved successfully",
 		})
 	}
 }
 
 // AdminGetOrdersByUserID retrieves all orders for a specific user for admin users
-func AdminGetOrdersByUserID() gin.HandlerFunc {
+func AdminGetOrdersByUserID(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		userID := c.Param("user_id")
-		db := database.DB.WithContext(ctx)
-		var orders []models.Order
-		if err := db.Where("user_id = ?", userID).Preload("ShippingAddress").Preload("Items.Product").Find(&orders).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Orders not found for this user")
+		orders, err := orderService.ListByUser(ctx, pathID(c, "user_id"))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"data":    orders,
+			"data":    dto.NewOrders(orders),
 			"message": "Orders retrieved successfully",
 		})
 	}
 }
 
 // AdminGetAllOrderItems retrieves all order items for admin users
-func AdminGetAllOrderItems() gin.HandlerFunc {
+func AdminGetAllOrderItems(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		var orderItems []models.OrderItem
-		if err := db.Preload("Product").Find(&orderItems).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to fetch order items")
+		orderItems, err := orderService.Items(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"data":    orderItems,
+			"data":    dto.NewOrderItems(orderItems),
 			"message": "Order items retrieved successfully",
 		})
 	}
 }
 
 // AdminGetOrderItemsByProductID retrieves order items by product ID for admin users
-func AdminGetOrderItemsByProductID() gin.HandlerFunc {
+func AdminGetOrderItemsByProductID(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		productID := c.Param("product_id")
-		db := database.DB.WithContext(ctx)
-		var orderItems []models.OrderItem
-		if err := db.Where("product_id = ?", productID).Preload("Product").Find(&orderItems).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Order items not found for this product")
+		orderItems, err := orderService.ItemsByProduct(ctx, pathID(c, "product_id"))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"data":    orderItems,
+			"data":    dto.NewOrderItems(orderItems),
 			"message": "Order items retrieved successfully",
 		})
 	}
 }
 
+// OrderStatusInput is the body of an admin order status change. Shipped and
+// delivered follow from the shipments of the order.
+type OrderStatusInput struct {
+	Status string `json:"status" binding:"required,oneof=processing cancelled"`
+}
+
 // AdminUpdateOrderStatus updates the status of an order for admin users
-func AdminUpdateOrderStatus() gin.HandlerFunc {
+func AdminUpdateOrderStatus(orderService *services.OrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 		defer cancel()
 
-		orderID := c.Param("id")
-		db := database.DB.WithContext(ctx)
-		var order models.Order
-		if err := db.Where("id = ?", orderID).First(&order).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Order not found")
-			return
-		}
-
-		var input struct {
-			Status string `json:"status" binding:"required,oneof=pending processing shipped delivered cancelled"`
-		}
+		var input OrderStatusInput
 		if err := c.ShouldBindJSON(&input); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid input data")
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
 			return
 		}
 
-		order.Status = input.Status
-		if err := db.Save(&order).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to update order status")
+		adminID, _ := c.Get("userid")
+		if err := orderService.UpdateStatus(ctx, pathID(c, "id"), input.Status, adminID.(uint)); err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
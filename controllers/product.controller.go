@@ -2,169 +2,159 @@ package controllers
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
 	"github.com/sajagsubedi/Ecommerce-Api/models"
-	"gorm.io/gorm"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-func GetAllProducts() gin.HandlerFunc {
+func GetAllProducts(productService *services.ProductService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		var products []models.Product
-
-		if err := db.Find(&products).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to fetch products")
+		products, err := productService.List(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "Products fetched successfully!",
-			"data":    products,
+			"data":    dto.NewProducts(products),
 		})
 	}
 }
 
-func GetProductById() gin.HandlerFunc {
+func GetProductById(productService *services.ProductService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		productId := c.Param("productId")
-
-		var product models.Product
-		err := db.Where("id = ?", productId).First(&product).Error
+		product, err := productService.Get(ctx, pathID(c, "productId"))
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				handleError(c, http.StatusNotFound, "Product not found")
-				return
-			}
-			handleError(c, http.StatusInternalServerError, "Failed to fetch product")
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "Product fetched successfully!",
-			"data":    product,
+			"data":    dto.NewProduct(product),
 		})
 	}
 }
 
-func CreateProduct() gin.HandlerFunc {
+func CreateProduct(productService *services.ProductService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
 		var product models.Product
-
-		if err := c.BindJSON(&product); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
+		if err := c.ShouldBindJSON(&product); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
-		if err := db.Create(&product).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to create product")
+		adminID, _ := c.Get("userid")
+		product, err := productService.Create(ctx, product, adminID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
 			"success": true,
 			"message": "Product created successfully!",
-			"data":    product,
+			"data":    dto.NewProduct(product),
 		})
 	}
 }
 
-func UpdateProduct() gin.HandlerFunc {
+func UpdateProduct(productService *services.ProductService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		productId := c.Param("productId")
-
 		var updatedData models.Product
-		if err := c.BindJSON(&updatedData); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
+		if err := c.ShouldBindJSON(&updatedData); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
-		// First check if the product exists
-		var existingProduct models.Product
-		if err := db.Where("id = ?", productId).First(&existingProduct).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				handleError(c, http.StatusNotFound, "Product not found")
-				return
-			}
-			handleError(c, http.StatusInternalServerError, "Failed to fetch product")
+		adminID, _ := c.Get("userid")
+		product, err := productService.Update(ctx, pathID(c, "productId"), updatedData, adminID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
-		// Now update the product
-		if err := db.Model(&existingProduct).Updates(updatedData).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to update product")
-			return
-		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Product updated successfully!",
+			"data":    dto.NewProduct(product),
+		})
+	}
+}
 
-		// Fetch updated product
-		if err := db.Where("id = ?", productId).First(&existingProduct).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to fetch updated product")
+func DeleteProduct(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := productService.Delete(ctx, pathID(c, "productId")); err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"message": "Product updated successfully!",
-			"data":    existingProduct,
+			"message": "Product deleted successfully!",
 		})
 	}
 }
 
-func DeleteProduct() gin.HandlerFunc {
+// GetDeletedProducts lists soft-deleted products for admin users
+func GetDeletedProducts(productService *services.ProductService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		productId := c.Param("productId")
-
-		var product models.Product
-		if err := db.Where("id = ?", productId).First(&product).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				handleError(c, http.StatusNotFound, "Product not found")
-				return
-			}
-			handleError(c, http.StatusInternalServerError, "Failed to find product")
+		products, err := productService.ListDeleted(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
-		if err := db.Delete(&product).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to delete product")
-			return
-		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Deleted products fetched successfully!",
+			"data":    dto.NewProducts(products),
+		})
+	}
+}
 
-		//delete the cart item with the product
-		var cartItem models.CartItem
+// RestoreProduct brings a soft-deleted product back into the catalog
+func RestoreProduct(productService *services.ProductService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
 
-		if err := db.Where("product_id = ?", productId).Delete(&cartItem).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to delete cart item using the deleted product")
+		product, err := productService.Restore(ctx, pathID(c, "productId"))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"message": "Product deleted successfully!",
+			"message": "Product restored successfully!",
+			"data":    dto.NewProduct(product),
 		})
 	}
 }
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// RefundInput represents money given back for an order
+type RefundInput struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	Reason string  `json:"reason" binding:"max=500"`
+}
+
+// AdminRefundOrder records a full or partial refund of an order. The total
+// refunded can never exceed what the order cost.
+func AdminRefundOrder(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var input RefundInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		adminID, _ := c.Get("userid")
+		refund, err := orderService.Refund(ctx, pathID(c, "id"), input.Amount, input.Reason, adminID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "Refund issued successfully",
+			"data":    dto.NewRefund(refund),
+		})
+	}
+}
+
+// AdminGetOrderRefunds lists the refunds of an order
+func AdminGetOrderRefunds(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		refunds, err := orderService.Refunds(ctx, pathID(c, "id"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Refunds retrieved successfully",
+			"data":    dto.NewRefunds(refunds),
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	defaultTopLimit = 10
+	maxTopLimit     = 100
+)
+
+// parseReportFilter reads the from/to query parameters. A date-only "to"
+// includes that whole day.
+func parseReportFilter(c *gin.Context) (repository.ReportRange, string) {
+	var filter repository.ReportRange
+
+	if from := c.Query("from"); from != "" {
+		fromTime, err := parseTimeFilter(from)
+		if err != nil {
+			return filter, "Invalid from date"
+		}
+		filter.From = &fromTime
+	}
+	if to := c.Query("to"); to != "" {
+		toTime, err := parseTimeFilter(to)
+		if err != nil {
+			return filter, "Invalid to date"
+		}
+		if _, err := time.Parse("2006-01-02", to); err == nil {
+			toTime = toTime.AddDate(0, 0, 1)
+		}
+		filter.To = &toTime
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, "from must be before to"
+	}
+
+	return filter, ""
+}
+
+func parseTopLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTopLimit)))
+	if err != nil || limit < 1 {
+		return 0, false
+	}
+	if limit > maxTopLimit {
+		limit = maxTopLimit
+	}
+	return limit, true
+}
+
+func formatMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// respondReport writes the report as JSON, or as a CSV download when the
+// request has format=csv
+func respondReport(c *gin.Context, name string, data interface{}, header []string, rows [][]string) {
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Report generated successfully",
+			"data":    data,
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-%s.csv\"", name, time.Now().Format("20060102")))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(header)
+	writer.WriteAll(rows)
+}
+
+// AdminGetRevenueReport returns revenue and order count per day, week or month
+func AdminGetRevenueReport(reportService *services.ReportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter, msg := parseReportFilter(c)
+		if msg != "" {
+			apperror.Respond(c, apperror.BadRequest(msg))
+			return
+		}
+
+		buckets, err := reportService.Revenue(ctx, filter, c.DefaultQuery("interval", "day"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		rows := make([][]string, len(buckets))
+		for i, bucket := range buckets {
+			rows[i] = []string{bucket.Period.Format("2006-01-02"), strconv.FormatInt(bucket.Orders, 10), formatMoney(bucket.Revenue)}
+		}
+		respondReport(c, "revenue", buckets, []string{"period", "orders", "revenue"}, rows)
+	}
+}
+
+// AdminGetOrdersByStatusReport counts orders per status, including cancelled ones
+func AdminGetOrdersByStatusReport(reportService *services.ReportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter, msg := parseReportFilter(c)
+		if msg != "" {
+			apperror.Respond(c, apperror.BadRequest(msg))
+			return
+		}
+
+		counts, err := reportService.OrdersByStatus(ctx, filter)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		rows := make([][]string, len(counts))
+		for i, count := range counts {
+			rows[i] = []string{count.Status, strconv.FormatInt(count.Orders, 10), formatMoney(count.Revenue)}
+		}
+		respondReport(c, "orders-by-status", counts, []string{"status", "orders", "revenue"}, rows)
+	}
+}
+
+// AdminGetAverageOrderValueReport returns the average value of non-cancelled orders
+func AdminGetAverageOrderValueReport(reportService *services.ReportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter, msg := parseReportFilter(c)
+		if msg != "" {
+			apperror.Respond(c, apperror.BadRequest(msg))
+			return
+		}
+
+		result, err := reportService.AverageOrderValue(ctx, filter)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		respondReport(c, "average-order-value", result,
+			[]string{"orders", "revenue", "average_order_value"},
+			[][]string{{strconv.FormatInt(result.Orders, 10), formatMoney(result.Revenue), formatMoney(result.AverageOrderValue)}})
+	}
+}
+
+// AdminGetTopProductsReport ranks products by revenue or units sold, using
+// the product snapshot stored on order items
+func AdminGetTopProductsReport(reportService *services.ReportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter, msg := parseReportFilter(c)
+		if msg != "" {
+			apperror.Respond(c, apperror.BadRequest(msg))
+			return
+		}
+		limit, ok := parseTopLimit(c)
+		if !ok {
+			apperror.Respond(c, apperror.BadRequest("Invalid limit"))
+			return
+		}
+
+		products, err := reportService.TopProducts(ctx, filter, c.DefaultQuery("by", "revenue"), limit)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		rows := make([][]string, len(products))
+		for i, product := range products {
+			rows[i] = []string{strconv.FormatUint(uint64(product.ProductID), 10), product.ProductName, product.SKU, strconv.FormatInt(product.Units, 10), formatMoney(product.Revenue)}
+		}
+		respondReport(c, "top-products", products, []string{"product_id", "product_name", "sku", "units", "revenue"}, rows)
+	}
+}
+
+// AdminGetTopCategoriesReport ranks categories by revenue or units sold
+func AdminGetTopCategoriesReport(reportService *services.ReportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter, msg := parseReportFilter(c)
+		if msg != "" {
+			apperror.Respond(c, apperror.BadRequest(msg))
+			return
+		}
+		limit, ok := parseTopLimit(c)
+		if !ok {
+			apperror.Respond(c, apperror.BadRequest("Invalid limit"))
+			return
+		}
+
+		categories, err := reportService.TopCategories(ctx, filter, c.DefaultQuery("by", "revenue"), limit)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		rows := make([][]string, len(categories))
+		for i, category := range categories {
+			rows[i] = []string{category.Category, strconv.FormatInt(category.Units, 10), formatMoney(category.Revenue)}
+		}
+		respondReport(c, "top-categories", categories, []string{"category", "units", "revenue"}, rows)
+	}
+}
+
+// AdminGetCustomersReport splits ordering customers per period into new ones,
+// whose first order falls in that period, and returning ones who ordered before
+func AdminGetCustomersReport(reportService *services.ReportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter, msg := parseReportFilter(c)
+		if msg != "" {
+			apperror.Respond(c, apperror.BadRequest(msg))
+			return
+		}
+
+		buckets, err := reportService.Customers(ctx, filter, c.DefaultQuery("interval", "day"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		rows := make([][]string, len(buckets))
+		for i, bucket := range buckets {
+			rows[i] = []string{bucket.Period.Format("2006-01-02"), strconv.FormatInt(bucket.NewCustomers, 10), strconv.FormatInt(bucket.ReturningCustomers, 10)}
+		}
+		respondReport(c, "customers", buckets, []string{"period", "new_customers", "returning_customers"}, rows)
+	}
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// ShipShipmentInput represents the carrier details of a shipment leaving the
+// warehouse. When Items is set, only those quantities are shipped and the
+// rest of the shipment stays pending.
+type ShipShipmentInput struct {
+	Carrier        string              `json:"carrier" binding:"required,max=50"`
+	TrackingNumber string              `json:"tracking_number" binding:"required,max=100"`
+	TrackingURL    string              `json:"tracking_url" binding:"omitempty,url"`
+	Items          []ShipmentItemInput `json:"items" binding:"omitempty,dive"`
+}
+
+// ShipmentItemInput is the quantity of an order line put in a shipment
+type ShipmentItemInput struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// TrackingItem is an order line as shown in the customer tracking section
+type TrackingItem struct {
+	OrderItemID uint   `json:"order_item_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
+
+// ShipmentTracking is what a customer sees of one shipment of their order
+type ShipmentTracking struct {
+	ShipmentID     uint           `json:"shipment_id"`
+	Status         string         `json:"status"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	TrackingURL    string         `json:"tracking_url"`
+	ShippedAt      *time.Time     `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	Items          []TrackingItem `json:"items"`
+}
+
+// buildOrderTracking lists the shipments of an order for its customer,
+// leaving out warehouse details and cancelled shipments
+func buildOrderTracking(order models.Order) []ShipmentTracking {
+	names := map[uint]string{}
+	for _, item := range order.Items {
+		names[item.ID] = item.ProductName
+	}
+
+	tracking := []ShipmentTracking{}
+	for _, shipment := range order.Shipments {
+		if shipment.Status == models.ShipmentStatusCancelled {
+			continue
+		}
+		entry := ShipmentTracking{
+			ShipmentID:     shipment.ID,
+			Status:         shipment.Status,
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			TrackingURL:    shipment.TrackingURL,
+			ShippedAt:      shipment.ShippedAt,
+			DeliveredAt:    shipment.DeliveredAt,
+		}
+		for _, item := range shipment.Items {
+			entry.Items = append(entry.Items, TrackingItem{
+				OrderItemID: item.OrderItemID,
+				ProductName: names[item.OrderItemID],
+				Quantity:    item.Quantity,
+			})
+		}
+		tracking = append(tracking, entry)
+	}
+	return tracking
+}
+
+// AdminGetOrderShipments lists the shipments of an order
+func AdminGetOrderShipments(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		shipments, err := orderService.Shipments(ctx, pathID(c, "id"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Shipments retrieved successfully",
+			"data":    dto.NewShipments(shipments),
+		})
+	}
+}
+
+// AdminShipShipment hands a pending shipment, or part of it, to a carrier and
+// updates the order status
+func AdminShipShipment(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var input ShipShipmentInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		ship := services.ShipInput{
+			Carrier:        input.Carrier,
+			TrackingNumber: input.TrackingNumber,
+			TrackingURL:    input.TrackingURL,
+		}
+		for _, item := range input.Items {
+			ship.Items = append(ship.Items, services.ShipmentQuantity{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+		}
+
+		shipped, err := orderService.ShipShipment(ctx, pathID(c, "id"), pathID(c, "shipmentId"), ship)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Shipment shipped successfully",
+			"data":    dto.NewShipment(shipped),
+		})
+	}
+}
+
+// AdminDeliverShipment marks a shipped shipment as delivered and updates the
+// order status
+func AdminDeliverShipment(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		delivered, err := orderService.DeliverShipment(ctx, pathID(c, "id"), pathID(c, "shipmentId"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Shipment delivered successfully",
+			"data":    dto.NewShipment(delivered),
+		})
+	}
+}
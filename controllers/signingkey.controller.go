@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+	"github.com/sajagsubedi/Ecommerce-Api/signing"
+)
+
+// RotateSigningKeyInput chooses whether the new key signs at once or after
+// the publish lead
+type RotateSigningKeyInput struct {
+	Immediate bool `json:"immediate"`
+}
+
+// GetJWKS publishes the public keys access tokens are signed with so other
+// services can verify them
+func GetJWKS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set, err := signing.Default.JWKS()
+		if err != nil {
+			apperror.Respond(c, apperror.Internal("Failed to publish signing keys"))
+			return
+		}
+
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, set)
+	}
+}
+
+// AdminGetSigningKeys lists the signing keys and their schedules, newest
+// first
+func AdminGetSigningKeys(signingKeyService *services.SigningKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		keys, err := signingKeyService.List(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Signing keys retrieved successfully",
+			"data":    dto.NewSigningKeys(keys),
+		})
+	}
+}
+
+// AdminRotateSigningKey generates a new signing key ahead of schedule
+func AdminRotateSigningKey(signingKeyService *services.SigningKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		var input RotateSigningKeyInput
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&input); err != nil {
+				apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+				return
+			}
+		}
+
+		key, err := signingKeyService.Rotate(ctx, input.Immediate)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "Signing key rotated successfully",
+			"data":    dto.NewSigningKey(key),
+		})
+	}
+}
+
+// AdminRevokeSigningKey stops trusting a key at once. Every token it signed
+// stops working.
+func AdminRevokeSigningKey(signingKeyService *services.SigningKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		if err := signingKeyService.Revoke(ctx, c.Param("kid")); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Signing key revoked successfully",
+		})
+	}
+}
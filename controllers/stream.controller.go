@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/eventbus"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	streamHeartbeat   = 25 * time.Second
+	streamReplayLimit = 500
+)
+
+// eventOwner reads the user an event payload belongs to. A payload that
+// cannot be read belongs to nobody.
+func eventOwner(payload []byte) uint {
+	var owner struct {
+		UserID uint `json:"user_id"`
+	}
+	if err := json.Unmarshal(payload, &owner); err != nil {
+		log.Printf("Failed to read the owner of an event: %v", err)
+		return 0
+	}
+	return owner.UserID
+}
+
+// writeStreamEvent writes one event in the text/event-stream format
+func writeStreamEvent(c *gin.Context, event eventbus.Event) {
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Payload)
+	c.Writer.Flush()
+}
+
+// streamEvents sends the events of types accepted by filter as Server-Sent
+// Events until the client disconnects. Clients reconnecting with
+// Last-Event-ID first get what they missed, replayed from the outbox.
+func streamEvents(c *gin.Context, orderService *services.OrderService, types []string, filter func(eventbus.Event) bool) {
+	accept := func(event eventbus.Event) bool {
+		for _, eventType := range types {
+			if event.Type == eventType {
+				return filter(event)
+			}
+		}
+		return false
+	}
+
+	// Subscribe before replaying so nothing committed in between is lost
+	sub := eventbus.Default.Subscribe(accept)
+	defer sub.Close()
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	var missed []models.OutboxEvent
+	if lastID != "" {
+		since, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			apperror.Respond(c, apperror.BadRequest("Invalid Last-Event-ID"))
+			return
+		}
+
+		missed, err = orderService.MissedEvents(c.Request.Context(), uint(since), types, streamReplayLimit)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	var replayed uint
+	for _, outboxEvent := range missed {
+		event := eventbus.Event{ID: outboxEvent.ID, Type: outboxEvent.Type, Payload: json.RawMessage(outboxEvent.Payload)}
+		if accept(event) {
+			writeStreamEvent(c, event)
+		}
+		replayed = outboxEvent.ID
+	}
+
+	// Tell the client how long to wait before reconnecting
+	fmt.Fprintf(c.Writer, "retry: 3000\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and replays
+				return
+			}
+			if event.ID <= replayed {
+				continue
+			}
+			writeStreamEvent(c, event)
+		case <-heartbeat.C:
+			fmt.Fprintf(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// StreamUserOrders pushes status changes of the authenticated user's orders
+// as Server-Sent Events
+func StreamUserOrders(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userid")
+		if !exists {
+			apperror.Respond(c, apperror.Unauthorized("User not authenticated"))
+			return
+		}
+
+		streamEvents(c, orderService, []string{helpers.EventOrderStatusChanged}, func(event eventbus.Event) bool {
+			return eventOwner(event.Payload) == userID.(uint)
+		})
+	}
+}
+
+// AdminStreamOrders pushes every new order as Server-Sent Events
+func AdminStreamOrders(orderService *services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		streamEvents(c, orderService, []string{helpers.EventOrderCreated}, func(event eventbus.Event) bool {
+			return true
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// TwoFactorVerifyInput completes a sign-in with either an authenticator code
+// or a recovery code
+type TwoFactorVerifyInput struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// TwoFactorCodeInput carries a code from the user's authenticator
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableInput asks for the password and a current code before
+// two-factor authentication is turned off
+type TwoFactorDisableInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// VerifyTwoFactor exchanges the challenge token from Signin and a second
+// factor for a session token. Wrong codes count towards the account lockout.
+func VerifyTwoFactor(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var input TwoFactorVerifyInput
+		if err := c.ShouldBindJSON(&input); err != nil || (input.Code == "") == (input.RecoveryCode == "") {
+			apperror.Respond(c, apperror.BadRequest("Provide the challenge token and either a code or a recovery code"))
+			return
+		}
+
+		session, err := userService.VerifyTwoFactor(ctx, input.ChallengeToken, input.Code, input.RecoveryCode)
+		if err != nil {
+			respondSignInError(c, err)
+			return
+		}
+
+		c.SetCookie("Authorization", session.Token, 60*60*24*7, "/", "", false, true)
+		c.JSON(http.StatusOK, gin.H{
+			"success":                  true,
+			"message":                  "Logged in successfully!",
+			"token":                    session.Token,
+			"recovery_codes_remaining": session.RecoveryCodesRemaining,
+		})
+	}
+}
+
+// EnrollTwoFactor starts two-factor enrollment by generating a secret. It
+// takes effect once ConfirmTwoFactor sees a code from it.
+func EnrollTwoFactor(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userID, _ := c.Get("userid")
+
+		enrollment, err := userService.StartTwoFactorEnrollment(ctx, userID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Scan the code with an authenticator app and confirm it",
+			"data": gin.H{
+				"secret":      enrollment.Secret,
+				"otpauth_uri": enrollment.OTPAuthURI,
+			},
+		})
+	}
+}
+
+// ConfirmTwoFactor turns two-factor authentication on once the user proves
+// their authenticator works, and returns the recovery codes. They are only
+// shown here.
+func ConfirmTwoFactor(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var input TwoFactorCodeInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		userID, _ := c.Get("userid")
+
+		codes, err := userService.ConfirmTwoFactor(ctx, userID.(uint), input.Code)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Two-factor authentication enabled. Store the recovery codes somewhere safe",
+			"data":    gin.H{"recovery_codes": codes},
+		})
+	}
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes
+func RegenerateRecoveryCodes(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var input TwoFactorCodeInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		userID, _ := c.Get("userid")
+
+		codes, err := userService.RegenerateRecoveryCodes(ctx, userID.(uint), input.Code)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Recovery codes regenerated",
+			"data":    gin.H{"recovery_codes": codes},
+		})
+	}
+}
+
+// DisableTwoFactor turns two-factor authentication off. Admins lose access
+// to the admin API until they enroll again when it is enforced.
+func DisableTwoFactor(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var input TwoFactorDisableInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		userID, _ := c.Get("userid")
+
+		if err := userService.DisableTwoFactor(ctx, userID.(uint), input.Password, input.Code); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Two-factor authentication disabled",
+		})
+	}
+}
+
+// AdminResetTwoFactor turns two-factor authentication off for a user who
+// lost their authenticator and recovery codes
+func AdminResetTwoFactor(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := userService.ResetTwoFactor(ctx, pathID(c, "userId")); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Two-factor authentication reset successfully",
+		})
+	}
+}
@@ -2,131 +2,99 @@ package controllers
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/sajagsubedi/Ecommerce-Api/database"
-	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-func GetProfile() gin.HandlerFunc {
+func GetProfile(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, _ := c.Get("userid")
-		db := database.DB.WithContext(ctx)
 
-		fmt.Print(userID)
-		var user models.User
-		if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
-			handleError(c, http.StatusNotFound, "User not found")
+		user, err := userService.Get(ctx, userID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "Your profile fetched successfully",
-			"data": gin.H{
-				"id":    user.ID,
-				"name":  user.Name,
-				"email": user.Email,
-				"role":  user.Role,
-			},
+			"data":    dto.NewUser(user),
 		})
 	}
 }
 
-func UpdateProfile() gin.HandlerFunc {
+// UpdateProfileInput is the body of a profile update
+type UpdateProfileInput struct {
+	Name string `gorm:"not null" json:"name" validate:"required,min=2,max=100"`
+}
+
+// AdminUserInput is the body of an admin update of a user. Fields left
+// empty are not changed.
+type AdminUserInput struct {
+	Name     string `json:"name" validate:"omitempty,min=2,max=100"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	Password string `json:"password" validate:"omitempty,min=6"`
+	Role     string `json:"role" validate:"omitempty,oneof=user admin"`
+}
+
+// ChangePasswordInput is the body of a password change. OldPassword is
+// left out by users who have not set a password yet.
+type ChangePasswordInput struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+func UpdateProfile(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, _ := c.Get("userid")
-		db := database.DB.WithContext(ctx)
-
-		type UpdateProfile struct {
-			Name string `gorm:"not null" json:"name" validate:"required,min=2,max=100"`
-		}
-
-		var input UpdateProfile
 
+		var input UpdateProfileInput
 		if err := c.ShouldBindJSON(&input); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
-			return
-		}
-
-		var existingUser models.User
-		if err := db.Where("id = ?", userID).First(&existingUser).Error; err != nil {
-			handleError(c, http.StatusNotFound, "User not found")
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
-		if input.Name != "" {
-			existingUser.Name = input.Name
-		}
-
-		if err := db.Save(&existingUser).Error; err != nil {
-			log.Printf("Failed to update user: %v", err)
-			handleError(c, http.StatusInternalServerError, "Failed to update profile")
+		user, err := userService.UpdateProfile(ctx, userID.(uint), input.Name)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "Profile updated successfully",
-			"user": gin.H{
-				"id":    existingUser.ID,
-				"name":  existingUser.Name,
-				"email": existingUser.Email,
-			}})
+			"user":    dto.NewUser(user),
+		})
 	}
 }
 
-func ChangePassword() gin.HandlerFunc {
+func ChangePassword(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userID, _ := c.Get("userid")
-		db := database.DB.WithContext(ctx)
 
-		type ChangePasswordRequest struct {
-			OldPassword string `json:"old_password" validate:"required"`
-			NewPassword string `json:"new_password" validate:"required,min=6"`
-		}
-		var request ChangePasswordRequest
-		if err := c.BindJSON(&request); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
-			return
-		}
-
-		var existingUser models.User
-		if err := db.Where("id = ?", userID).First(&existingUser).Error; err != nil {
-			handleError(c, http.StatusNotFound, "User not found")
+		var request ChangePasswordInput
+		if err := c.ShouldBindJSON(&request); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
-		if !existingUser.ComparePassword(request.OldPassword) {
-			handleError(c, http.StatusUnauthorized, "Invalid credentials!")
-			return
-		}
-
-		existingUser.Password = request.NewPassword
-		hashedPassword, err := existingUser.HashPassword()
-		if err != nil {
-			log.Printf("Failed to hash password: %v", err)
-			handleError(c, http.StatusInternalServerError, "Failed to process password")
-			return
-		}
-		existingUser.Password = hashedPassword
-		if err := db.Save(&existingUser).Error; err != nil {
-			log.Printf("Failed to update user: %v", err)
-			handleError(c, http.StatusInternalServerError, "Failed to update password")
+		if err := userService.ChangePassword(ctx, userID.(uint), request.OldPassword, request.NewPassword); err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
@@ -137,160 +105,131 @@ func ChangePassword() gin.HandlerFunc {
 	}
 }
 
-func GetUsersByAdmin() gin.HandlerFunc {
+func GetUsersByAdmin(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-
-		var users []models.User
-		if err := db.Find(&users).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to fetch users")
+		users, err := userService.List(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "Users fetched successfully!",
-			"data":    users,
+			"data":    dto.NewUsers(users),
 		})
 	}
 }
-func GetUserById() gin.HandlerFunc {
+func GetUserById(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		userId := c.Param("userId")
-
-		var user models.User
-		err := db.Where("id = ?", userId).First(&user).Error
+		user, err := userService.Get(ctx, pathID(c, "userId"))
 		if err != nil {
-			handleError(c, http.StatusNotFound, "User not found")
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": "User fetched successfully!",
-			"data":    user,
+			"data":    dto.NewUser(user),
 		})
 	}
 }
 
-func UpdateUserByAdmin() gin.HandlerFunc {
+func UpdateUserByAdmin(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		userId := c.Param("userId")
-
-		var user models.User
-		if err := c.BindJSON(&user); err != nil {
-			handleError(c, http.StatusBadRequest, "Invalid request payload")
+		var input AdminUserInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
 			return
 		}
 
-		if err := validate.Struct(user); err != nil {
-			handleError(c, http.StatusBadRequest, err.(validator.ValidationErrors).Error())
+		if err := validate.Struct(input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Validation failed"))
 			return
 		}
 
-		var existingUser models.User
-		if err := db.Where("id = ?", userId).First(&existingUser).Error; err != nil {
-			handleError(c, http.StatusNotFound, "User not found")
+		user, err := userService.Update(ctx, pathID(c, "userId"), services.UserChanges{
+			Name:     input.Name,
+			Email:    input.Email,
+			Password: input.Password,
+			Role:     input.Role,
+		})
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
-		if user.Email != "" && user.Email != existingUser.Email {
-			var emailExists []models.User
-			if err := db.Where("email = ?", user.Email).Find(&emailExists).Error; err != nil {
-				handleError(c, http.StatusInternalServerError, "Internal Server Error")
-				return
-			}
-			if len(emailExists) > 0 {
-				handleError(c, http.StatusConflict, "Email already exists!")
-				return
-			}
-			existingUser.Email = user.Email
-		}
-
-		if user.Name != "" {
-			existingUser.Name = user.Name
-		}
-
-		if user.Password != "" {
-			hashedPassword, err := existingUser.HashPassword()
-			if err != nil {
-				log.Printf("Failed to hash password: %v", err)
-				handleError(c, http.StatusInternalServerError, "Failed to process password")
-				return
-			}
-			existingUser.Password = hashedPassword
-		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "User updated successfully",
+			"user":    dto.NewUser(user),
+		})
+	}
+}
 
-		if user.Role != "" {
-			existingUser.Role = user.Role
-		}
+func DeleteUserByAdmin(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
 
-		if err := db.Save(&existingUser).Error; err != nil {
-			log.Printf("Failed to update user: %v", err)
-			handleError(c, http.StatusInternalServerError, "Failed to update user")
+		if err := userService.Delete(ctx, pathID(c, "userId")); err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"message": "User updated successfully",
-			"user": gin.H{
-				"id":    existingUser.ID,
-				"name":  existingUser.Name,
-				"email": existingUser.Email,
-				"role":  existingUser.Role,
-			},
+			"message": "User deleted successfully",
 		})
 	}
 }
 
-func DeleteUserByAdmin() gin.HandlerFunc {
+// GetDeletedUsersByAdmin lists soft-deleted users
+func GetDeletedUsersByAdmin(userService *services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		db := database.DB.WithContext(ctx)
-		userId := c.Param("userId")
-
-		var user models.User
-		if err := db.Where("id = ?", userId).First(&user).Error; err != nil {
-			handleError(c, http.StatusNotFound, "User not found")
+		users, err := userService.ListDeleted(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
-		if err := db.Delete(&user).Error; err != nil {
-			handleError(c, http.StatusInternalServerError, "Failed to delete user")
-			return
-		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Deleted users fetched successfully!",
+			"data":    dto.NewUsers(users),
+		})
+	}
+}
 
-		//delete the cart item with the product
-		var cart models.Cart
-		if err := db.Where("user_id = ?", userId).Delete(&cart).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Cart not found")
-			return
-		}
+// RestoreUserByAdmin reactivates a soft-deleted user
+func RestoreUserByAdmin(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
 
-		//delete the cart items
-		var cartItems []models.CartItem
-		if err := db.Where("cart_id = ?", cart.ID).Delete(&cartItems).Error; err != nil {
-			handleError(c, http.StatusNotFound, "Cart items not found")
+		user, err := userService.Restore(ctx, pathID(c, "userId"))
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"message": "User deleted successfully",
+			"message": "User restored successfully",
+			"user":    dto.NewUser(user),
 		})
 	}
 }
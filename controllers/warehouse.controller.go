@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// WarehouseUpdateInput represents the editable fields of a warehouse. Fields
+// left out of the request are not changed.
+type WarehouseUpdateInput struct {
+	Name      *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Street    *string `json:"street"`
+	City      *string `json:"city"`
+	State     *string `json:"state"`
+	ZipCode   *string `json:"zip_code"`
+	Country   *string `json:"country"`
+	Priority  *int    `json:"priority"`
+	IsDefault *bool   `json:"is_default"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+// StockTransferInput represents stock moved between two warehouses
+type StockTransferInput struct {
+	ProductID       uint   `json:"product_id" binding:"required"`
+	FromWarehouseID uint   `json:"from_warehouse_id" binding:"required"`
+	ToWarehouseID   uint   `json:"to_warehouse_id" binding:"required,nefield=FromWarehouseID"`
+	Quantity        int    `json:"quantity" binding:"required,gt=0"`
+	Note            string `json:"note" binding:"max=500"`
+}
+
+// AdminGetWarehouses lists every warehouse
+func AdminGetWarehouses(warehouseService *services.WarehouseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		warehouses, err := warehouseService.List(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Warehouses fetched successfully",
+			"data":    dto.NewWarehouses(warehouses),
+		})
+	}
+}
+
+// AdminCreateWarehouse adds a warehouse. The first warehouse always becomes
+// the default one.
+func AdminCreateWarehouse(warehouseService *services.WarehouseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var warehouse models.Warehouse
+		if err := c.ShouldBindJSON(&warehouse); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid request payload"))
+			return
+		}
+		if err := validate.Struct(warehouse); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		warehouse, err := warehouseService.Create(ctx, warehouse)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "Warehouse created successfully",
+			"data":    dto.NewWarehouse(warehouse),
+		})
+	}
+}
+
+// AdminUpdateWarehouse edits a warehouse. The default warehouse cannot be
+// deactivated or unset directly; make another warehouse the default instead.
+func AdminUpdateWarehouse(warehouseService *services.WarehouseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var input WarehouseUpdateInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		warehouse, err := warehouseService.Update(ctx, pathID(c, "warehouseId"), services.WarehouseUpdate{
+			Name:      input.Name,
+			Street:    input.Street,
+			City:      input.City,
+			State:     input.State,
+			ZipCode:   input.ZipCode,
+			Country:   input.Country,
+			Priority:  input.Priority,
+			IsDefault: input.IsDefault,
+			IsActive:  input.IsActive,
+		})
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Warehouse updated successfully",
+			"data":    dto.NewWarehouse(warehouse),
+		})
+	}
+}
+
+// AdminGetWarehouseStock lists the stock levels held in a warehouse
+func AdminGetWarehouseStock(warehouseService *services.WarehouseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		stocks, err := warehouseService.Stock(ctx, pathID(c, "warehouseId"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Warehouse stock fetched successfully",
+			"data":    dto.NewWarehouseStocks(stocks),
+		})
+	}
+}
+
+// AdminTransferStock moves stock of a product from one warehouse to another
+func AdminTransferStock(warehouseService *services.WarehouseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var input StockTransferInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		adminID, _ := c.Get("userid")
+		movements, err := warehouseService.Transfer(ctx, services.StockTransfer{
+			ProductID:       input.ProductID,
+			FromWarehouseID: input.FromWarehouseID,
+			ToWarehouseID:   input.ToWarehouseID,
+			Quantity:        input.Quantity,
+			Note:            input.Note,
+		}, adminID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "Stock transferred successfully",
+			"data":    dto.NewStockMovements(movements),
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// WebhookInput represents a webhook endpoint registration. On update, fields
+// left out of the request are not changed.
+type WebhookInput struct {
+	URL         *string  `json:"url" binding:"omitempty,url"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Events      []string `json:"events"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// changes converts the input to what the webhook service applies
+func (input WebhookInput) changes() services.WebhookChanges {
+	return services.WebhookChanges{
+		URL:         input.URL,
+		Description: input.Description,
+		Events:      input.Events,
+		IsActive:    input.IsActive,
+	}
+}
+
+// AdminGetWebhookEventTypes lists the events endpoints can subscribe to
+func AdminGetWebhookEventTypes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Event types retrieved successfully",
+			"data":    helpers.EventTypes,
+		})
+	}
+}
+
+// AdminGetWebhooks lists every registered webhook endpoint
+func AdminGetWebhooks(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		endpoints, err := webhookService.List(ctx)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Webhooks retrieved successfully",
+			"data":    dto.NewWebhookEndpoints(endpoints),
+		})
+	}
+}
+
+// AdminCreateWebhook registers an endpoint. The signing secret is returned
+// in this response only.
+func AdminCreateWebhook(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var input WebhookInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		adminID, _ := c.Get("userid")
+		endpoint, secret, err := webhookService.Create(ctx, input.changes(), adminID.(uint))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "Webhook created successfully",
+			"data":    dto.NewWebhookEndpoint(endpoint),
+			"secret":  secret,
+		})
+	}
+}
+
+// AdminUpdateWebhook changes the URL, description, events or state of an
+// endpoint
+func AdminUpdateWebhook(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var input WebhookInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apperror.Respond(c, apperror.Validation(err, "Invalid input data"))
+			return
+		}
+
+		endpoint, err := webhookService.Update(ctx, pathID(c, "webhookId"), input.changes())
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Webhook updated successfully",
+			"data":    dto.NewWebhookEndpoint(endpoint),
+		})
+	}
+}
+
+// AdminRotateWebhookSecret replaces the signing secret of an endpoint and
+// returns the new one
+func AdminRotateWebhookSecret(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		secret, err := webhookService.RotateSecret(ctx, pathID(c, "webhookId"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Webhook secret rotated successfully",
+			"secret":  secret,
+		})
+	}
+}
+
+// AdminDeleteWebhook removes an endpoint together with its delivery log
+func AdminDeleteWebhook(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := webhookService.Delete(ctx, pathID(c, "webhookId")); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Webhook deleted successfully",
+		})
+	}
+}
+
+// AdminGetWebhookDeliveries lists the delivery log, newest first, filtered
+// by webhook_id, status and event_type
+func AdminGetWebhookDeliveries(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		webhookID, ok := queryID(c, "webhook_id")
+		if !ok {
+			apperror.Respond(c, apperror.BadRequest("Invalid webhook_id"))
+			return
+		}
+		filter := repository.DeliveryFilter{
+			EndpointID: webhookID,
+			Status:     c.Query("status"),
+			EventType:  c.Query("event_type"),
+		}
+
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid page"))
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDeliveryLimit)))
+		if err != nil || limit < 1 {
+			apperror.Respond(c, apperror.BadRequest("Invalid limit"))
+			return
+		}
+		if limit > maxDeliveryLimit {
+			limit = maxDeliveryLimit
+		}
+
+		deliveries, total, err := webhookService.Deliveries(ctx, filter, page, limit)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Webhook deliveries retrieved successfully",
+			"data":    dto.NewWebhookDeliveries(deliveries),
+			"pagination": gin.H{
+				"page":  page,
+				"limit": limit,
+				"total": total,
+			},
+		})
+	}
+}
+
+// AdminRedeliverWebhook queues a delivery again as a new entry of the log,
+// keeping the original attempt for reference
+func AdminRedeliverWebhook(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		delivery, err := webhookService.Redeliver(ctx, pathID(c, "deliveryId"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"success": true,
+			"message": "Webhook delivery queued",
+			"data":    dto.NewWebhookDelivery(delivery),
+		})
+	}
+}
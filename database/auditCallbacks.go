@@ -0,0 +1,188 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+const auditBeforeKey = "audit:before"
+
+// Fields that are never written to the audit log
+var auditRedactedFields = []string{"password"}
+
+// registerAuditCallbacks records every create, update and delete of an
+// auditable model made with a context carrying helpers.AuditMeta. The log
+// entry is written inside the same transaction as the change itself.
+func registerAuditCallbacks(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().After("gorm:create").Register("audit:after_create", writeAuditLog(models.AuditActionCreate)); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("audit:before_update", captureAuditBefore); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("audit:after_update", writeAuditLog(models.AuditActionUpdate)); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("audit:before_delete", captureAuditBefore); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("audit:after_delete", writeAuditLog(models.AuditActionDelete))
+}
+
+// auditTarget returns the entity name and primary key of the record being
+// changed, or ok=false when the statement should not be audited
+func auditTarget(db *gorm.DB) (entity string, id uint, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return "", 0, false
+	}
+	if _, ok := helpers.AuditMetaFromContext(db.Statement.Context); !ok {
+		return "", 0, false
+	}
+
+	model, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(models.Auditable)
+	if !ok {
+		return "", 0, false
+	}
+
+	if db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return "", 0, false
+	}
+	value, zero := db.Statement.Schema.PrioritizedPrimaryField.ValueOf(db.Statement.Context, db.Statement.ReflectValue)
+	if zero {
+		return "", 0, false
+	}
+	pk, ok := value.(uint)
+	if !ok {
+		return "", 0, false
+	}
+
+	return model.AuditEntity(), pk, true
+}
+
+// loadAuditSnapshot reads the current database state of a record as JSON
+func loadAuditSnapshot(db *gorm.DB, id uint) (map[string]interface{}, error) {
+	record := reflect.New(db.Statement.Schema.ModelType).Interface()
+	err := db.Session(&gorm.Session{NewDB: true}).Unscoped().Where("id = ?", id).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return auditSnapshot(record)
+}
+
+func auditSnapshot(record interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+	for _, field := range auditRedactedFields {
+		delete(snapshot, field)
+	}
+	return snapshot, nil
+}
+
+func captureAuditBefore(db *gorm.DB) {
+	_, id, ok := auditTarget(db)
+	if !ok {
+		return
+	}
+
+	// A missing row has no before state; the change itself will affect
+	// nothing or insert it
+	before, err := loadAuditSnapshot(db, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.Statement.Settings.Store(auditBeforeKey, before)
+}
+
+func writeAuditLog(action string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		entity, id, ok := auditTarget(db)
+		if !ok {
+			return
+		}
+		meta, _ := helpers.AuditMetaFromContext(db.Statement.Context)
+
+		var before, after map[string]interface{}
+		if value, ok := db.Statement.Settings.LoadAndDelete(auditBeforeKey); ok {
+			before = value.(map[string]interface{})
+		}
+
+		var err error
+		switch action {
+		case models.AuditActionCreate:
+			after, err = auditSnapshot(db.Statement.ReflectValue.Interface())
+		case models.AuditActionUpdate:
+			after, err = loadAuditSnapshot(db, id)
+		}
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+
+		entry := models.AuditLog{
+			ActorID:    meta.ActorID,
+			Action:     action,
+			EntityType: entity,
+			EntityID:   id,
+			IP:         meta.IP,
+			RequestID:  meta.RequestID,
+		}
+		if entry.Before, err = marshalAuditJSON(before); err != nil {
+			db.AddError(err)
+			return
+		}
+		if entry.After, err = marshalAuditJSON(after); err != nil {
+			db.AddError(err)
+			return
+		}
+		if entry.Changes, err = marshalAuditJSON(diffAuditSnapshots(before, after)); err != nil {
+			db.AddError(err)
+			return
+		}
+
+		db.AddError(db.Session(&gorm.Session{NewDB: true}).Create(&entry).Error)
+	}
+}
+
+// diffAuditSnapshots returns the fields whose value differs between before
+// and after, as {"field": {"from": old, "to": new}}
+func diffAuditSnapshots(before, after map[string]interface{}) map[string]interface{} {
+	changes := map[string]interface{}{}
+	for key, oldValue := range before {
+		newValue, ok := after[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = map[string]interface{}{"from": oldValue, "to": newValue}
+		}
+	}
+	for key, newValue := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = map[string]interface{}{"from": nil, "to": newValue}
+		}
+	}
+	return changes
+}
+
+func marshalAuditJSON(value map[string]interface{}) (models.JSON, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	return models.JSON(raw), err
+}
@@ -5,14 +5,15 @@ import (
 	"log"
 	"os"
 
-	"github.com/sajagsubedi/Ecommerce-Api/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func ConnectDB() error {
+// DSN builds the Postgres connection string from the DB_* environment
+// variables
+func DSN() (string, error) {
 	// Get environment variables
 	host := os.Getenv("DB_HOST")
 	port := os.Getenv("DB_PORT")
@@ -27,34 +28,29 @@ func ConnectDB() error {
 	required := []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASS", "DB_NAME"}
 	for _, env := range required {
 		if os.Getenv(env) == "" {
-			return fmt.Errorf("missing required environment variable: %s", env)
+			return "", fmt.Errorf("missing required environment variable: %s", env)
 		}
 	}
 
 	// Create the DSN (Data Source Name)
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		host, port, user, password, dbname, sslmode)
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		host, port, user, password, dbname, sslmode), nil
+}
+
+func ConnectDB() error {
+	dsn, err := DSN()
+	if err != nil {
+		return err
+	}
 
 	// Connect to the database
-	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Auto-migrate all models
-	err = DB.AutoMigrate(
-		&models.User{},
-		&models.Product{},
-		&models.Cart{},
-		&models.CartItem{},
-		&models.Order{},
-		&models.OrderItem{},
-		&models.ShippingAddress{},
-	)
-
-	if err != nil {
-		return fmt.Errorf("error migrating models: %w", err)
+	if err := registerAuditCallbacks(DB); err != nil {
+		return fmt.Errorf("failed to register audit callbacks: %w", err)
 	}
 
 	log.Println("Database connection established successfully")
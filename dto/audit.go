@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+type AuditLog struct {
+	ID         uint        `json:"id"`
+	ActorID    uint        `json:"actor_id"`
+	Action     string      `json:"action"`
+	EntityType string      `json:"entity_type"`
+	EntityID   uint        `json:"entity_id"`
+	Before     models.JSON `json:"before"`
+	After      models.JSON `json:"after"`
+	Changes    models.JSON `json:"changes"`
+	IP         string      `json:"ip"`
+	RequestID  string      `json:"request_id"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func NewAuditLog(log models.AuditLog) AuditLog {
+	return AuditLog{
+		ID:         log.ID,
+		ActorID:    log.ActorID,
+		Action:     log.Action,
+		EntityType: log.EntityType,
+		EntityID:   log.EntityID,
+		Before:     log.Before,
+		After:      log.After,
+		Changes:    log.Changes,
+		IP:         log.IP,
+		RequestID:  log.RequestID,
+		CreatedAt:  log.CreatedAt,
+	}
+}
+
+func NewAuditLogs(logs []models.AuditLog) []AuditLog {
+	return mapSlice(logs, NewAuditLog)
+}
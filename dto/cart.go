@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+type Cart struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	Items     []CartItem `json:"items"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func NewCart(cart models.Cart) Cart {
+	return Cart{
+		ID:        cart.ID,
+		UserID:    cart.UserID,
+		Items:     mapSlice(cart.Items, NewCartItem),
+		CreatedAt: cart.CreatedAt,
+		UpdatedAt: cart.UpdatedAt,
+	}
+}
+
+// CartItem is a product in a cart. Product is only filled in when the
+// product was loaded with the item.
+type CartItem struct {
+	ID        uint      `json:"id"`
+	CartID    uint      `json:"cart_id"`
+	ProductID uint      `json:"product_id"`
+	Product   *Product  `json:"product,omitempty"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewCartItem(item models.CartItem) CartItem {
+	cartItem := CartItem{
+		ID:        item.ID,
+		CartID:    item.CartID,
+		ProductID: item.ProductID,
+		Quantity:  item.Quantity,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+	if item.Product.ID != 0 {
+		product := NewProduct(item.Product)
+		cartItem.Product = &product
+	}
+	return cartItem
+}
@@ -0,0 +1,29 @@
+// Package dto holds the shapes the API responds with. Handlers map models
+// to these types instead of serializing GORM models, so secrets and
+// internal columns never leave the server and the JSON can change
+// independently of the schema.
+package dto
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// mapSlice maps every model in a list. The result is never nil so empty
+// lists are sent as [] rather than null.
+func mapSlice[M any, D any](items []M, mapper func(M) D) []D {
+	mapped := make([]D, 0, len(items))
+	for _, item := range items {
+		mapped = append(mapped, mapper(item))
+	}
+	return mapped
+}
+
+// deletedAt is nil unless the record was soft deleted
+func deletedAt(deleted gorm.DeletedAt) *time.Time {
+	if !deleted.Valid {
+		return nil
+	}
+	return &deleted.Time
+}
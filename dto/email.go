@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+// EmailMessage is a queued or sent email without its rendered body
+type EmailMessage struct {
+	ID            uint       `json:"id"`
+	UserID        *uint      `json:"user_id"`
+	EventID       *uint      `json:"event_id"`
+	Template      string     `json:"template"`
+	To            string     `json:"to"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func NewEmailMessage(message models.EmailMessage) EmailMessage {
+	return EmailMessage{
+		ID:            message.ID,
+		UserID:        message.UserID,
+		EventID:       message.EventID,
+		Template:      message.Template,
+		To:            message.To,
+		Subject:       message.Subject,
+		Status:        message.Status,
+		Attempts:      message.Attempts,
+		NextAttemptAt: message.NextAttemptAt,
+		LastError:     message.LastError,
+		SentAt:        message.SentAt,
+		CreatedAt:     message.CreatedAt,
+		UpdatedAt:     message.UpdatedAt,
+	}
+}
+
+func NewEmailMessages(messages []models.EmailMessage) []EmailMessage {
+	return mapSlice(messages, NewEmailMessage)
+}
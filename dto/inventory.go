@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+// StockMovement is an entry of the inventory ledger
+type StockMovement struct {
+	ID           uint      `json:"id"`
+	ProductID    uint      `json:"product_id"`
+	WarehouseID  *uint     `json:"warehouse_id"`
+	Delta        int       `json:"delta"`
+	BalanceAfter int       `json:"balance_after"`
+	Reason       string    `json:"reason"`
+	Reference    string    `json:"reference"`
+	Note         string    `json:"note"`
+	ActorID      *uint     `json:"actor_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func NewStockMovement(movement models.StockMovement) StockMovement {
+	return StockMovement{
+		ID:           movement.ID,
+		ProductID:    movement.ProductID,
+		WarehouseID:  movement.WarehouseID,
+		Delta:        movement.Delta,
+		BalanceAfter: movement.BalanceAfter,
+		Reason:       movement.Reason,
+		Reference:    movement.Reference,
+		Note:         movement.Note,
+		ActorID:      movement.ActorID,
+		CreatedAt:    movement.CreatedAt,
+	}
+}
+
+func NewStockMovements(movements []models.StockMovement) []StockMovement {
+	return mapSlice(movements, NewStockMovement)
+}
+
+type Warehouse struct {
+	ID        uint      `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Street    string    `json:"street"`
+	City      string    `json:"city"`
+	State     string    `json:"state"`
+	ZipCode   string    `json:"zip_code"`
+	Country   string    `json:"country"`
+	Priority  int       `json:"priority"`
+	IsDefault bool      `json:"is_default"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewWarehouse(warehouse models.Warehouse) Warehouse {
+	return Warehouse{
+		ID:        warehouse.ID,
+		Code:      warehouse.Code,
+		Name:      warehouse.Name,
+		Street:    warehouse.Street,
+		City:      warehouse.City,
+		State:     warehouse.State,
+		ZipCode:   warehouse.ZipCode,
+		Country:   warehouse.Country,
+		Priority:  warehouse.Priority,
+		IsDefault: warehouse.IsDefault,
+		IsActive:  warehouse.IsActive,
+		CreatedAt: warehouse.CreatedAt,
+		UpdatedAt: warehouse.UpdatedAt,
+	}
+}
+
+func NewWarehouses(warehouses []models.Warehouse) []Warehouse {
+	return mapSlice(warehouses, NewWarehouse)
+}
+
+// WarehouseStock is the quantity of a product held in a warehouse
+type WarehouseStock struct {
+	ID          uint      `json:"id"`
+	WarehouseID uint      `json:"warehouse_id"`
+	ProductID   uint      `json:"product_id"`
+	Quantity    int       `json:"quantity"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewWarehouseStock(stock models.WarehouseStock) WarehouseStock {
+	return WarehouseStock{
+		ID:          stock.ID,
+		WarehouseID: stock.WarehouseID,
+		ProductID:   stock.ProductID,
+		Quantity:    stock.Quantity,
+		UpdatedAt:   stock.UpdatedAt,
+	}
+}
+
+func NewWarehouseStocks(stocks []models.WarehouseStock) []WarehouseStock {
+	return mapSlice(stocks, NewWarehouseStock)
+}
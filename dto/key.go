@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+// SigningKey is a token signing key without its private half
+type SigningKey struct {
+	ID          uint      `json:"id"`
+	Kid         string    `json:"kid"`
+	Algorithm   string    `json:"algorithm"`
+	PublicKey   string    `json:"public_key"`
+	ActivatesAt time.Time `json:"activates_at"`
+	RetiresAt   time.Time `json:"retires_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewSigningKey(key models.SigningKey) SigningKey {
+	return SigningKey{
+		ID:          key.ID,
+		Kid:         key.Kid,
+		Algorithm:   key.Algorithm,
+		PublicKey:   key.PublicKey,
+		ActivatesAt: key.ActivatesAt,
+		RetiresAt:   key.RetiresAt,
+		ExpiresAt:   key.ExpiresAt,
+		CreatedAt:   key.CreatedAt,
+	}
+}
+
+func NewSigningKeys(keys []models.SigningKey) []SigningKey {
+	return mapSlice(keys, NewSigningKey)
+}
+
+// APIKey is an issued API key without its hash
+type APIKey struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uint       `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func NewAPIKey(key models.APIKey) APIKey {
+	return APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedBy:  key.CreatedBy,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+		UpdatedAt:  key.UpdatedAt,
+	}
+}
+
+func NewAPIKeys(keys []models.APIKey) []APIKey {
+	return mapSlice(keys, NewAPIKey)
+}
@@ -0,0 +1,183 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+type Order struct {
+	ID              uint            `json:"id"`
+	UserID          uint            `json:"user_id"`
+	Items           []OrderItem     `json:"items"`
+	TotalAmount     float64         `json:"total_amount"`
+	RefundedAmount  float64         `json:"refunded_amount"`
+	Status          string          `json:"status"`
+	ShippingAddress ShippingAddress `json:"shipping_address"`
+	Shipments       []Shipment      `json:"shipments,omitempty"`
+	ContactNumber   string          `json:"contact_number"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+func NewOrder(order models.Order) Order {
+	return Order{
+		ID:              order.ID,
+		UserID:          order.UserID,
+		Items:           NewOrderItems(order.Items),
+		TotalAmount:     order.TotalAmount,
+		RefundedAmount:  order.RefundedAmount,
+		Status:          order.Status,
+		ShippingAddress: NewShippingAddress(order.ShippingAddress),
+		Shipments:       NewShipments(order.Shipments),
+		ContactNumber:   order.ContactNumber,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+	}
+}
+
+func NewOrders(orders []models.Order) []Order {
+	return mapSlice(orders, NewOrder)
+}
+
+// OrderItem is an order line with the product as it was at checkout
+type OrderItem struct {
+	ID          uint              `json:"id"`
+	OrderID     uint              `json:"order_id"`
+	ProductID   uint              `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	SKU         string            `json:"sku"`
+	Category    string            `json:"category"`
+	ImageURL    string            `json:"image_url"`
+	Attributes  map[string]string `json:"attributes"`
+	UnitPrice   float64           `json:"unit_price"`
+	Quantity    int               `json:"quantity"`
+	Price       float64           `json:"price"`
+}
+
+func NewOrderItem(item models.OrderItem) OrderItem {
+	return OrderItem{
+		ID:          item.ID,
+		OrderID:     item.OrderID,
+		ProductID:   item.ProductID,
+		ProductName: item.ProductName,
+		SKU:         item.SKU,
+		Category:    item.Category,
+		ImageURL:    item.ImageURL,
+		Attributes:  item.Attributes,
+		UnitPrice:   item.UnitPrice,
+		Quantity:    item.Quantity,
+		Price:       item.Price,
+	}
+}
+
+func NewOrderItems(items []models.OrderItem) []OrderItem {
+	return mapSlice(items, NewOrderItem)
+}
+
+type ShippingAddress struct {
+	ID        uint      `json:"id"`
+	OrderID   uint      `json:"order_id"`
+	Street    string    `json:"street"`
+	City      string    `json:"city"`
+	State     string    `json:"state"`
+	ZipCode   string    `json:"zip_code"`
+	Country   string    `json:"country"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewShippingAddress(address models.ShippingAddress) ShippingAddress {
+	return ShippingAddress{
+		ID:        address.ID,
+		OrderID:   address.OrderID,
+		Street:    address.Street,
+		City:      address.City,
+		State:     address.State,
+		ZipCode:   address.ZipCode,
+		Country:   address.Country,
+		Notes:     address.Notes,
+		CreatedAt: address.CreatedAt,
+		UpdatedAt: address.UpdatedAt,
+	}
+}
+
+// Shipment is the part of an order sent from one warehouse
+type Shipment struct {
+	ID             uint           `json:"id"`
+	OrderID        uint           `json:"order_id"`
+	WarehouseID    uint           `json:"warehouse_id"`
+	Status         string         `json:"status"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	TrackingURL    string         `json:"tracking_url"`
+	Items          []ShipmentItem `json:"items"`
+	ShippedAt      *time.Time     `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func NewShipment(shipment models.Shipment) Shipment {
+	return Shipment{
+		ID:             shipment.ID,
+		OrderID:        shipment.OrderID,
+		WarehouseID:    shipment.WarehouseID,
+		Status:         shipment.Status,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		TrackingURL:    shipment.TrackingURL,
+		Items:          mapSlice(shipment.Items, NewShipmentItem),
+		ShippedAt:      shipment.ShippedAt,
+		DeliveredAt:    shipment.DeliveredAt,
+		CreatedAt:      shipment.CreatedAt,
+		UpdatedAt:      shipment.UpdatedAt,
+	}
+}
+
+func NewShipments(shipments []models.Shipment) []Shipment {
+	return mapSlice(shipments, NewShipment)
+}
+
+type ShipmentItem struct {
+	ID          uint `json:"id"`
+	ShipmentID  uint `json:"shipment_id"`
+	OrderItemID uint `json:"order_item_id"`
+	ProductID   uint `json:"product_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+func NewShipmentItem(item models.ShipmentItem) ShipmentItem {
+	return ShipmentItem{
+		ID:          item.ID,
+		ShipmentID:  item.ShipmentID,
+		OrderItemID: item.OrderItemID,
+		ProductID:   item.ProductID,
+		Quantity:    item.Quantity,
+	}
+}
+
+type Refund struct {
+	ID        uint      `json:"id"`
+	OrderID   uint      `json:"order_id"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewRefund(refund models.Refund) Refund {
+	return Refund{
+		ID:        refund.ID,
+		OrderID:   refund.OrderID,
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+		CreatedBy: refund.CreatedBy,
+		CreatedAt: refund.CreatedAt,
+	}
+}
+
+func NewRefunds(refunds []models.Refund) []Refund {
+	return mapSlice(refunds, NewRefund)
+}
@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+type Product struct {
+	ID                uint              `json:"id"`
+	SKU               string            `json:"sku"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	Price             float64           `json:"price"`
+	Category          string            `json:"category"`
+	ImageURL          string            `json:"image_url"`
+	Attributes        map[string]string `json:"attributes"`
+	Stock             int               `json:"stock"`
+	LowStockThreshold int               `json:"low_stock_threshold"`
+	IsAvailable       bool              `json:"is_available"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	DeletedAt         *time.Time        `json:"deleted_at,omitempty"`
+}
+
+func NewProduct(product models.Product) Product {
+	return Product{
+		ID:                product.ID,
+		SKU:               product.SKU,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Category:          product.Category,
+		ImageURL:          product.ImageURL,
+		Attributes:        product.Attributes,
+		Stock:             product.Stock,
+		LowStockThreshold: product.LowStockThreshold,
+		IsAvailable:       product.IsAvailable,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
+		DeletedAt:         deletedAt(product.DeletedAt),
+	}
+}
+
+func NewProducts(products []models.Product) []Product {
+	return mapSlice(products, NewProduct)
+}
+
+// ImportJob is the progress and outcome of a product import
+type ImportJob struct {
+	ID           uint        `json:"id"`
+	Format       string      `json:"format"`
+	FileName     string      `json:"file_name"`
+	DryRun       bool        `json:"dry_run"`
+	Status       string      `json:"status"`
+	TotalRows    int         `json:"total_rows"`
+	CreatedCount int         `json:"created_count"`
+	UpdatedCount int         `json:"updated_count"`
+	FailedCount  int         `json:"failed_count"`
+	Errors       models.JSON `json:"errors"`
+	Message      string      `json:"message"`
+	CreatedBy    uint        `json:"created_by"`
+	CreatedAt    time.Time   `json:"created_at"`
+	StartedAt    *time.Time  `json:"started_at"`
+	FinishedAt   *time.Time  `json:"finished_at"`
+}
+
+func NewImportJob(job models.ImportJob) ImportJob {
+	return ImportJob{
+		ID:           job.ID,
+		Format:       job.Format,
+		FileName:     job.FileName,
+		DryRun:       job.DryRun,
+		Status:       job.Status,
+		TotalRows:    job.TotalRows,
+		CreatedCount: job.CreatedCount,
+		UpdatedCount: job.UpdatedCount,
+		FailedCount:  job.FailedCount,
+		Errors:       job.Errors,
+		Message:      job.Message,
+		CreatedBy:    job.CreatedBy,
+		CreatedAt:    job.CreatedAt,
+		StartedAt:    job.StartedAt,
+		FinishedAt:   job.FinishedAt,
+	}
+}
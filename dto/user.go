@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+// User is an account as shown to the user and to admins
+type User struct {
+	ID               uint       `json:"id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	HasPassword      bool       `json:"has_password"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewUser(user models.User) User {
+	return User{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		HasPassword:      !user.PasswordUnset,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		DeletedAt:        deletedAt(user.DeletedAt),
+	}
+}
+
+func NewUsers(users []models.User) []User {
+	return mapSlice(users, NewUser)
+}
+
+// UserIdentity is an identity provider account linked to a user
+type UserIdentity struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"user_id"`
+	Provider    string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewUserIdentity(identity models.UserIdentity) UserIdentity {
+	return UserIdentity{
+		ID:          identity.ID,
+		UserID:      identity.UserID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: identity.LastLoginAt,
+		CreatedAt:   identity.CreatedAt,
+	}
+}
+
+func NewUserIdentities(identities []models.UserIdentity) []UserIdentity {
+	return mapSlice(identities, NewUserIdentity)
+}
+
+// NotificationPreference is which emails a user wants
+type NotificationPreference struct {
+	UserID         uint      `json:"user_id"`
+	OrderEmails    bool      `json:"order_emails"`
+	ShippingEmails bool      `json:"shipping_emails"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func NewNotificationPreference(preference models.NotificationPreference) NotificationPreference {
+	return NotificationPreference{
+		UserID:         preference.UserID,
+		OrderEmails:    preference.OrderEmails,
+		ShippingEmails: preference.ShippingEmails,
+		UpdatedAt:      preference.UpdatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+// WebhookEndpoint is a subscribed URL. Its secret is only returned when it
+// is generated.
+type WebhookEndpoint struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Events      []string  `json:"events"`
+	IsActive    bool      `json:"is_active"`
+	CreatedBy   uint      `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewWebhookEndpoint(endpoint models.WebhookEndpoint) WebhookEndpoint {
+	return WebhookEndpoint{
+		ID:          endpoint.ID,
+		URL:         endpoint.URL,
+		Description: endpoint.Description,
+		Events:      endpoint.Events,
+		IsActive:    endpoint.IsActive,
+		CreatedBy:   endpoint.CreatedBy,
+		CreatedAt:   endpoint.CreatedAt,
+		UpdatedAt:   endpoint.UpdatedAt,
+	}
+}
+
+func NewWebhookEndpoints(endpoints []models.WebhookEndpoint) []WebhookEndpoint {
+	return mapSlice(endpoints, NewWebhookEndpoint)
+}
+
+type WebhookDelivery struct {
+	ID             uint        `json:"id"`
+	EndpointID     uint        `json:"endpoint_id"`
+	EventID        uint        `json:"event_id"`
+	EventType      string      `json:"event_type"`
+	Payload        models.JSON `json:"payload"`
+	Status         string      `json:"status"`
+	Attempts       int         `json:"attempts"`
+	NextAttemptAt  time.Time   `json:"next_attempt_at"`
+	LastStatusCode int         `json:"last_status_code"`
+	LastError      string      `json:"last_error"`
+	DeliveredAt    *time.Time  `json:"delivered_at"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func NewWebhookDelivery(delivery models.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             delivery.ID,
+		EndpointID:     delivery.EndpointID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
+
+func NewWebhookDeliveries(deliveries []models.WebhookDelivery) []WebhookDelivery {
+	return mapSlice(deliveries, NewWebhookDelivery)
+}
@@ -0,0 +1,84 @@
+// Package eventbus fans committed domain events out to in-process
+// subscribers such as the Server-Sent Events streams.
+package eventbus
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before it
+// is dropped
+const subscriberBuffer = 64
+
+// Event is a committed outbox event
+type Event struct {
+	ID      uint
+	Type    string
+	Payload json.RawMessage
+}
+
+// Bus delivers published events to every matching subscriber
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives the events accepted by its filter on C. C is closed
+// when the subscription is closed or falls too far behind; streams should
+// then end and let the client reconnect from its last event.
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	filter func(Event) bool
+	bus    *Bus
+}
+
+// Default is the bus fed by the database event listener
+var Default = New()
+
+func New() *Bus {
+	return &Bus{subscribers: map[*Subscription]struct{}{}}
+}
+
+// Subscribe registers a subscriber for the events accepted by filter; a nil
+// filter accepts every event
+func (b *Bus) Subscribe(filter func(Event) bool) *Subscription {
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter, bus: b}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Publish hands event to the matching subscribers without blocking
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// A slow subscriber would hold everyone up, so drop it
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close unregisters the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if _, ok := s.bus.subscribers[s]; ok {
+		delete(s.bus.subscribers, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,209 @@
+package helpers
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// Allocation strategies used at checkout
+const (
+	// AllocationNearest fills every line from the closest warehouse that has
+	// stock, splitting the order across warehouses when needed
+	AllocationNearest = "nearest"
+	// AllocationSingle prefers the closest warehouse able to ship the whole
+	// order, and falls back to AllocationNearest when there is none
+	AllocationSingle = "single"
+)
+
+// AllocationStrategyFromEnv reads ALLOCATION_STRATEGY, defaulting to nearest
+func AllocationStrategyFromEnv() string {
+	if strings.ToLower(os.Getenv("ALLOCATION_STRATEGY")) == AllocationSingle {
+		return AllocationSingle
+	}
+	return AllocationNearest
+}
+
+// AllocationLine is a quantity of an order line to fulfil
+type AllocationLine struct {
+	OrderItemID uint
+	ProductID   uint
+	Quantity    int
+}
+
+// Allocation is the set of lines shipped from one warehouse
+type Allocation struct {
+	WarehouseID uint
+	Lines       []AllocationLine
+}
+
+// warehouseDistance ranks how close a warehouse is to an address, from 0
+// (same zip code) to 4 (another country). Without geocoding this is the best
+// proximity signal the address gives us.
+func warehouseDistance(warehouse models.Warehouse, address models.ShippingAddress) int {
+	same := func(a, b string) bool {
+		return a != "" && strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+
+	if !same(warehouse.Country, address.Country) {
+		return 4
+	}
+	switch {
+	case same(warehouse.ZipCode, address.ZipCode):
+		return 0
+	case same(warehouse.City, address.City) && same(warehouse.State, address.State):
+		return 1
+	case same(warehouse.State, address.State):
+		return 2
+	}
+	return 3
+}
+
+// rankWarehouses orders active warehouses from nearest to farthest, breaking
+// ties by priority
+func rankWarehouses(warehouses []models.Warehouse, address models.ShippingAddress) {
+	sort.SliceStable(warehouses, func(i, j int) bool {
+		di, dj := warehouseDistance(warehouses[i], address), warehouseDistance(warehouses[j], address)
+		if di != dj {
+			return di < dj
+		}
+		if warehouses[i].Priority != warehouses[j].Priority {
+			return warehouses[i].Priority < warehouses[j].Priority
+		}
+		return warehouses[i].ID < warehouses[j].ID
+	})
+}
+
+// AllocateOrder decides which warehouse ships each order line. It returns
+// ErrInsufficientStock when the active warehouses together cannot cover it.
+func AllocateOrder(tx *gorm.DB, address models.ShippingAddress, lines []AllocationLine, strategy string) ([]Allocation, error) {
+	var warehouses []models.Warehouse
+	if err := tx.Where("is_active = ?", true).Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+	rankWarehouses(warehouses, address)
+
+	productIDs := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+
+	var stocks []models.WarehouseStock
+	if err := tx.Where("product_id IN ? AND quantity > 0", productIDs).Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+
+	// available[warehouseID][productID]
+	available := map[uint]map[uint]int{}
+	for _, stock := range stocks {
+		if available[stock.WarehouseID] == nil {
+			available[stock.WarehouseID] = map[uint]int{}
+		}
+		available[stock.WarehouseID][stock.ProductID] = stock.Quantity
+	}
+
+	if strategy == AllocationSingle {
+		for _, warehouse := range warehouses {
+			if canFulfil(available[warehouse.ID], lines) {
+				return []Allocation{{WarehouseID: warehouse.ID, Lines: lines}}, nil
+			}
+		}
+	}
+
+	return allocateNearest(warehouses, available, lines)
+}
+
+func canFulfil(stock map[uint]int, lines []AllocationLine) bool {
+	needed := map[uint]int{}
+	for _, line := range lines {
+		needed[line.ProductID] += line.Quantity
+	}
+	for productID, quantity := range needed {
+		if stock[productID] < quantity {
+			return false
+		}
+	}
+	return true
+}
+
+func allocateNearest(warehouses []models.Warehouse, available map[uint]map[uint]int, lines []AllocationLine) ([]Allocation, error) {
+	byWarehouse := map[uint]*Allocation{}
+	var order []uint
+
+	for _, line := range lines {
+		remaining := line.Quantity
+		for _, warehouse := range warehouses {
+			if remaining == 0 {
+				break
+			}
+			stock := available[warehouse.ID][line.ProductID]
+			if stock == 0 {
+				continue
+			}
+
+			take := min(stock, remaining)
+			available[warehouse.ID][line.ProductID] -= take
+			remaining -= take
+
+			allocation, ok := byWarehouse[warehouse.ID]
+			if !ok {
+				allocation = &Allocation{WarehouseID: warehouse.ID}
+				byWarehouse[warehouse.ID] = allocation
+				order = append(order, warehouse.ID)
+			}
+			allocation.Lines = append(allocation.Lines, AllocationLine{
+				OrderItemID: line.OrderItemID,
+				ProductID:   line.ProductID,
+				Quantity:    take,
+			})
+		}
+		if remaining > 0 {
+			return nil, ErrInsufficientStock
+		}
+	}
+
+	allocations := make([]Allocation, 0, len(order))
+	for _, warehouseID := range order {
+		allocations = append(allocations, *byWarehouse[warehouseID])
+	}
+	return allocations, nil
+}
+
+// CreateShipments records one shipment per allocated warehouse and takes
+// the allocated stock out of it
+func CreateShipments(tx *gorm.DB, orderID uint, allocations []Allocation) error {
+	for _, allocation := range allocations {
+		shipment := models.Shipment{
+			OrderID:     orderID,
+			WarehouseID: allocation.WarehouseID,
+			Status:      models.ShipmentStatusPending,
+		}
+		for _, line := range allocation.Lines {
+			shipment.Items = append(shipment.Items, models.ShipmentItem{
+				OrderItemID: line.OrderItemID,
+				ProductID:   line.ProductID,
+				Quantity:    line.Quantity,
+			})
+		}
+		if err := tx.Create(&shipment).Error; err != nil {
+			return err
+		}
+
+		for _, line := range allocation.Lines {
+			_, err := AdjustStock(tx, StockChange{
+				ProductID:   line.ProductID,
+				WarehouseID: allocation.WarehouseID,
+				Delta:       -line.Quantity,
+				Reason:      models.StockReasonSale,
+				Reference:   OrderStockReference(orderID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package helpers
+
+import "context"
+
+// AuditMeta identifies who made a change and from which request
+type AuditMeta struct {
+	ActorID   uint
+	IP        string
+	RequestID string
+}
+
+type auditMetaKey struct{}
+
+// WithAuditMeta attaches audit details to ctx; database writes made with the
+// returned context are recorded in the audit log
+func WithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+// AuditMetaFromContext returns the audit details attached to ctx, if any
+func AuditMetaFromContext(ctx context.Context) (AuditMeta, bool) {
+	if ctx == nil {
+		return AuditMeta{}, false
+	}
+	meta, ok := ctx.Value(auditMetaKey{}).(AuditMeta)
+	return meta, ok
+}
@@ -0,0 +1,128 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/mailer"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// shippingTemplates are the emails governed by the shipping preference; the
+// others follow the order preference
+var shippingTemplates = map[string]bool{
+	mailer.TemplateOrderShipped:   true,
+	mailer.TemplateOrderDelivered: true,
+}
+
+// NotificationPreferenceFor returns the saved preferences of a user, or the
+// defaults when there are none
+func NotificationPreferenceFor(tx *gorm.DB, userID uint) (models.NotificationPreference, error) {
+	preference := models.DefaultNotificationPreference(userID)
+	err := tx.Where("user_id = ?", userID).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationPreference(userID), nil
+	}
+	return preference, err
+}
+
+// OrderEmail describes an email about an order to queue. Lines, when set,
+// replace the order lines shown in the email, e.g. for a partial shipment.
+type OrderEmail struct {
+	Template       string
+	OrderID        uint
+	EventID        uint
+	Lines          map[uint]int
+	Carrier        string
+	TrackingNumber string
+	TrackingURL    string
+	RefundAmount   float64
+	RefundReason   string
+}
+
+// QueueOrderEmail renders an order email for its customer and adds it to the
+// mail queue, unless the customer opted out of that kind of email
+func QueueOrderEmail(tx *gorm.DB, email OrderEmail) error {
+	var order models.Order
+	err := tx.Preload("Items").Preload("ShippingAddress").
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&order, email.OrderID).Error
+	if err != nil {
+		return err
+	}
+	if order.User.DeletedAt.Valid {
+		return nil
+	}
+
+	preference, err := NotificationPreferenceFor(tx, order.UserID)
+	if err != nil {
+		return err
+	}
+	if shippingTemplates[email.Template] && !preference.ShippingEmails {
+		return nil
+	}
+	if !shippingTemplates[email.Template] && !preference.OrderEmails {
+		return nil
+	}
+
+	branding := StoreBrandingFromEnv()
+	data := mailer.OrderEmailData{
+		StoreName:      branding.Name,
+		StoreEmail:     branding.Email,
+		CustomerName:   order.User.Name,
+		OrderID:        order.ID,
+		Status:         order.Status,
+		Total:          branding.money(order.TotalAmount),
+		Carrier:        email.Carrier,
+		TrackingNumber: email.TrackingNumber,
+		TrackingURL:    email.TrackingURL,
+		RefundAmount:   branding.money(email.RefundAmount),
+		RefundReason:   email.RefundReason,
+		RefundedTotal:  branding.money(order.RefundedAmount),
+	}
+	for _, item := range order.Items {
+		quantity := item.Quantity
+		if email.Lines != nil {
+			if quantity = email.Lines[item.ID]; quantity == 0 {
+				continue
+			}
+		}
+		data.Items = append(data.Items, mailer.EmailLine{
+			Name:      item.ProductName,
+			SKU:       item.SKU,
+			Quantity:  quantity,
+			UnitPrice: branding.money(item.UnitPrice),
+			Total:     branding.money(item.UnitPrice * float64(quantity)),
+		})
+	}
+	address := order.ShippingAddress
+	if address.Street != "" {
+		data.ShippingAddress = []string{
+			address.Street,
+			strings.TrimSpace(strings.Join([]string{address.City, address.State, address.ZipCode}, " ")),
+			address.Country,
+		}
+	}
+
+	subject, html, text, err := mailer.Render(email.Template, data)
+	if err != nil {
+		return err
+	}
+
+	message := models.EmailMessage{
+		UserID:        &order.UserID,
+		Template:      email.Template,
+		To:            order.User.Email,
+		Subject:       subject,
+		HTMLBody:      html,
+		TextBody:      text,
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if email.EventID != 0 {
+		message.EventID = &email.EventID
+	}
+	return tx.Create(&message).Error
+}
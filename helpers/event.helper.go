@@ -0,0 +1,210 @@
+package helpers
+
+import (
+	"encoding/json"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// Event types written to the outbox
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderRefunded      = "order.refunded"
+	EventShipmentShipped    = "shipment.shipped"
+	EventShipmentDelivered  = "shipment.delivered"
+	EventProductCreated     = "product.created"
+	EventProductUpdated     = "product.updated"
+	EventProductDeleted     = "product.deleted"
+	EventProductStockLow    = "product.stock_low"
+	EventUserCreated        = "user.created"
+	EventUserDeleted        = "user.deleted"
+)
+
+// EventTypes lists every event that can be subscribed to
+var EventTypes = []string{
+	EventOrderCreated,
+	EventOrderStatusChanged,
+	EventOrderRefunded,
+	EventShipmentShipped,
+	EventShipmentDelivered,
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventProductStockLow,
+	EventUserCreated,
+	EventUserDeleted,
+}
+
+// IsEventType reports whether eventType is a known event
+func IsEventType(eventType string) bool {
+	for _, known := range EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderEventItem is an order line in order event payloads
+type OrderEventItem struct {
+	OrderItemID uint    `json:"order_item_id"`
+	ProductID   uint    `json:"product_id"`
+	SKU         string  `json:"sku"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+// OrderEventData is the payload of order events
+type OrderEventData struct {
+	OrderID        uint             `json:"order_id"`
+	UserID         uint             `json:"user_id"`
+	Status         string           `json:"status"`
+	PreviousStatus string           `json:"previous_status,omitempty"`
+	TotalAmount    float64          `json:"total_amount"`
+	Items          []OrderEventItem `json:"items,omitempty"`
+}
+
+// ShipmentEventItem is a shipped quantity in shipment event payloads
+type ShipmentEventItem struct {
+	OrderItemID uint `json:"order_item_id"`
+	ProductID   uint `json:"product_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+// ShipmentEventData is the payload of shipment events
+type ShipmentEventData struct {
+	ShipmentID     uint                `json:"shipment_id"`
+	OrderID        uint                `json:"order_id"`
+	WarehouseID    uint                `json:"warehouse_id"`
+	Status         string              `json:"status"`
+	Carrier        string              `json:"carrier"`
+	TrackingNumber string              `json:"tracking_number"`
+	TrackingURL    string              `json:"tracking_url"`
+	Items          []ShipmentEventItem `json:"items"`
+}
+
+// RefundEventData is the payload of order.refunded
+type RefundEventData struct {
+	RefundID       uint    `json:"refund_id"`
+	OrderID        uint    `json:"order_id"`
+	UserID         uint    `json:"user_id"`
+	Amount         float64 `json:"amount"`
+	Reason         string  `json:"reason"`
+	RefundedAmount float64 `json:"refunded_amount"`
+	TotalAmount    float64 `json:"total_amount"`
+}
+
+// ProductEventData is the payload of product events
+type ProductEventData struct {
+	ProductID         uint    `json:"product_id"`
+	SKU               string  `json:"sku"`
+	Name              string  `json:"name"`
+	Price             float64 `json:"price"`
+	Stock             int     `json:"stock"`
+	LowStockThreshold int     `json:"low_stock_threshold"`
+	IsAvailable       bool    `json:"is_available"`
+}
+
+// UserEventData is the payload of user events
+type UserEventData struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// NewOrderEventData builds the payload of an order event
+func NewOrderEventData(order models.Order, items []models.OrderItem) OrderEventData {
+	data := OrderEventData{
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		Status:      order.Status,
+		TotalAmount: order.TotalAmount,
+	}
+	for _, item := range items {
+		data.Items = append(data.Items, OrderEventItem{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			SKU:         item.SKU,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		})
+	}
+	return data
+}
+
+// NewShipmentEventData builds the payload of a shipment event
+func NewShipmentEventData(shipment models.Shipment) ShipmentEventData {
+	data := ShipmentEventData{
+		ShipmentID:     shipment.ID,
+		OrderID:        shipment.OrderID,
+		WarehouseID:    shipment.WarehouseID,
+		Status:         shipment.Status,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		TrackingURL:    shipment.TrackingURL,
+		Items:          []ShipmentEventItem{},
+	}
+	for _, item := range shipment.Items {
+		data.Items = append(data.Items, ShipmentEventItem{
+			OrderItemID: item.OrderItemID,
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+		})
+	}
+	return data
+}
+
+// NewProductEventData builds the payload of a product event
+func NewProductEventData(product models.Product) ProductEventData {
+	return ProductEventData{
+		ProductID:         product.ID,
+		SKU:               product.SKU,
+		Name:              product.Name,
+		Price:             product.Price,
+		Stock:             product.Stock,
+		LowStockThreshold: product.LowStockThreshold,
+		IsAvailable:       product.IsAvailable,
+	}
+}
+
+// NewUserEventData builds the payload of a user event
+func NewUserEventData(user models.User) UserEventData {
+	return UserEventData{UserID: user.ID, Name: user.Name, Email: user.Email, Role: user.Role}
+}
+
+// EventNotifyChannel is the Postgres channel announcing committed events to
+// every instance
+const EventNotifyChannel = "outbox_events"
+
+// EventNotification is the NOTIFY payload of an event. Payloads are read from
+// the outbox, as NOTIFY is limited to 8000 bytes.
+type EventNotification struct {
+	ID   uint   `json:"id"`
+	Type string `json:"type"`
+}
+
+// PublishEvent writes an event to the outbox. Call it with the transaction
+// that makes the change, so the event is only ever seen if it commits. The
+// NOTIFY sent alongside is also delivered only on commit.
+func PublishEvent(tx *gorm.DB, eventType string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	event := models.OutboxEvent{Type: eventType, Payload: models.JSON(raw)}
+	if err := tx.Create(&event).Error; err != nil {
+		return err
+	}
+
+	notification, err := json.Marshal(EventNotification{ID: event.ID, Type: event.Type})
+	if err != nil {
+		return err
+	}
+	return tx.Exec("SELECT pg_notify(?, ?)", EventNotifyChannel, string(notification)).Error
+}
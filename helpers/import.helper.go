@@ -0,0 +1,292 @@
+package helpers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// ProductImportColumns is the column order used for CSV import and export
+var ProductImportColumns = []string{"sku", "name", "description", "price", "category", "image_url", "stock", "is_available", "attributes"}
+
+// ProductImportRow is one validated product from an import file
+type ProductImportRow struct {
+	Row         int               `json:"-"`
+	SKU         string            `json:"sku"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	Category    string            `json:"category"`
+	ImageURL    string            `json:"image_url"`
+	Stock       int               `json:"stock"`
+	IsAvailable bool              `json:"is_available"`
+	Attributes  models.Attributes `json:"attributes"`
+}
+
+// ImportRowError describes why a row of an import file was rejected
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// productImportRecord is the raw shape of an NDJSON line; pointers tell
+// missing fields apart from zero values
+type productImportRecord struct {
+	SKU         string            `json:"sku"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       *float64          `json:"price"`
+	Category    string            `json:"category"`
+	ImageURL    string            `json:"image_url"`
+	Stock       *int              `json:"stock"`
+	IsAvailable *bool             `json:"is_available"`
+	Attributes  models.Attributes `json:"attributes"`
+}
+
+// ParseProductImport reads every row of a CSV or NDJSON product file.
+// Valid rows and row-level errors are both returned; err is only set when
+// the file as a whole cannot be read.
+func ParseProductImport(r io.Reader, format string) ([]ProductImportRow, []ImportRowError, error) {
+	var (
+		rows      []ProductImportRow
+		rowErrors []ImportRowError
+		err       error
+	)
+
+	switch format {
+	case ImportFormatCSV:
+		rows, rowErrors, err = parseProductCSV(r)
+	case ImportFormatNDJSON:
+		rows, rowErrors, err = parseProductNDJSON(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A SKU may only appear once per file
+	seen := map[string]int{}
+	valid := rows[:0]
+	for _, row := range rows {
+		if first, ok := seen[row.SKU]; ok {
+			rowErrors = append(rowErrors, ImportRowError{Row: row.Row, SKU: row.SKU, Field: "sku", Message: fmt.Sprintf("duplicate SKU, first seen on row %d", first)})
+			continue
+		}
+		seen[row.SKU] = row.Row
+		valid = append(valid, row)
+	}
+
+	return valid, rowErrors, nil
+}
+
+func parseProductCSV(r io.Reader) ([]ProductImportRow, []ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("file is empty")
+		}
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"sku", "name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	var (
+		rows      []ProductImportRow
+		rowErrors []ImportRowError
+	)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := ProductImportRow{
+			Row:         line,
+			SKU:         value("sku"),
+			Name:        value("name"),
+			Description: value("description"),
+			Category:    value("category"),
+			ImageURL:    value("image_url"),
+			IsAvailable: true,
+		}
+		var fieldErrors []ImportRowError
+		fail := func(field, message string) {
+			fieldErrors = append(fieldErrors, ImportRowError{Row: line, SKU: row.SKU, Field: field, Message: message})
+		}
+
+		if price := value("price"); price == "" {
+			fail("price", "is required")
+		} else if row.Price, err = strconv.ParseFloat(price, 64); err != nil {
+			fail("price", "must be a number")
+		}
+		if stock := value("stock"); stock != "" {
+			if row.Stock, err = strconv.Atoi(stock); err != nil {
+				fail("stock", "must be a whole number")
+			}
+		}
+		if available := value("is_available"); available != "" {
+			if row.IsAvailable, err = strconv.ParseBool(available); err != nil {
+				fail("is_available", "must be true or false")
+			}
+		}
+		if attributes := value("attributes"); attributes != "" {
+			if err := json.Unmarshal([]byte(attributes), &row.Attributes); err != nil {
+				fail("attributes", "must be a JSON object of strings")
+			}
+		}
+
+		fieldErrors = append(fieldErrors, validateImportRow(row)...)
+		if len(fieldErrors) > 0 {
+			rowErrors = append(rowErrors, fieldErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+func parseProductNDJSON(r io.Reader) ([]ProductImportRow, []ImportRowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var (
+		rows      []ProductImportRow
+		rowErrors []ImportRowError
+	)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record productImportRecord
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: line, Message: "invalid JSON: " + err.Error()})
+			continue
+		}
+
+		row := ProductImportRow{
+			Row:         line,
+			SKU:         strings.TrimSpace(record.SKU),
+			Name:        strings.TrimSpace(record.Name),
+			Description: record.Description,
+			Category:    record.Category,
+			ImageURL:    record.ImageURL,
+			IsAvailable: true,
+			Attributes:  record.Attributes,
+		}
+		var fieldErrors []ImportRowError
+		if record.Price == nil {
+			fieldErrors = append(fieldErrors, ImportRowError{Row: line, SKU: row.SKU, Field: "price", Message: "is required"})
+		} else {
+			row.Price = *record.Price
+		}
+		if record.Stock != nil {
+			row.Stock = *record.Stock
+		}
+		if record.IsAvailable != nil {
+			row.IsAvailable = *record.IsAvailable
+		}
+
+		fieldErrors = append(fieldErrors, validateImportRow(row)...)
+		if len(fieldErrors) > 0 {
+			rowErrors = append(rowErrors, fieldErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return rows, rowErrors, nil
+}
+
+func validateImportRow(row ProductImportRow) []ImportRowError {
+	var rowErrors []ImportRowError
+	fail := func(field, message string) {
+		rowErrors = append(rowErrors, ImportRowError{Row: row.Row, SKU: row.SKU, Field: field, Message: message})
+	}
+
+	if row.SKU == "" {
+		fail("sku", "is required")
+	} else if len(row.SKU) > 64 {
+		fail("sku", "must be at most 64 characters")
+	}
+	if row.Name == "" {
+		fail("name", "is required")
+	} else if len(row.Name) > 255 {
+		fail("name", "must be at most 255 characters")
+	}
+	if row.Price < 0 {
+		fail("price", "must not be negative")
+	}
+	if row.Stock < 0 {
+		fail("stock", "must not be negative")
+	}
+	if len(row.Category) > 100 {
+		fail("category", "must be at most 100 characters")
+	}
+	return rowErrors
+}
+
+// ProductCSVRecord formats a product as a CSV row in ProductImportColumns order
+func ProductCSVRecord(product models.Product) []string {
+	attributes := ""
+	if len(product.Attributes) > 0 {
+		raw, _ := json.Marshal(product.Attributes)
+		attributes = string(raw)
+	}
+	return []string{
+		product.SKU,
+		product.Name,
+		product.Description,
+		strconv.FormatFloat(product.Price, 'f', 2, 64),
+		product.Category,
+		product.ImageURL,
+		strconv.Itoa(product.Stock),
+		strconv.FormatBool(product.IsAvailable),
+		attributes,
+	}
+}
@@ -0,0 +1,325 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/pdf"
+)
+
+// StoreBranding is the store identity printed on invoices and packing slips
+type StoreBranding struct {
+	Name          string
+	AddressLines  []string
+	Email         string
+	Phone         string
+	TaxID         string
+	Currency      string
+	InvoicePrefix string
+	FooterNote    string
+}
+
+// StoreBrandingFromEnv reads STORE_NAME, STORE_ADDRESS (lines separated by
+// "|"), STORE_EMAIL, STORE_PHONE, STORE_TAX_ID, STORE_CURRENCY,
+// INVOICE_PREFIX and INVOICE_FOOTER
+func StoreBrandingFromEnv() StoreBranding {
+	branding := StoreBranding{
+		Name:          envOrDefault("STORE_NAME", "Ecommerce Store"),
+		Email:         os.Getenv("STORE_EMAIL"),
+		Phone:         os.Getenv("STORE_PHONE"),
+		TaxID:         os.Getenv("STORE_TAX_ID"),
+		Currency:      envOrDefault("STORE_CURRENCY", "USD"),
+		InvoicePrefix: envOrDefault("INVOICE_PREFIX", "INV-"),
+		FooterNote:    envOrDefault("INVOICE_FOOTER", "Thank you for your order!"),
+	}
+	if address := os.Getenv("STORE_ADDRESS"); address != "" {
+		branding.AddressLines = strings.Split(address, "|")
+	}
+	return branding
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// FormatInvoiceNumber turns a sequence into the printed invoice number
+func (b StoreBranding) FormatInvoiceNumber(sequence uint) string {
+	return fmt.Sprintf("%s%06d", b.InvoicePrefix, sequence)
+}
+
+func (b StoreBranding) money(amount float64) string {
+	return fmt.Sprintf("%s %.2f", b.Currency, amount)
+}
+
+const (
+	docMarginLeft  = 50.0
+	docMarginRight = pdf.A4Width - 50.0
+	docBottomLimit = pdf.A4Height - 80.0
+	docRowHeight   = 18.0
+)
+
+// documentLayout tracks the current page and vertical position while
+// rendering a document that may span several pages
+type documentLayout struct {
+	doc    *pdf.Document
+	page   *pdf.Page
+	y      float64
+	header func(page *pdf.Page) float64
+}
+
+func newDocumentLayout(title string, header func(page *pdf.Page) float64) *documentLayout {
+	layout := &documentLayout{doc: pdf.New(title), header: header}
+	layout.newPage()
+	return layout
+}
+
+func (l *documentLayout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = l.header(l.page)
+}
+
+// ensureSpace starts a new page when height does not fit on the current one
+func (l *documentLayout) ensureSpace(height float64, onNewPage func()) {
+	if l.y+height > docBottomLimit {
+		l.newPage()
+		if onNewPage != nil {
+			onNewPage()
+		}
+	}
+}
+
+// drawStoreHeader prints the store identity on the left and the document
+// title on the right, returning the y position below it
+func drawStoreHeader(page *pdf.Page, branding StoreBranding, title string) float64 {
+	page.Text(docMarginLeft, 60, pdf.HelveticaBold, 18, branding.Name)
+	page.TextRight(docMarginRight, 60, pdf.HelveticaBold, 20, title)
+
+	y := 78.0
+	lines := append([]string{}, branding.AddressLines...)
+	if branding.Email != "" {
+		lines = append(lines, branding.Email)
+	}
+	if branding.Phone != "" {
+		lines = append(lines, branding.Phone)
+	}
+	if branding.TaxID != "" {
+		lines = append(lines, "Tax ID: "+branding.TaxID)
+	}
+	for _, line := range lines {
+		page.Text(docMarginLeft, y, pdf.Helvetica, 9, line)
+		y += 12
+	}
+	return y
+}
+
+// drawKeyValues prints right-aligned "label value" rows starting at y
+func drawKeyValues(page *pdf.Page, y float64, rows [][2]string) float64 {
+	for _, row := range rows {
+		page.TextRight(docMarginRight-110, y, pdf.HelveticaBold, 9, row[0])
+		page.TextRight(docMarginRight, y, pdf.Helvetica, 9, row[1])
+		y += 13
+	}
+	return y
+}
+
+func drawShipTo(page *pdf.Page, y float64, customerName string, order *models.Order) float64 {
+	address := order.ShippingAddress
+	page.Text(docMarginLeft, y, pdf.HelveticaBold, 10, "Ship To")
+	y += 14
+
+	lines := []string{
+		customerName,
+		address.Street,
+		strings.TrimSpace(fmt.Sprintf("%s, %s %s", address.City, address.State, address.ZipCode)),
+		address.Country,
+		"Phone: " + order.ContactNumber,
+	}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		page.Text(docMarginLeft, y, pdf.Helvetica, 10, line)
+		y += 13
+	}
+	return y
+}
+
+func formatAttributes(attributes models.Attributes) string {
+	if len(attributes) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + attributes[key]
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RenderInvoicePDF renders an invoice for order. The order must have its
+// Items and ShippingAddress loaded.
+func RenderInvoicePDF(branding StoreBranding, invoice models.Invoice, order *models.Order, customerName string) []byte {
+	title := "Invoice " + invoice.Number
+	header := func(page *pdf.Page) float64 {
+		y := drawStoreHeader(page, branding, "INVOICE")
+		infoY := drawKeyValues(page, 84, [][2]string{
+			{"Invoice No:", invoice.Number},
+			{"Invoice Date:", invoice.IssuedAt.Format("Jan 2, 2006")},
+			{"Order No:", fmt.Sprintf("#%d", order.ID)},
+			{"Order Date:", order.CreatedAt.Format("Jan 2, 2006")},
+		})
+		if infoY > y {
+			y = infoY
+		}
+		return y + 20
+	}
+	layout := newDocumentLayout(title, header)
+
+	layout.y = drawShipTo(layout.page, layout.y, customerName, order) + 20
+
+	const (
+		colSKU    = 290.0
+		colQty    = 390.0
+		colUnit   = 465.0
+		colAmount = docMarginRight
+	)
+	tableHeader := func() {
+		page := layout.page
+		page.FillRect(docMarginLeft, layout.y-12, docMarginRight-docMarginLeft, docRowHeight, 0.9)
+		page.Text(docMarginLeft+4, layout.y, pdf.HelveticaBold, 9, "Item")
+		page.Text(colSKU, layout.y, pdf.HelveticaBold, 9, "SKU")
+		page.TextRight(colQty, layout.y, pdf.HelveticaBold, 9, "Qty")
+		page.TextRight(colUnit, layout.y, pdf.HelveticaBold, 9, "Unit Price")
+		page.TextRight(colAmount-4, layout.y, pdf.HelveticaBold, 9, "Amount")
+		layout.y += docRowHeight + 4
+	}
+	tableHeader()
+
+	var subtotal float64
+	for _, item := range order.Items {
+		attributes := formatAttributes(item.Attributes)
+		height := docRowHeight
+		if attributes != "" {
+			height += 11
+		}
+		layout.ensureSpace(height, tableHeader)
+
+		page := layout.page
+		page.Text(docMarginLeft+4, layout.y, pdf.Helvetica, 9, pdf.Truncate(pdf.Helvetica, 9, item.ProductName, colSKU-docMarginLeft-14))
+		page.Text(colSKU, layout.y, pdf.Helvetica, 9, pdf.Truncate(pdf.Helvetica, 9, item.SKU, colQty-colSKU-30))
+		page.TextRight(colQty, layout.y, pdf.Helvetica, 9, fmt.Sprintf("%d", item.Quantity))
+		page.TextRight(colUnit, layout.y, pdf.Helvetica, 9, branding.money(item.UnitPrice))
+		page.TextRight(colAmount-4, layout.y, pdf.Helvetica, 9, branding.money(item.Price))
+		if attributes != "" {
+			page.Text(docMarginLeft+10, layout.y+11, pdf.Helvetica, 7, pdf.Truncate(pdf.Helvetica, 7, attributes, colSKU-docMarginLeft-20))
+		}
+		layout.y += height
+		subtotal += item.Price
+	}
+
+	layout.ensureSpace(60, nil)
+	page := layout.page
+	page.Line(colQty-60, layout.y-6, docMarginRight, layout.y-6, 0.5)
+	layout.y += 8
+	page.TextRight(colUnit, layout.y, pdf.Helvetica, 10, "Subtotal")
+	page.TextRight(colAmount-4, layout.y, pdf.Helvetica, 10, branding.money(subtotal))
+	layout.y += 16
+	page.TextRight(colUnit, layout.y, pdf.HelveticaBold, 11, "Total")
+	page.TextRight(colAmount-4, layout.y, pdf.HelveticaBold, 11, branding.money(order.TotalAmount))
+	layout.y += 30
+
+	if branding.FooterNote != "" {
+		layout.ensureSpace(20, nil)
+		layout.page.Text(docMarginLeft, layout.y, pdf.Helvetica, 9, branding.FooterNote)
+	}
+
+	return layout.doc.Bytes()
+}
+
+// RenderPackingSlipPDF renders the picking list that goes in the parcel.
+// It lists quantities only, never prices.
+func RenderPackingSlipPDF(branding StoreBranding, order *models.Order, customerName string) []byte {
+	header := func(page *pdf.Page) float64 {
+		y := drawStoreHeader(page, branding, "PACKING SLIP")
+		infoY := drawKeyValues(page, 84, [][2]string{
+			{"Order No:", fmt.Sprintf("#%d", order.ID)},
+			{"Order Date:", order.CreatedAt.Format("Jan 2, 2006")},
+			{"Status:", order.Status},
+		})
+		if infoY > y {
+			y = infoY
+		}
+		return y + 20
+	}
+	layout := newDocumentLayout(fmt.Sprintf("Packing slip #%d", order.ID), header)
+
+	layout.y = drawShipTo(layout.page, layout.y, customerName, order)
+	if notes := order.ShippingAddress.Notes; notes != "" {
+		layout.page.Text(docMarginLeft, layout.y, pdf.HelveticaBold, 9, "Notes:")
+		layout.page.Text(docMarginLeft+35, layout.y, pdf.Helvetica, 9, pdf.Truncate(pdf.Helvetica, 9, notes, docMarginRight-docMarginLeft-35))
+		layout.y += 13
+	}
+	layout.y += 20
+
+	const (
+		colSKU    = 330.0
+		colQty    = 470.0
+		colPacked = docMarginRight - 30
+	)
+	tableHeader := func() {
+		page := layout.page
+		page.FillRect(docMarginLeft, layout.y-12, docMarginRight-docMarginLeft, docRowHeight, 0.9)
+		page.Text(docMarginLeft+4, layout.y, pdf.HelveticaBold, 9, "Item")
+		page.Text(colSKU, layout.y, pdf.HelveticaBold, 9, "SKU")
+		page.TextRight(colQty, layout.y, pdf.HelveticaBold, 9, "Qty")
+		page.Text(colPacked-8, layout.y, pdf.HelveticaBold, 9, "Packed")
+		layout.y += docRowHeight + 4
+	}
+	tableHeader()
+
+	totalUnits := 0
+	for _, item := range order.Items {
+		attributes := formatAttributes(item.Attributes)
+		height := docRowHeight
+		if attributes != "" {
+			height += 11
+		}
+		layout.ensureSpace(height, tableHeader)
+
+		page := layout.page
+		page.Text(docMarginLeft+4, layout.y, pdf.Helvetica, 10, pdf.Truncate(pdf.Helvetica, 10, item.ProductName, colSKU-docMarginLeft-14))
+		page.Text(colSKU, layout.y, pdf.Helvetica, 10, pdf.Truncate(pdf.Helvetica, 10, item.SKU, colQty-colSKU-40))
+		page.TextRight(colQty, layout.y, pdf.HelveticaBold, 10, fmt.Sprintf("%d", item.Quantity))
+
+		// Empty checkbox for the packer
+		boxX, boxY := colPacked+2, layout.y-9
+		page.Line(boxX, boxY, boxX+10, boxY, 0.7)
+		page.Line(boxX+10, boxY, boxX+10, boxY+10, 0.7)
+		page.Line(boxX+10, boxY+10, boxX, boxY+10, 0.7)
+		page.Line(boxX, boxY+10, boxX, boxY, 0.7)
+
+		if attributes != "" {
+			page.Text(docMarginLeft+10, layout.y+11, pdf.Helvetica, 8, pdf.Truncate(pdf.Helvetica, 8, attributes, colSKU-docMarginLeft-20))
+		}
+		layout.y += height
+		totalUnits += item.Quantity
+	}
+
+	layout.ensureSpace(30, nil)
+	layout.page.Line(colSKU, layout.y-6, docMarginRight, layout.y-6, 0.5)
+	layout.y += 8
+	layout.page.TextRight(colQty, layout.y, pdf.HelveticaBold, 10, fmt.Sprintf("Total units: %d", totalUnits))
+
+	return layout.doc.Bytes()
+}
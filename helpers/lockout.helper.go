@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+const (
+	defaultLoginMaxFailures = 5
+	defaultLoginLockout     = time.Minute
+	maxLoginLockout         = 24 * time.Hour
+)
+
+// LockoutPolicy controls how an account is locked after repeated failed
+// sign-ins. The first lockout lasts Base and every further failure doubles
+// it, up to a day.
+type LockoutPolicy struct {
+	MaxFailures int
+	Base        time.Duration
+}
+
+// LockoutPolicyFromEnv reads LOGIN_MAX_FAILURES and LOGIN_LOCKOUT (a Go
+// duration such as "1m")
+func LockoutPolicyFromEnv() LockoutPolicy {
+	policy := LockoutPolicy{
+		MaxFailures: defaultLoginMaxFailures,
+		Base:        defaultLoginLockout,
+	}
+
+	if failures, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && failures > 0 {
+		policy.MaxFailures = failures
+	}
+	if lockout, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT")); err == nil && lockout > 0 {
+		policy.Base = lockout
+	}
+
+	return policy
+}
+
+// LockoutFor returns how long an account with failures failed sign-ins
+// stays locked
+func (p LockoutPolicy) LockoutFor(failures int) time.Duration {
+	if failures < p.MaxFailures {
+		return 0
+	}
+	lockout := p.Base
+	for i := p.MaxFailures; i < failures && lockout < maxLoginLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, maxLoginLockout)
+}
+
+// LockedFor returns how long the user must still wait before signing in
+func LockedFor(user models.User) time.Duration {
+	if user.LockedUntil == nil {
+		return 0
+	}
+	return max(time.Until(*user.LockedUntil), 0)
+}
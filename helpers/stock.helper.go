@@ -0,0 +1,307 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrNoDefaultWarehouse = errors.New("no default warehouse configured")
+)
+
+// StockChange describes one movement to apply to a product's stock.
+// A zero WarehouseID means the default warehouse.
+type StockChange struct {
+	ProductID   uint
+	WarehouseID uint
+	Delta       int
+	Reason      string
+	Reference   string
+	Note        string
+	ActorID     uint
+}
+
+// OrderStockReference is the ledger reference used for movements caused by an order
+func OrderStockReference(orderID uint) string {
+	return fmt.Sprintf("order:%d", orderID)
+}
+
+// DefaultWarehouseID returns the warehouse used when a stock change does not
+// name one
+func DefaultWarehouseID(tx *gorm.DB) (uint, error) {
+	var warehouse models.Warehouse
+	err := tx.Select("id").Where("is_default = ?", true).Order("id").First(&warehouse).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrNoDefaultWarehouse
+	}
+	return warehouse.ID, err
+}
+
+// lockWarehouseStock returns the stock row of a product in a warehouse,
+// creating it when missing, locked until the transaction ends
+func lockWarehouseStock(tx *gorm.DB, warehouseID, productID uint) (*models.WarehouseStock, error) {
+	stock := models.WarehouseStock{WarehouseID: warehouseID, ProductID: productID}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "warehouse_id"}, {Name: "product_id"}},
+		DoNothing: true,
+	}).Create(&stock).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND product_id = ?", warehouseID, productID).
+		First(&stock).Error
+	return &stock, err
+}
+
+// applyWarehouseDelta changes the quantity held in one warehouse
+func applyWarehouseDelta(tx *gorm.DB, warehouseID, productID uint, delta int) error {
+	stock, err := lockWarehouseStock(tx, warehouseID, productID)
+	if err != nil {
+		return err
+	}
+	if stock.Quantity+delta < 0 {
+		return ErrInsufficientStock
+	}
+	return tx.Model(stock).UpdateColumn("quantity", stock.Quantity+delta).Error
+}
+
+func newStockMovement(change StockChange, warehouseID uint, delta, balance int) models.StockMovement {
+	movement := models.StockMovement{
+		ProductID:    change.ProductID,
+		WarehouseID:  &warehouseID,
+		Delta:        delta,
+		BalanceAfter: balance,
+		Reason:       change.Reason,
+		Reference:    change.Reference,
+		Note:         change.Note,
+	}
+	if change.ActorID != 0 {
+		movement.ActorID = &change.ActorID
+	}
+	return movement
+}
+
+// AdjustStock locks the product row, applies change to its stock in the
+// given warehouse and appends the movement to the ledger. It must be called
+// inside a transaction so the stock and the ledger never disagree.
+func AdjustStock(tx *gorm.DB, change StockChange) (*models.StockMovement, error) {
+	var product models.Product
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, change.ProductID).Error; err != nil {
+		return nil, err
+	}
+
+	warehouseID := change.WarehouseID
+	if warehouseID == 0 {
+		var err error
+		if warehouseID, err = DefaultWarehouseID(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	balance := product.Stock + change.Delta
+	if balance < 0 {
+		return nil, ErrInsufficientStock
+	}
+	if err := applyWarehouseDelta(tx, warehouseID, product.ID, change.Delta); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Unscoped().Model(&product).UpdateColumn("stock", balance).Error; err != nil {
+		return nil, err
+	}
+
+	movement := newStockMovement(change, warehouseID, change.Delta, balance)
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+
+	if product.Stock > product.LowStockThreshold && balance <= product.LowStockThreshold {
+		if err := notifyLowStock(tx, product, balance); err != nil {
+			return nil, err
+		}
+	}
+
+	return &movement, nil
+}
+
+// TransferStock moves quantity of a product between two warehouses,
+// recording both legs in the ledger. The product's total stock is unchanged.
+func TransferStock(tx *gorm.DB, productID, fromWarehouseID, toWarehouseID uint, quantity int, reference, note string, actorID uint) ([]models.StockMovement, error) {
+	var product models.Product
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := applyWarehouseDelta(tx, fromWarehouseID, productID, -quantity); err != nil {
+		return nil, err
+	}
+	if err := applyWarehouseDelta(tx, toWarehouseID, productID, quantity); err != nil {
+		return nil, err
+	}
+
+	change := StockChange{ProductID: productID, Reason: models.StockReasonTransfer, Reference: reference, Note: note, ActorID: actorID}
+	movements := []models.StockMovement{
+		newStockMovement(change, fromWarehouseID, -quantity, product.Stock),
+		newStockMovement(change, toWarehouseID, quantity, product.Stock),
+	}
+	if err := tx.Create(&movements).Error; err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// SetStock records the movement needed to bring a product's stock to level,
+// applied to the default warehouse
+func SetStock(tx *gorm.DB, productID uint, level int, reason, note string, actorID uint) error {
+	var product models.Product
+	if err := tx.Unscoped().Select("id", "stock").First(&product, productID).Error; err != nil {
+		return err
+	}
+	if product.Stock == level {
+		return nil
+	}
+
+	_, err := AdjustStock(tx, StockChange{
+		ProductID: productID,
+		Delta:     level - product.Stock,
+		Reason:    reason,
+		Note:      note,
+		ActorID:   actorID,
+	})
+	return err
+}
+
+// notifyLowStock publishes product.stock_low when a product crosses its
+// low-stock threshold
+func notifyLowStock(tx *gorm.DB, product models.Product, balance int) error {
+	product.Stock = balance
+	return PublishEvent(tx, EventProductStockLow, NewProductEventData(product))
+}
+
+// RestockOrder returns the unshipped items of a cancelled order to the
+// warehouses they were allocated from and cancels their shipments. Orders
+// placed before warehouses existed are returned to the default warehouse.
+// The order is locked until the transaction ends and is only ever restocked
+// once, so concurrent cancels cannot return its stock twice.
+func RestockOrder(tx *gorm.DB, orderID uint, actorID uint) error {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&order, orderID).Error; err != nil {
+		return err
+	}
+
+	var restocked int64
+	err := tx.Model(&models.StockMovement{}).
+		Where("reason = ? AND reference = ?", models.StockReasonCancel, OrderStockReference(orderID)).
+		Count(&restocked).Error
+	if err != nil {
+		return err
+	}
+	if restocked > 0 {
+		return nil
+	}
+
+	var shipments []models.Shipment
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).Preload("Items").Find(&shipments).Error
+	if err != nil {
+		return err
+	}
+
+	changes := []StockChange{}
+	for _, shipment := range shipments {
+		if shipment.Status != models.ShipmentStatusPending {
+			continue
+		}
+		for _, item := range shipment.Items {
+			changes = append(changes, StockChange{ProductID: item.ProductID, WarehouseID: shipment.WarehouseID, Delta: item.Quantity})
+		}
+	}
+	if len(shipments) == 0 {
+		var items []models.OrderItem
+		if err := tx.Where("order_id = ?", orderID).Find(&items).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			changes = append(changes, StockChange{ProductID: item.ProductID, Delta: item.Quantity})
+		}
+	}
+
+	for _, change := range changes {
+		change.Reason = models.StockReasonCancel
+		change.Reference = OrderStockReference(orderID)
+		change.ActorID = actorID
+		if _, err := AdjustStock(tx, change); err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.Shipment{}).
+		Where("order_id = ? AND status = ?", orderID, models.ShipmentStatusPending).
+		Update("status", models.ShipmentStatusCancelled).Error
+}
+
+// StockDrift is a product whose stock disagrees with its ledger
+type StockDrift struct {
+	ProductID   uint `json:"product_id"`
+	Stock       int  `json:"stock"`
+	LedgerStock int  `json:"ledger_stock"`
+}
+
+// WarehouseStockDrift is a warehouse stock level that disagrees with its ledger
+type WarehouseStockDrift struct {
+	WarehouseID uint `json:"warehouse_id"`
+	ProductID   uint `json:"product_id"`
+	Quantity    int  `json:"quantity"`
+	LedgerStock int  `json:"ledger_stock"`
+}
+
+// ReconcileStock compares every product's stock, and every warehouse stock
+// level, with the sum of its ledger. With apply, drifting values are reset
+// to the ledger value; call it inside a transaction so the report matches
+// what was changed.
+func ReconcileStock(tx *gorm.DB, apply bool) ([]StockDrift, []WarehouseStockDrift, error) {
+	var (
+		drifts          []StockDrift
+		warehouseDrifts []WarehouseStockDrift
+	)
+	err := tx.Unscoped().Table("products").
+		Select("products.id AS product_id, products.stock AS stock, COALESCE(SUM(stock_movements.delta), 0) AS ledger_stock").
+		Joins("LEFT JOIN stock_movements ON stock_movements.product_id = products.id").
+		Group("products.id").
+		Having("products.stock <> COALESCE(SUM(stock_movements.delta), 0)").
+		Scan(&drifts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Table("warehouse_stocks").
+		Select("warehouse_stocks.warehouse_id, warehouse_stocks.product_id, warehouse_stocks.quantity, COALESCE(SUM(stock_movements.delta), 0) AS ledger_stock").
+		Joins("LEFT JOIN stock_movements ON stock_movements.product_id = warehouse_stocks.product_id AND stock_movements.warehouse_id = warehouse_stocks.warehouse_id").
+		Group("warehouse_stocks.id").
+		Having("warehouse_stocks.quantity <> COALESCE(SUM(stock_movements.delta), 0)").
+		Scan(&warehouseDrifts).Error
+	if err != nil || !apply {
+		return drifts, warehouseDrifts, err
+	}
+
+	for _, drift := range drifts {
+		if err := tx.Unscoped().Model(&models.Product{ID: drift.ProductID}).UpdateColumn("stock", drift.LedgerStock).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, drift := range warehouseDrifts {
+		err := tx.Model(&models.WarehouseStock{}).
+			Where("warehouse_id = ? AND product_id = ?", drift.WarehouseID, drift.ProductID).
+			UpdateColumn("quantity", drift.LedgerStock).Error
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return drifts, warehouseDrifts, nil
+}
@@ -2,20 +2,27 @@ package helpers
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/sajagsubedi/Ecommerce-Api/signing"
 )
 
 // Define a struct for the claims
 type Claims struct {
 	UserID uint   `json:"user_id"`
 	Role   string `json:"role"`
+	// Purpose is empty for session tokens and names what any other token
+	// may be used for
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-var SECRET_KEY = os.Getenv("AUTH_SECRET_KEY")
+// ChallengePurpose marks a token that only proves the password was correct
+// and must be exchanged for a session token with a second factor
+const ChallengePurpose = "2fa_challenge"
+
+const challengeTokenLifetime = 5 * time.Minute
 
 // Function to generate a JWT token
 func GenerateToken(userID uint, role string) (string, error) {
@@ -35,11 +42,8 @@ func GenerateToken(userID uint, role string) (string, error) {
 		},
 	}
 
-	// Create the token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token with the secret key
-	tokenString, err := token.SignedString([]byte(SECRET_KEY))
+	// Sign the token with the active key
+	tokenString, err := signing.Default.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -51,9 +55,8 @@ func ValidateToken(signedToken string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
 		&Claims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(SECRET_KEY), nil
-		},
+		signing.Default.Keyfunc,
+		jwt.WithValidMethods(signing.Algorithms),
 	)
 	if err != nil {
 		return nil, err
@@ -67,6 +70,38 @@ func ValidateToken(signedToken string) (*Claims, error) {
 		return nil, fmt.Errorf("Token is expired")
 	}
 
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("Invalid token")
+	}
+
 	return claims, nil
 
 }
+
+// GenerateChallengeToken issues a short-lived token for a user who gave the
+// right password but still has to pass two-factor authentication
+func GenerateChallengeToken(userID uint) (string, error) {
+	claims := &Claims{
+		UserID:  userID,
+		Purpose: ChallengePurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(challengeTokenLifetime)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "ecommerce_api",
+		},
+	}
+
+	return signing.Default.Sign(claims)
+}
+
+// ValidateChallengeToken returns the user a challenge token was issued to
+func ValidateChallengeToken(signedToken string) (uint, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(signedToken, claims, signing.Default.Keyfunc,
+		jwt.WithValidMethods(signing.Algorithms), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid || claims.Purpose != ChallengePurpose {
+		return 0, fmt.Errorf("Invalid or expired challenge token")
+	}
+	return claims.UserID, nil
+}
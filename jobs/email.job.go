@@ -0,0 +1,237 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/mailer"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultEmailInterval    = 5 * time.Second
+	defaultEmailBatchSize   = 50
+	defaultEmailMaxAttempts = 6
+	defaultEmailBaseDelay   = time.Minute
+	emailSendTimeout        = 30 * time.Second
+	// A claimed email is not picked up again before this lease expires
+	emailLease = 2 * time.Minute
+)
+
+// EmailPolicy controls how queued emails are sent and retried
+type EmailPolicy struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// EmailPolicyFromEnv reads EMAIL_POLL_INTERVAL, EMAIL_MAX_ATTEMPTS and
+// EMAIL_RETRY_BASE_DELAY (Go durations for the interval and the delay)
+func EmailPolicyFromEnv() EmailPolicy {
+	policy := EmailPolicy{
+		Interval:    defaultEmailInterval,
+		BatchSize:   defaultEmailBatchSize,
+		MaxAttempts: defaultEmailMaxAttempts,
+		BaseDelay:   defaultEmailBaseDelay,
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("EMAIL_POLL_INTERVAL")); err == nil && interval > 0 {
+		policy.Interval = interval
+	}
+	if attempts, err := strconv.Atoi(os.Getenv("EMAIL_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		policy.MaxAttempts = attempts
+	}
+	if delay, err := time.ParseDuration(os.Getenv("EMAIL_RETRY_BASE_DELAY")); err == nil && delay > 0 {
+		policy.BaseDelay = delay
+	}
+
+	return policy
+}
+
+// EnqueueOrderEmails is the event handler that queues the customer emails of
+// the order lifecycle
+func EnqueueOrderEmails(tx *gorm.DB, event models.OutboxEvent) error {
+	switch event.Type {
+	case helpers.EventOrderCreated:
+		var data helpers.OrderEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		return helpers.QueueOrderEmail(tx, helpers.OrderEmail{Template: mailer.TemplateOrderConfirmation, OrderID: data.OrderID, EventID: event.ID})
+
+	case helpers.EventOrderStatusChanged:
+		var data helpers.OrderEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		// Shipped emails come from shipment.shipped, which carries the tracking
+		switch data.Status {
+		case models.OrderStatusDelivered:
+			return helpers.QueueOrderEmail(tx, helpers.OrderEmail{Template: mailer.TemplateOrderDelivered, OrderID: data.OrderID, EventID: event.ID})
+		case models.OrderStatusCancelled:
+			return helpers.QueueOrderEmail(tx, helpers.OrderEmail{Template: mailer.TemplateOrderCancelled, OrderID: data.OrderID, EventID: event.ID})
+		}
+
+	case helpers.EventShipmentShipped:
+		var data helpers.ShipmentEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		lines := map[uint]int{}
+		for _, item := range data.Items {
+			lines[item.OrderItemID] += item.Quantity
+		}
+		return helpers.QueueOrderEmail(tx, helpers.OrderEmail{
+			Template:       mailer.TemplateOrderShipped,
+			OrderID:        data.OrderID,
+			EventID:        event.ID,
+			Lines:          lines,
+			Carrier:        data.Carrier,
+			TrackingNumber: data.TrackingNumber,
+			TrackingURL:    data.TrackingURL,
+		})
+
+	case helpers.EventOrderRefunded:
+		var data helpers.RefundEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		return helpers.QueueOrderEmail(tx, helpers.OrderEmail{
+			Template:     mailer.TemplateRefundIssued,
+			OrderID:      data.OrderID,
+			EventID:      event.ID,
+			RefundAmount: data.Amount,
+			RefundReason: data.Reason,
+		})
+	}
+	return nil
+}
+
+// StartEmailSender runs SendEmails every policy.Interval until ctx is
+// cancelled. A panic only ends the tick it happened in.
+func StartEmailSender(ctx context.Context, policy EmailPolicy, sender mailer.Sender) {
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := sendEmailsOnce(ctx, sender, policy); err != nil {
+				log.Printf("Failed to send emails: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sendEmailsOnce runs SendEmails, reporting a panic as an error so it cannot
+// take the API down
+func sendEmailsOnce(ctx context.Context, sender mailer.Sender, policy EmailPolicy) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v\n%s", recovered, debug.Stack())
+		}
+	}()
+	return SendEmails(ctx, sender, policy)
+}
+
+// SendEmails sends the queued emails that are due. Failed emails are retried
+// with exponential backoff and marked failed after MaxAttempts.
+func SendEmails(ctx context.Context, sender mailer.Sender, policy EmailPolicy) error {
+	db := database.DB.WithContext(ctx)
+
+	// Claim a batch by pushing its next attempt past the lease, so other
+	// instances skip it while it is being sent
+	var messages []models.EmailMessage
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailStatusPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(policy.BatchSize).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(messages))
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+		}
+		return tx.Model(&models.EmailMessage{}).Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", time.Now().Add(emailLease)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	from := mailer.DefaultFrom()
+	for _, message := range messages {
+		sendErr := sendEmail(ctx, sender, mailer.Message{
+			From:    from,
+			To:      message.To,
+			Subject: message.Subject,
+			HTML:    message.HTMLBody,
+			Text:    message.TextBody,
+		})
+
+		if err := recordEmailAttempt(db, policy, message, sendErr); err != nil {
+			log.Printf("Failed to record email %d: %v", message.ID, err)
+		}
+	}
+	return nil
+}
+
+// sendEmail sends one message. A panicking sender counts as a failed
+// attempt, so the message is retried and eventually given up on like any
+// other failure instead of stopping the rest of the batch.
+func sendEmail(ctx context.Context, sender mailer.Sender, message mailer.Message) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+	return sender.Send(ctx, message)
+}
+
+// recordEmailAttempt stores the outcome of sending an email and schedules
+// the next attempt when it failed
+func recordEmailAttempt(db *gorm.DB, policy EmailPolicy, message models.EmailMessage, sendErr error) error {
+	now := time.Now()
+	attempts := message.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": "",
+	}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.EmailStatusSent
+		updates["sent_at"] = now
+	case attempts >= policy.MaxAttempts:
+		updates["status"] = models.EmailStatusFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(retryBackoff(policy.BaseDelay, attempts))
+		updates["last_error"] = sendErr.Error()
+	}
+
+	return db.Model(&models.EmailMessage{ID: message.ID}).Updates(updates).Error
+}
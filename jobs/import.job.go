@@ -0,0 +1,227 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// Only the first errors are kept on the job so a broken file cannot bloat it
+const maxStoredImportErrors = 1000
+
+var errImportSKUDeleted = errors.New("a deleted product uses this SKU, restore it first")
+
+// countImportRows returns the number of distinct rows seen in the file
+func countImportRows(rows []helpers.ProductImportRow, rowErrors []helpers.ImportRowError) int {
+	seen := map[int]bool{}
+	for _, row := range rows {
+		seen[row.Row] = true
+	}
+	for _, rowError := range rowErrors {
+		seen[rowError.Row] = true
+	}
+	return len(seen)
+}
+
+func countFailedRows(rowErrors []helpers.ImportRowError) int {
+	seen := map[int]bool{}
+	for _, rowError := range rowErrors {
+		seen[rowError.Row] = true
+	}
+	return len(seen)
+}
+
+// finishImportJob stores the outcome of a job
+func finishImportJob(db *gorm.DB, job *models.ImportJob, rowErrors []helpers.ImportRowError) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.FailedCount = countFailedRows(rowErrors)
+	if job.Status != models.ImportStatusFailed {
+		job.Status = models.ImportStatusCompleted
+	}
+
+	if len(rowErrors) > maxStoredImportErrors {
+		rowErrors = rowErrors[:maxStoredImportErrors]
+	}
+	raw, err := json.Marshal(rowErrors)
+	if err != nil {
+		return err
+	}
+	job.Errors = models.JSON(raw)
+
+	return db.Save(job).Error
+}
+
+// DryRunProductImport validates an import without writing any product,
+// reporting how many rows would be created or updated
+func DryRunProductImport(ctx context.Context, job *models.ImportJob, rows []helpers.ProductImportRow, rowErrors []helpers.ImportRowError) error {
+	db := database.DB.WithContext(ctx)
+	job.TotalRows = countImportRows(rows, rowErrors)
+
+	existing := map[string]models.Product{}
+	for start := 0; start < len(rows); start += 1000 {
+		end := min(start+1000, len(rows))
+		skus := make([]string, 0, end-start)
+		for _, row := range rows[start:end] {
+			skus = append(skus, row.SKU)
+		}
+
+		var products []models.Product
+		if err := db.Unscoped().Select("id", "sku", "deleted_at").Where("sku IN ?", skus).Find(&products).Error; err != nil {
+			return err
+		}
+		for _, product := range products {
+			existing[product.SKU] = product
+		}
+	}
+
+	for _, row := range rows {
+		product, ok := existing[row.SKU]
+		switch {
+		case !ok:
+			job.CreatedCount++
+		case product.DeletedAt.Valid:
+			rowErrors = append(rowErrors, helpers.ImportRowError{Row: row.Row, SKU: row.SKU, Field: "sku", Message: errImportSKUDeleted.Error()})
+		default:
+			job.UpdatedCount++
+		}
+	}
+
+	return finishImportJob(db, job, rowErrors)
+}
+
+// RunProductImport upserts every row by SKU, each in its own transaction,
+// and records the outcome on the job. ctx should carry helpers.AuditMeta so
+// the changes are audited. It runs in its own goroutine for large imports,
+// so a panic marks the job failed instead of reaching the caller.
+func RunProductImport(ctx context.Context, job *models.ImportJob, rows []helpers.ProductImportRow, rowErrors []helpers.ImportRowError) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Import job %d panicked: %v\n%s", job.ID, recovered, debug.Stack())
+			failImportJob(job, "The import stopped unexpectedly")
+		}
+	}()
+
+	db := database.DB.WithContext(ctx)
+
+	started := time.Now()
+	job.Status = models.ImportStatusProcessing
+	job.StartedAt = &started
+	job.TotalRows = countImportRows(rows, rowErrors)
+	if err := db.Save(job).Error; err != nil {
+		log.Printf("Failed to start import job %d: %v", job.ID, err)
+		failImportJob(job, "The import could not be started")
+		return
+	}
+
+	for _, row := range rows {
+		var created bool
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			created, err = upsertImportRow(tx, job, row)
+			return err
+		})
+		if err != nil {
+			rowErrors = append(rowErrors, helpers.ImportRowError{Row: row.Row, SKU: row.SKU, Message: err.Error()})
+			continue
+		}
+		if created {
+			job.CreatedCount++
+		} else {
+			job.UpdatedCount++
+		}
+	}
+
+	if err := finishImportJob(db, job, rowErrors); err != nil {
+		log.Printf("Failed to finish import job %d: %v", job.ID, err)
+		failImportJob(job, "The import result could not be recorded")
+	}
+}
+
+// failImportJob marks a job failed so it does not stay pending or processing
+// forever. Only the status columns are written, with a fresh context, since
+// the full save or the request context may be what failed.
+func failImportJob(job *models.ImportJob, message string) {
+	now := time.Now()
+	job.Status = models.ImportStatusFailed
+	job.Message = message
+	job.FinishedAt = &now
+
+	err := database.DB.Model(job).UpdateColumns(map[string]interface{}{
+		"status":      job.Status,
+		"message":     job.Message,
+		"finished_at": now,
+	}).Error
+	if err != nil {
+		log.Printf("Failed to mark import job %d failed: %v", job.ID, err)
+	}
+}
+
+// upsertImportRow creates the product for row or updates the one with the
+// same SKU, reporting whether it was created
+func upsertImportRow(tx *gorm.DB, job *models.ImportJob, row helpers.ProductImportRow) (bool, error) {
+	var product models.Product
+	err := tx.Unscoped().Where("sku = ?", row.SKU).First(&product).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		product = models.Product{
+			SKU:         row.SKU,
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			Category:    row.Category,
+			ImageURL:    row.ImageURL,
+			IsAvailable: row.IsAvailable,
+			Attributes:  row.Attributes,
+		}
+		if err := tx.Create(&product).Error; err != nil {
+			return true, err
+		}
+		return true, publishImportedProduct(tx, job, product.ID, row.Stock, helpers.EventProductCreated)
+	}
+	if err != nil {
+		return false, err
+	}
+	if product.DeletedAt.Valid {
+		return false, errImportSKUDeleted
+	}
+
+	// Select every column so that zero values such as is_available=false apply
+	err = tx.Model(&product).
+		Select("name", "description", "price", "category", "image_url", "is_available", "attributes").
+		Updates(models.Product{
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			Category:    row.Category,
+			ImageURL:    row.ImageURL,
+			IsAvailable: row.IsAvailable,
+			Attributes:  row.Attributes,
+		}).Error
+	if err != nil {
+		return false, err
+	}
+	return false, publishImportedProduct(tx, job, product.ID, row.Stock, helpers.EventProductUpdated)
+}
+
+// publishImportedProduct sets the imported stock level and publishes the
+// product event
+func publishImportedProduct(tx *gorm.DB, job *models.ImportJob, productID uint, stock int, eventType string) error {
+	if err := helpers.SetStock(tx, productID, stock, models.StockReasonImport, fmt.Sprintf("import job %d", job.ID), job.CreatedBy); err != nil {
+		return err
+	}
+
+	var product models.Product
+	if err := tx.First(&product, productID).Error; err != nil {
+		return err
+	}
+	return helpers.PublishEvent(tx, eventType, helpers.NewProductEventData(product))
+}
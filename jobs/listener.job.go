@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/eventbus"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+const (
+	listenerMinBackoff = time.Second
+	listenerMaxBackoff = 30 * time.Second
+	// Events published while the listener was disconnected are replayed from
+	// the outbox, up to this many
+	listenerCatchUpLimit = 1000
+	// An event is stamped when it is written, not when its transaction
+	// commits, so the replay reaches this far back from the disconnect
+	listenerCatchUpWindow = 5 * time.Minute
+)
+
+// eventListener remembers what has been published so a replay can reach back
+// past the disconnect without publishing an event twice
+type eventListener struct {
+	bus *eventbus.Bus
+	// since is when the listener last stopped listening
+	since time.Time
+	// published maps recently published events to when they were created
+	published map[uint]time.Time
+	forgotAt  time.Time
+}
+
+// StartEventListener LISTENs for committed events from every instance and
+// publishes them on bus, reconnecting until ctx is cancelled
+func StartEventListener(ctx context.Context, bus *eventbus.Bus) {
+	go func() {
+		listener := &eventListener{bus: bus, since: time.Now(), published: map[uint]time.Time{}}
+
+		backoff := listenerMinBackoff
+		for {
+			started := time.Now()
+			err := listener.listen(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Event listener disconnected: %v", err)
+
+			if time.Since(started) > listenerMaxBackoff {
+				backoff = listenerMinBackoff
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, listenerMaxBackoff)
+		}
+	}()
+}
+
+func (l *eventListener) listen(ctx context.Context) error {
+	dsn, err := database.DSN()
+	if err != nil {
+		return err
+	}
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{helpers.EventNotifyChannel}.Sanitize()); err != nil {
+		return err
+	}
+	defer func() { l.since = time.Now() }()
+
+	// Replay what was committed while we were not listening. Transactions
+	// commit out of id order, so the replay goes by creation time and skips
+	// what was already published.
+	var missed []models.OutboxEvent
+	err = database.DB.WithContext(ctx).
+		Where("created_at >= ?", l.since.Add(-listenerCatchUpWindow)).
+		Order("id").Limit(listenerCatchUpLimit).Find(&missed).Error
+	if err != nil {
+		return err
+	}
+	for _, event := range missed {
+		l.publish(event)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var announced helpers.EventNotification
+		if err := json.Unmarshal([]byte(notification.Payload), &announced); err != nil {
+			log.Printf("Ignoring malformed event notification %q", notification.Payload)
+			continue
+		}
+
+		var event models.OutboxEvent
+		if err := database.DB.WithContext(ctx).First(&event, announced.ID).Error; err != nil {
+			log.Printf("Failed to load event %d: %v", announced.ID, err)
+			continue
+		}
+		l.publish(event)
+	}
+}
+
+func (l *eventListener) publish(event models.OutboxEvent) {
+	if _, ok := l.published[event.ID]; ok {
+		return
+	}
+	l.published[event.ID] = event.CreatedAt
+	l.bus.Publish(eventbus.Event{ID: event.ID, Type: event.Type, Payload: json.RawMessage(event.Payload)})
+
+	// The next replay reaches back at most listenerCatchUpWindow from now,
+	// so older events can be forgotten; twice that allows for clock skew
+	if time.Since(l.forgotAt) > listenerCatchUpWindow {
+		cutoff := time.Now().Add(-2 * listenerCatchUpWindow)
+		for id, createdAt := range l.published {
+			if createdAt.Before(cutoff) {
+				delete(l.published, id)
+			}
+		}
+		l.forgotAt = time.Now()
+	}
+}
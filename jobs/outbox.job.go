@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultOutboxInterval    = 2 * time.Second
+	defaultOutboxBatchSize   = 100
+	defaultOutboxMaxAttempts = 10
+	maxRetryDelay            = 6 * time.Hour
+)
+
+// retryBackoff returns the wait before the next attempt after attempts
+// failures: base, doubled each time, capped at maxRetryDelay
+func retryBackoff(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// EventHandler reacts to an outbox event. It runs in the transaction that
+// marks the event processed, so it should only write to the database; slow
+// work such as HTTP calls belongs in a separate worker fed by those writes.
+type EventHandler func(tx *gorm.DB, event models.OutboxEvent) error
+
+type namedEventHandler struct {
+	name    string
+	handler EventHandler
+}
+
+var (
+	eventHandlersMu sync.RWMutex
+	eventHandlers   []namedEventHandler
+)
+
+// RegisterEventHandler adds a handler called for every outbox event
+func RegisterEventHandler(name string, handler EventHandler) {
+	eventHandlersMu.Lock()
+	defer eventHandlersMu.Unlock()
+	eventHandlers = append(eventHandlers, namedEventHandler{name: name, handler: handler})
+}
+
+// OutboxPolicy controls how often the outbox is polled and how many times a
+// failing event is retried
+type OutboxPolicy struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+}
+
+// OutboxPolicyFromEnv reads OUTBOX_POLL_INTERVAL (a Go duration) and
+// OUTBOX_MAX_ATTEMPTS
+func OutboxPolicyFromEnv() OutboxPolicy {
+	policy := OutboxPolicy{
+		Interval:    defaultOutboxInterval,
+		BatchSize:   defaultOutboxBatchSize,
+		MaxAttempts: defaultOutboxMaxAttempts,
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("OUTBOX_POLL_INTERVAL")); err == nil && interval > 0 {
+		policy.Interval = interval
+	}
+	if attempts, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		policy.MaxAttempts = attempts
+	}
+
+	return policy
+}
+
+// StartOutboxDispatcher runs DispatchOutbox every policy.Interval until ctx
+// is cancelled
+func StartOutboxDispatcher(ctx context.Context, policy OutboxPolicy) {
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := DispatchOutbox(ctx, policy); err != nil {
+				log.Printf("Failed to dispatch outbox events: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// DispatchOutbox hands pending events to every registered handler, oldest
+// first. Each event is processed in its own transaction; a failing event is
+// retried on the next run until policy.MaxAttempts is reached.
+func DispatchOutbox(ctx context.Context, policy OutboxPolicy) error {
+	db := database.DB.WithContext(ctx)
+
+	var ids []uint
+	err := db.Model(&models.OutboxEvent{}).
+		Where("processed_at IS NULL AND attempts < ?", policy.MaxAttempts).
+		Order("id").
+		Limit(policy.BatchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	eventHandlersMu.RLock()
+	handlers := append([]namedEventHandler(nil), eventHandlers...)
+	eventHandlersMu.RUnlock()
+
+	for _, id := range ids {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var event models.OutboxEvent
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND processed_at IS NULL", id).
+				First(&event).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Already taken by another instance
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, handler := range handlers {
+				if err := handler.handler(tx, event); err != nil {
+					log.Printf("Event handler %s failed for event %d: %v", handler.name, event.ID, err)
+					return err
+				}
+			}
+
+			return tx.Model(&event).UpdateColumn("processed_at", time.Now()).Error
+		})
+		if err != nil {
+			// Without the attempt the event would be retried without limit
+			recordErr := db.Model(&models.OutboxEvent{ID: id}).UpdateColumns(map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": err.Error(),
+			}).Error
+			if recordErr != nil {
+				log.Printf("Failed to record the failure of event %d: %v (it failed with: %v)", id, recordErr, err)
+			}
+		}
+	}
+	return nil
+}
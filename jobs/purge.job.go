@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+const (
+	defaultRetentionDays = 30
+	defaultPurgeInterval = 24 * time.Hour
+)
+
+// PurgePolicy controls how long soft-deleted records are kept before they
+// are removed for good
+type PurgePolicy struct {
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// PurgePolicyFromEnv reads SOFT_DELETE_RETENTION_DAYS and
+// SOFT_DELETE_PURGE_INTERVAL (a Go duration such as "24h")
+func PurgePolicyFromEnv() PurgePolicy {
+	policy := PurgePolicy{
+		Retention: defaultRetentionDays * 24 * time.Hour,
+		Interval:  defaultPurgeInterval,
+	}
+
+	if days, err := strconv.Atoi(os.Getenv("SOFT_DELETE_RETENTION_DAYS")); err == nil && days >= 0 {
+		policy.Retention = time.Duration(days) * 24 * time.Hour
+	}
+	if interval, err := time.ParseDuration(os.Getenv("SOFT_DELETE_PURGE_INTERVAL")); err == nil && interval > 0 {
+		policy.Interval = interval
+	}
+
+	return policy
+}
+
+// StartPurgeScheduler runs PurgeSoftDeleted and the other cleanups every
+// policy.Interval until ctx is cancelled
+func StartPurgeScheduler(ctx context.Context, policy PurgePolicy) {
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := PurgeSoftDeleted(ctx, policy.Retention); err != nil {
+				log.Printf("Failed to purge soft-deleted records: %v", err)
+			}
+			if err := PurgeExpiredIdempotencyKeys(ctx); err != nil {
+				log.Printf("Failed to purge expired idempotency keys: %v", err)
+			}
+			if err := PurgeRateLimitBuckets(ctx); err != nil {
+				log.Printf("Failed to purge rate limit buckets: %v", err)
+			}
+			if err := PurgeOIDCLoginStates(ctx); err != nil {
+				log.Printf("Failed to purge sign-in states: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PurgeSoftDeleted permanently removes products and users that were deleted
+// more than retention ago. Records still referenced by an order are kept so
+// order history stays intact.
+func PurgeSoftDeleted(ctx context.Context, retention time.Duration) error {
+	db := database.DB.WithContext(ctx)
+	cutoff := time.Now().Add(-retention)
+
+	products := db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM order_items WHERE order_items.product_id = products.id)").
+		Delete(&models.Product{})
+	if products.Error != nil {
+		return products.Error
+	}
+
+	users := db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)").
+		Delete(&models.User{})
+	if users.Error != nil {
+		return users.Error
+	}
+
+	if products.RowsAffected > 0 || users.RowsAffected > 0 {
+		log.Printf("Purged %d products and %d users deleted before %s", products.RowsAffected, users.RowsAffected, cutoff.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys removes stored responses whose retry window has
+// passed
+func PurgeExpiredIdempotencyKeys(ctx context.Context) error {
+	result := database.DB.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Purged %d expired idempotency keys", result.RowsAffected)
+	}
+	return nil
+}
+
+// PurgeRateLimitBuckets removes shared rate limit buckets that have refilled,
+// since a missing bucket is treated as a full one
+func PurgeRateLimitBuckets(ctx context.Context) error {
+	return database.DB.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.RateLimitBucket{}).Error
+}
+
+// PurgeOIDCLoginStates removes sign-ins that never came back from the
+// identity provider
+func PurgeOIDCLoginStates(ctx context.Context) error {
+	return database.DB.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{}).Error
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/signing"
+)
+
+// StartSigningKeyRotation rotates generated signing keys when they are due
+// and reloads the keyring every policy.CheckInterval, so keys created by
+// other instances are picked up, until ctx is cancelled
+func StartSigningKeyRotation(ctx context.Context, policy signing.Policy) {
+	go func() {
+		ticker := time.NewTicker(policy.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if policy.Generate {
+				if err := signing.RotateIfDue(ctx, database.DB, policy); err != nil {
+					log.Printf("Failed to rotate signing keys: %v", err)
+				}
+			}
+			if err := signing.Default.Load(ctx, database.DB); err != nil {
+				log.Printf("Failed to reload signing keys: %v", err)
+			}
+		}
+	}()
+}
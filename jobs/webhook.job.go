@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultWebhookInterval    = 5 * time.Second
+	defaultWebhookBatchSize   = 50
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookBaseDelay   = 30 * time.Second
+	webhookTimeout            = 10 * time.Second
+	// A claimed delivery is not picked up again before this lease expires
+	webhookLease = time.Minute
+)
+
+// WebhookPolicy controls how webhook deliveries are sent and retried
+type WebhookPolicy struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// WebhookPolicyFromEnv reads WEBHOOK_POLL_INTERVAL, WEBHOOK_MAX_ATTEMPTS and
+// WEBHOOK_RETRY_BASE_DELAY (Go durations for the interval and the delay)
+func WebhookPolicyFromEnv() WebhookPolicy {
+	policy := WebhookPolicy{
+		Interval:    defaultWebhookInterval,
+		BatchSize:   defaultWebhookBatchSize,
+		MaxAttempts: defaultWebhookMaxAttempts,
+		BaseDelay:   defaultWebhookBaseDelay,
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("WEBHOOK_POLL_INTERVAL")); err == nil && interval > 0 {
+		policy.Interval = interval
+	}
+	if attempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		policy.MaxAttempts = attempts
+	}
+	if delay, err := time.ParseDuration(os.Getenv("WEBHOOK_RETRY_BASE_DELAY")); err == nil && delay > 0 {
+		policy.BaseDelay = delay
+	}
+
+	return policy
+}
+
+// EnqueueWebhookDeliveries is the event handler that fans an outbox event out
+// to a pending delivery for every active endpoint subscribed to it
+func EnqueueWebhookDeliveries(tx *gorm.DB, event models.OutboxEvent) error {
+	var endpoints []models.WebhookEndpoint
+	if err := tx.Where("is_active = ?", true).Find(&endpoints).Error; err != nil {
+		return err
+	}
+
+	payload, err := helpers.NewWebhookPayload(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if !endpoint.Events.Contains(event.Type) && !endpoint.Events.Contains("*") {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// StartWebhookDeliverer runs DeliverWebhooks every policy.Interval until ctx
+// is cancelled
+func StartWebhookDeliverer(ctx context.Context, policy WebhookPolicy) {
+	client := &http.Client{Timeout: webhookTimeout}
+
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := DeliverWebhooks(ctx, client, policy); err != nil {
+				log.Printf("Failed to deliver webhooks: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// DeliverWebhooks sends the deliveries that are due. Failed deliveries are
+// retried with exponential backoff and marked failed after MaxAttempts.
+func DeliverWebhooks(ctx context.Context, client *http.Client, policy WebhookPolicy) error {
+	db := database.DB.WithContext(ctx)
+
+	// Claim a batch by pushing its next attempt past the lease, so other
+	// instances skip it while it is being sent
+	var deliveries []models.WebhookDelivery
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(policy.BatchSize).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", time.Now().Add(webhookLease)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		var endpoint models.WebhookEndpoint
+		if err := db.First(&endpoint, delivery.EndpointID).Error; err != nil {
+			log.Printf("Failed to load webhook endpoint %d: %v", delivery.EndpointID, err)
+			continue
+		}
+
+		statusCode, sendErr := helpers.SendWebhook(ctx, client, endpoint, delivery)
+		if err := recordWebhookAttempt(db, policy, delivery, statusCode, sendErr); err != nil {
+			log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// recordWebhookAttempt stores the outcome of sending a delivery and schedules
+// the next attempt when it failed
+func recordWebhookAttempt(db *gorm.DB, policy WebhookPolicy, delivery models.WebhookDelivery, statusCode int, sendErr error) error {
+	now := time.Now()
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":         attempts,
+		"last_status_code": statusCode,
+		"last_error":       "",
+	}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["delivered_at"] = now
+	case attempts >= policy.MaxAttempts:
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(retryBackoff(policy.BaseDelay, attempts))
+		updates["last_error"] = sendErr.Error()
+	}
+
+	return db.Model(&models.WebhookDelivery{ID: delivery.ID}).Updates(updates).Error
+}
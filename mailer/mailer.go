@@ -0,0 +1,73 @@
+// Package mailer renders the transactional email templates and sends
+// messages over SMTP, to .eml files on disk or to an in-memory sink.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Message is a rendered email ready to send
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Drivers selectable with MAIL_DRIVER
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+)
+
+// DefaultFrom returns MAIL_FROM, the sender address of every email
+func DefaultFrom() string {
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		return from
+	}
+	return "no-reply@localhost"
+}
+
+// FromEnv builds the sender chosen by MAIL_DRIVER. smtp reads SMTP_HOST,
+// SMTP_PORT, SMTP_USERNAME and SMTP_PASSWORD; file writes to MAIL_FILE_DIR.
+// Without MAIL_DRIVER, emails are written to files for local development.
+func FromEnv() (Sender, error) {
+	switch driver := strings.ToLower(os.Getenv("MAIL_DRIVER")); driver {
+	case DriverSMTP:
+		port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if err != nil {
+			port = 587
+		}
+		sender := &SMTPSender{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}
+		if sender.Host == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required with MAIL_DRIVER=smtp")
+		}
+		return sender, nil
+	case DriverMemory:
+		return NewMemorySender(), nil
+	case DriverFile, "":
+		dir := os.Getenv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		return &FileSender{Dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
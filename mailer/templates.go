@@ -0,0 +1,100 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates available to Render
+const (
+	TemplateOrderConfirmation = "order_confirmation"
+	TemplateOrderShipped      = "order_shipped"
+	TemplateOrderDelivered    = "order_delivered"
+	TemplateOrderCancelled    = "order_cancelled"
+	TemplateRefundIssued      = "refund_issued"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// EmailLine is an order line as shown in emails
+type EmailLine struct {
+	Name      string
+	SKU       string
+	Quantity  int
+	UnitPrice string
+	Total     string
+}
+
+// OrderEmailData is what every order email template is rendered with.
+// Amounts are already formatted with the store currency.
+type OrderEmailData struct {
+	StoreName       string
+	StoreEmail      string
+	CustomerName    string
+	OrderID         uint
+	Status          string
+	Total           string
+	Items           []EmailLine
+	ShippingAddress []string
+	Carrier         string
+	TrackingNumber  string
+	TrackingURL     string
+	RefundAmount    string
+	RefundReason    string
+	RefundedTotal   string
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var templates = map[string]emailTemplate{}
+
+func init() {
+	for _, name := range []string{
+		TemplateOrderConfirmation,
+		TemplateOrderShipped,
+		TemplateOrderDelivered,
+		TemplateOrderCancelled,
+		TemplateRefundIssued,
+	} {
+		templates[name] = emailTemplate{
+			html: htmltemplate.Must(htmltemplate.ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html")),
+			text: texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/"+name+".txt")),
+		}
+	}
+}
+
+// Render executes a template, returning the subject and both bodies
+func Render(name string, data OrderEmailData) (subject, html, text string, err error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tmpl.text.ExecuteTemplate(&buf, "body", data); err != nil {
+		return "", "", "", err
+	}
+	text = buf.String()
+
+	buf.Reset()
+	if err := tmpl.html.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return "", "", "", err
+	}
+	html = buf.String()
+
+	return subject, html, text, nil
+}
@@ -1,14 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
 	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/eventbus"
+	"github.com/sajagsubedi/Ecommerce-Api/jobs"
+	"github.com/sajagsubedi/Ecommerce-Api/mailer"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/oidc"
+	"github.com/sajagsubedi/Ecommerce-Api/ratelimit"
 	"github.com/sajagsubedi/Ecommerce-Api/routes"
+	"github.com/sajagsubedi/Ecommerce-Api/signing"
 )
 
 func main() {
@@ -18,10 +34,74 @@ func main() {
 		log.Fatal("Error on loading .env file")
 	}
 
+	name, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		name, args = os.Args[1], os.Args[2:]
+	}
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Print(usage())
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage())
+		os.Exit(2)
+	}
+	if err := cmd.run(args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serve runs the HTTP API
+func serve(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: serve")
+	}
+
 	// Initialize database connection
-	if err := database.ConnectDB(); err != nil {
+	app, err := connect()
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
+	if err := migrateOnStart(); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	// Load the keys access tokens are signed with; without one nobody can
+	// sign in, so refuse to start
+	signingPolicy, err := signing.PolicyFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure token signing: %v", err)
+	}
+	if err := signing.Bootstrap(context.Background(), database.DB, signingPolicy); err != nil {
+		log.Fatalf("Failed to load token signing keys: %v", err)
+	}
+
+	// Share rate limits between instances when configured
+	rateLimitStore, err := ratelimit.StoreFromEnv(database.DB)
+	if err != nil {
+		log.Fatalf("Failed to configure rate limiting: %v", err)
+	}
+	ratelimit.Default = rateLimitStore
+
+	// Register the identity providers users can sign in with
+	if err := oidc.ProvidersFromEnv(); err != nil {
+		log.Fatalf("Failed to configure identity providers: %v", err)
+	}
+
+	// Start background jobs
+	jobs.StartPurgeScheduler(context.Background(), jobs.PurgePolicyFromEnv())
+	jobs.StartSigningKeyRotation(context.Background(), signingPolicy)
+	mailSender, err := mailer.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure mail sender: %v", err)
+	}
+	jobs.RegisterEventHandler("webhooks", jobs.EnqueueWebhookDeliveries)
+	jobs.RegisterEventHandler("emails", jobs.EnqueueOrderEmails)
+	jobs.StartOutboxDispatcher(context.Background(), jobs.OutboxPolicyFromEnv())
+	jobs.StartWebhookDeliverer(context.Background(), jobs.WebhookPolicyFromEnv())
+	jobs.StartEmailSender(context.Background(), jobs.EmailPolicyFromEnv(), mailSender)
+	jobs.StartEventListener(context.Background(), eventbus.Default)
 
 	// Get port from environment or default to 8000
 	port := os.Getenv("PORT")
@@ -29,9 +109,30 @@ func main() {
 		port = "8000"
 	}
 
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:" + port
+	}
+	mockProvider, err := oidc.MockFromEnv(baseURL)
+	if err != nil {
+		log.Fatalf("Failed to start mock identity provider: %v", err)
+	}
+
 	// Initialize Gin router
 	router := gin.New()
 
+	// Only take the client address from X-Forwarded-For when the request
+	// comes through one of our own proxies. Rate limits, the audit log and
+	// API key usage all record ClientIP.
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+
+	// Report binding errors by the JSON field names clients send
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(apperror.JSONTagName)
+	}
+
 	// CORS configuration
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"*"}
@@ -46,20 +147,52 @@ func main() {
 		"Origin",
 		"Content-Type",
 		"Authorization",
+		"X-Request-ID",
+		"X-API-Key",
+	}
+	config.ExposeHeaders = []string{
+		"X-Request-ID",
+		"RateLimit-Limit",
+		"RateLimit-Remaining",
+		"RateLimit-Reset",
+		"Retry-After",
 	}
 
 	// Apply middlewares
+	router.Use(middlewares.RequestID())
 	router.Use(gin.Logger())
+	router.Use(apperror.Recovery())
 	router.Use(cors.New(config))
+	router.Use(middlewares.RateLimit("global", ratelimit.Per(300, time.Minute), middlewares.ByIP))
 
 	// Set up routes
-	routes.AuthRoutes(router)
-	routes.ProductRoutes(router)
-	routes.CartRoutes(router)
-	routes.OrderRoutes(router)
-	routes.UserRoutes(router)
+	if mockProvider != nil {
+		router.Any("/mock-oidc/*path", gin.WrapH(http.StripPrefix("/mock-oidc", mockProvider)))
+	}
+	routes.Register(router, app)
+
+	// A route missing from the OpenAPI document is a bug the tests catch;
+	// it is not worth refusing to serve the API over
+	if err := routes.OpenAPIRoutes(router); err != nil {
+		log.Printf("OpenAPI document is incomplete: %v", err)
+	}
+	router.NoRoute(apperror.NoRoute())
 
 	// Start the server
 	log.Printf("Server running on port %s", port)
-	router.Run(":" + port)
+	return router.Run(":" + port)
+}
+
+// trustedProxies reads TRUSTED_PROXIES, a comma-separated list of the IP
+// addresses or CIDR ranges of the load balancers in front of the API, such
+// as "10.0.0.0/8". When unset no proxy is trusted and the client address is
+// the address of the connection.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
 }
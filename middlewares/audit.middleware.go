@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+)
+
+// AuditTrail marks the request context so that every change the handler
+// writes to an auditable model is recorded against the authenticated user.
+// It must run after CheckAdmin or CheckUser.
+func AuditTrail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userid")
+		actorID, _ := userID.(uint)
+
+		ctx := helpers.WithAuditMeta(c.Request.Context(), helpers.AuditMeta{
+			ActorID:   actorID,
+			IP:        c.ClientIP(),
+			RequestID: c.GetString("requestid"),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -1,11 +1,13 @@
 package middlewares
 
 import (
-	"fmt"
-	"net/http"
+	"crypto/subtle"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
 	"github.com/sajagsubedi/Ecommerce-Api/database"
 	"github.com/sajagsubedi/Ecommerce-Api/helpers"
 	"github.com/sajagsubedi/Ecommerce-Api/models"
@@ -44,18 +46,76 @@ func DecodeJwt(c *gin.Context) (*models.User, string) {
 		return nil, err.Error()
 	}
 
-	fmt.Print(existingUser)
 	return existingUser, ""
 }
 
+// apiKeyTouchInterval limits how often last-use tracking writes to the
+// database for a busy key
+const apiKeyTouchInterval = time.Minute
+
+// DecodeAPIKey resolves the X-API-Key header to the key and the admin who
+// issued it. A key stops working when it is revoked or expires, or when its
+// issuer is deleted or no longer an admin.
+func DecodeAPIKey(c *gin.Context) (*models.User, *models.APIKey, string) {
+	rawKey := c.GetHeader("X-API-Key")
+	prefix := helpers.APIKeyPrefixOf(rawKey)
+	if prefix == "" {
+		return nil, nil, "Invalid API key"
+	}
+
+	apiKey := new(models.APIKey)
+	err := database.DB.Where("prefix = ?", prefix).First(apiKey).Error
+	if err != nil || subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(helpers.HashAPIKey(rawKey))) != 1 {
+		return nil, nil, "Invalid API key"
+	}
+
+	now := time.Now()
+	if !apiKey.Active(now) {
+		return nil, nil, "API key has expired or been revoked"
+	}
+
+	issuer := new(models.User)
+	if err := database.DB.Where("id = ?", apiKey.CreatedBy).First(issuer).Error; err != nil || issuer.Role != "admin" {
+		return nil, nil, "API key is no longer valid"
+	}
+
+	database.DB.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", apiKey.ID, now.Add(-apiKeyTouchInterval)).
+		UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": c.ClientIP()})
+
+	return issuer, apiKey, ""
+}
+
+// authenticate accepts an API key or a token and, for API keys, checks that
+// the key's scopes allow the request
+func authenticate(c *gin.Context) (*models.User, *models.APIKey, bool) {
+	if c.GetHeader("X-API-Key") == "" {
+		user, msg := DecodeJwt(c)
+		if msg != "" {
+			apperror.Respond(c, apperror.Unauthorized(msg).WithCode(apperror.CodeInvalidToken))
+			return nil, nil, false
+		}
+		return user, nil, true
+	}
+
+	user, apiKey, msg := DecodeAPIKey(c)
+	if msg != "" {
+		apperror.Respond(c, apperror.Unauthorized(msg).WithCode(apperror.CodeInvalidAPIKey))
+		return nil, nil, false
+	}
+	if !helpers.ScopesAllow(apiKey.Scopes, helpers.RequiredScope(c.Request.Method, c.FullPath())) {
+		apperror.Respond(c, apperror.Forbidden("API key is not allowed to make this request").
+			WithCode(apperror.CodeInsufficientScope))
+		return nil, nil, false
+	}
+	c.Set("apikeyid", apiKey.ID)
+	return user, apiKey, true
+}
+
 func CheckUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, msg := DecodeJwt(c)
-		if msg != "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false, "message": msg,
-			})
-			c.Abort()
+		claims, _, ok := authenticate(c)
+		if !ok {
 			return
 		}
 
@@ -65,22 +125,33 @@ func CheckUser() gin.HandlerFunc {
 	}
 }
 
+// adminTwoFactorRequired reads REQUIRE_ADMIN_2FA, which is on unless set to
+// "false"
+func adminTwoFactorRequired() bool {
+	return os.Getenv("REQUIRE_ADMIN_2FA") != "false"
+}
+
+// CheckAdmin lets through admins and their API keys only. Unless
+// REQUIRE_ADMIN_2FA is "false", admins must have two-factor authentication
+// enabled; they enroll through the user routes.
 func CheckAdmin() gin.HandlerFunc {
+	requireTwoFactor := adminTwoFactorRequired()
+
 	return func(c *gin.Context) {
-		claims, msg := DecodeJwt(c)
-		if msg != "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false, "message": msg,
-			})
-			c.Abort()
+		claims, apiKey, ok := authenticate(c)
+		if !ok {
 			return
 		}
 
 		if claims.Role != "admin" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false, "message": "Unauthorized access",
-			})
-			c.Abort()
+			apperror.Respond(c, apperror.Unauthorized("Unauthorized access"))
+			return
+		}
+
+		// Keys are issued from a session that already passed this check
+		if requireTwoFactor && apiKey == nil && !claims.TwoFactorEnabled {
+			apperror.Respond(c, apperror.Forbidden("Admin accounts must enable two-factor authentication").
+				WithCode(apperror.CodeTwoFactorRequired))
 			return
 		}
 		c.Set("userid", claims.ID)
@@ -0,0 +1,190 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/database"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	defaultIdempotencyTTL   = 24 * time.Hour
+	defaultIdempotencyBody  = 1 << 20
+)
+
+// responseRecorder keeps a copy of everything the handler writes
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyTTL reads IDEMPOTENCY_KEY_TTL (a Go duration such as "24h")
+func idempotencyTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaultIdempotencyTTL
+}
+
+// idempotencyMaxBody reads IDEMPOTENCY_MAX_BODY_BYTES, the largest body the
+// middleware reads into memory to fingerprint
+func idempotencyMaxBody() int64 {
+	if limit, err := strconv.ParseInt(os.Getenv("IDEMPOTENCY_MAX_BODY_BYTES"), 10, 64); err == nil && limit > 0 {
+		return limit
+	}
+	return defaultIdempotencyBody
+}
+
+// requestFingerprint hashes what makes two requests the same request
+func requestFingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Idempotency makes a request sent with an Idempotency-Key header safe to
+// retry. The first request runs and its response is stored; retries with the
+// same key and payload get the stored response back, while a different
+// payload under the same key is rejected. Server errors are not stored so
+// the request can be retried. Keys expire after IDEMPOTENCY_KEY_TTL, and
+// bodies over IDEMPOTENCY_MAX_BODY_BYTES are refused. It must run after
+// CheckUser or CheckAdmin when the route is authenticated.
+func Idempotency() gin.HandlerFunc {
+	ttl := idempotencyTTL()
+	maxBody := idempotencyMaxBody()
+
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			apperror.Respond(c, apperror.BadRequest("Idempotency-Key is too long"))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				apperror.Respond(c, apperror.PayloadTooLarge("Request body is too large"))
+				return
+			}
+			apperror.Respond(c, apperror.BadRequest("Failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID, _ := c.Get("userid")
+		ownerID, _ := userID.(uint)
+		db := database.DB.WithContext(c.Request.Context())
+		now := time.Now()
+
+		// An expired key is free to be used again
+		err = db.Where("user_id = ? AND key = ? AND expires_at < ?", ownerID, key, now).Delete(&models.IdempotencyKey{}).Error
+		if err != nil {
+			apperror.Respond(c, apperror.Internal("Failed to check Idempotency-Key"))
+			return
+		}
+
+		record := models.IdempotencyKey{
+			UserID:      ownerID,
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.RequestURI(),
+			Fingerprint: requestFingerprint(c, body),
+			Status:      models.IdempotencyStatusProcessing,
+			ExpiresAt:   now.Add(ttl),
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			apperror.Respond(c, apperror.Internal("Failed to check Idempotency-Key"))
+			return
+		}
+
+		if result.RowsAffected == 0 {
+			var existing models.IdempotencyKey
+			if err := db.Where("user_id = ? AND key = ?", ownerID, key).First(&existing).Error; err != nil {
+				apperror.Respond(c, apperror.Internal("Failed to check Idempotency-Key"))
+				return
+			}
+
+			switch {
+			case existing.Fingerprint != record.Fingerprint:
+				apperror.Respond(c, apperror.Unprocessable("Idempotency-Key was already used for a different request").
+					WithCode(apperror.CodeIdempotencyKeyReused))
+			case existing.Status != models.IdempotencyStatusCompleted:
+				apperror.Respond(c, apperror.Conflict("A request with this Idempotency-Key is still being processed").
+					WithCode(apperror.CodeIdempotencyKeyInFlight))
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.ResponseCode, existing.ContentType, existing.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		defer func() {
+			// A panic is answered with a 500 by the recovery middleware, so
+			// the key is released for the retry just like on a server error
+			panicked := recover()
+			finishIdempotencyKey(&record, recorder, panicked != nil)
+			if panicked != nil {
+				panic(panicked)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// finishIdempotencyKey stores the response for retries, or deletes the key
+// when the request failed so it can be retried
+func finishIdempotencyKey(record *models.IdempotencyKey, recorder *responseRecorder, failed bool) {
+	// Use a fresh context so the outcome is kept even if the client has gone
+	db := database.DB.WithContext(context.Background())
+	status := recorder.Status()
+	if !failed && status < http.StatusInternalServerError {
+		err := db.Model(record).Updates(map[string]interface{}{
+			"status":        models.IdempotencyStatusCompleted,
+			"response_code": status,
+			"content_type":  recorder.Header().Get("Content-Type"),
+			"response_body": recorder.body.Bytes(),
+		}).Error
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to store the response for Idempotency-Key %d: %v", record.ID, err)
+	}
+	// A key left processing would reject every retry until it expires
+	if err := db.Delete(record).Error; err != nil {
+		log.Printf("Failed to release Idempotency-Key %d: %v", record.ID, err)
+	}
+}
@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/ratelimit"
+)
+
+const defaultAccountBody = 64 << 10
+
+// accountMaxBody reads RATE_LIMIT_MAX_BODY_BYTES, the largest body ByAccount
+// reads into memory to find the account
+var accountMaxBody = sync.OnceValue(func() int64 {
+	if limit, err := strconv.ParseInt(os.Getenv("RATE_LIMIT_MAX_BODY_BYTES"), 10, 64); err == nil && limit > 0 {
+		return limit
+	}
+	return defaultAccountBody
+})
+
+// RateLimitKey names the bucket a request is counted against. An empty key
+// means the request is not counted.
+type RateLimitKey func(c *gin.Context) string
+
+// ByIP counts requests per client address. Behind a load balancer, list it
+// in TRUSTED_PROXIES or every request counts against the balancer.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser counts requests per authenticated user. It must run after CheckUser
+// or CheckAdmin.
+func ByUser(c *gin.Context) string {
+	userID, exists := c.Get("userid")
+	if !exists {
+		return ""
+	}
+	return "user:" + strconv.FormatUint(uint64(userID.(uint)), 10)
+}
+
+// ByAccount counts requests per account named by the "email" field of the
+// JSON body, so guesses against one account are limited whatever their
+// source. Bodies over RATE_LIMIT_MAX_BODY_BYTES are not counted; the handler
+// then fails to read them too.
+func ByAccount(c *gin.Context) string {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, accountMaxBody())
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var account struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &account) != nil || account.Email == "" {
+		return ""
+	}
+	return "account:" + strings.ToLower(strings.TrimSpace(account.Email))
+}
+
+// setRateLimitHeaders reports the bucket closest to its limit
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// RateLimit allows requests on a route up to limit, counted separately for
+// every key. The limit can be overridden with the RATE_LIMIT_<NAME>
+// environment variable, e.g. RATE_LIMIT_SIGNIN=5/1m. Requests over the limit
+// get 429 with a Retry-After header; if the store fails the request is let
+// through rather than taking the API down with it.
+func RateLimit(name string, limit ratelimit.Limit, keys ...RateLimitKey) gin.HandlerFunc {
+	limit = ratelimit.LimitFromEnv("RATE_LIMIT_"+strings.ToUpper(name), limit)
+
+	return func(c *gin.Context) {
+		var reported *ratelimit.Result
+		for _, key := range keys {
+			bucket := key(c)
+			if bucket == "" {
+				continue
+			}
+
+			result, err := ratelimit.Default.Take(c.Request.Context(), name+":"+bucket, limit)
+			if err != nil {
+				log.Printf("Rate limiter unavailable: %v", err)
+				continue
+			}
+
+			if !result.Allowed {
+				setRateLimitHeaders(c, result)
+				c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				apperror.Respond(c, apperror.TooManyRequests("Too many requests, please try again later"))
+				return
+			}
+			if reported == nil || result.Remaining < reported.Remaining {
+				reported = &result
+			}
+		}
+
+		if reported != nil {
+			setRateLimitHeaders(c, *reported)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create writes an empty up and down file for a new migration to dir,
+// numbered after the newest migration there, and returns their paths
+func Create(dir, name string) (up, down string, err error) {
+	if !migrationName.MatchString(name) {
+		return "", "", errors.New("migration names may only use lowercase letters, digits and underscores")
+	}
+
+	existing, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	var version uint = 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", version, name)
+	up = filepath.Join(dir, base+".up.sql")
+	down = filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(up, []byte("-- "+name+"\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- Undo "+name+"\n"), 0o644); err != nil {
+		os.Remove(up)
+		return "", "", err
+	}
+	return up, down, nil
+}
@@ -0,0 +1,29 @@
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "signing_keys";
+DROP TABLE IF EXISTS "oidc_login_states";
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "rate_limit_buckets";
+DROP TABLE IF EXISTS "idempotency_keys";
+DROP TABLE IF EXISTS "notification_preferences";
+DROP TABLE IF EXISTS "email_messages";
+DROP TABLE IF EXISTS "refunds";
+DROP TABLE IF EXISTS "webhook_deliveries";
+DROP TABLE IF EXISTS "webhook_endpoints";
+DROP TABLE IF EXISTS "outbox_events";
+DROP TABLE IF EXISTS "shipment_items";
+DROP TABLE IF EXISTS "shipments";
+DROP TABLE IF EXISTS "warehouse_stocks";
+DROP TABLE IF EXISTS "stock_movements";
+DROP TABLE IF EXISTS "warehouses";
+DROP TABLE IF EXISTS "import_jobs";
+DROP TABLE IF EXISTS "invoice_counters";
+DROP TABLE IF EXISTS "invoices";
+DROP TABLE IF EXISTS "audit_logs";
+DROP TABLE IF EXISTS "shipping_addresses";
+DROP TABLE IF EXISTS "order_items";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "cart_items";
+DROP TABLE IF EXISTS "carts";
+DROP TABLE IF EXISTS "products";
+DROP TABLE IF EXISTS "users";
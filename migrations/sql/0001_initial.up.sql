@@ -0,0 +1,508 @@
+-- The schema as it was when migrations replaced AutoMigrate. Everything is
+-- created only when missing, so databases set up by AutoMigrate take this
+-- migration as their starting point. Those may have been migrated by an
+-- older build, so tables that gained columns after they were first created
+-- also get those columns added.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "email" text NOT NULL,
+    "password" text NOT NULL,
+    "role" varchar(20) DEFAULT 'user',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "failed_logins" bigint NOT NULL DEFAULT 0,
+    "locked_until" timestamptz,
+    "two_factor_enabled" boolean NOT NULL DEFAULT false,
+    "totp_secret" varchar(64),
+    "totp_last_step" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_users_email" UNIQUE ("email")
+);
+ALTER TABLE "users"
+    ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "failed_logins" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "locked_until" timestamptz,
+    ADD COLUMN IF NOT EXISTS "two_factor_enabled" boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS "totp_secret" varchar(64),
+    ADD COLUMN IF NOT EXISTS "totp_last_step" bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "id" bigserial,
+    "sku" varchar(64) NOT NULL DEFAULT '',
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "price" numeric(10,2) NOT NULL,
+    "category" varchar(100),
+    "image_url" text,
+    "attributes" jsonb,
+    "stock" bigint NOT NULL DEFAULT 0,
+    "low_stock_threshold" bigint NOT NULL DEFAULT 5,
+    "is_available" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "products"
+    ADD COLUMN IF NOT EXISTS "sku" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "attributes" jsonb,
+    ADD COLUMN IF NOT EXISTS "low_stock_threshold" bigint NOT NULL DEFAULT 5,
+    ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_products_deleted_at" ON "products" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_products_sku" ON "products" ("sku") WHERE sku <> '';
+
+CREATE TABLE IF NOT EXISTS "carts" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_carts_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "uni_carts_user_id" UNIQUE ("user_id")
+);
+
+CREATE TABLE IF NOT EXISTS "cart_items" (
+    "id" bigserial,
+    "cart_id" bigint NOT NULL,
+    "product_id" bigint NOT NULL,
+    "quantity" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cart_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "fk_carts_items" FOREIGN KEY ("cart_id") REFERENCES "carts"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "total_amount" decimal NOT NULL,
+    "refunded_amount" numeric(10,2) NOT NULL DEFAULT 0,
+    "status" varchar(20) DEFAULT 'pending',
+    "contact_number" varchar(10) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+ALTER TABLE "orders"
+    ADD COLUMN IF NOT EXISTS "refunded_amount" numeric(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "order_items" (
+    "id" bigserial,
+    "order_id" bigint NOT NULL,
+    "product_id" bigint NOT NULL,
+    "product_name" varchar(255) NOT NULL DEFAULT '',
+    "sku" varchar(64) NOT NULL DEFAULT '',
+    "category" varchar(100),
+    "image_url" text,
+    "attributes" jsonb,
+    "unit_price" numeric(10,2) NOT NULL DEFAULT 0,
+    "quantity" bigint NOT NULL,
+    "price" decimal NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_items" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_order_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+ALTER TABLE "order_items"
+    ADD COLUMN IF NOT EXISTS "product_name" varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "sku" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "category" varchar(100),
+    ADD COLUMN IF NOT EXISTS "image_url" text,
+    ADD COLUMN IF NOT EXISTS "attributes" jsonb,
+    ADD COLUMN IF NOT EXISTS "unit_price" numeric(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "shipping_addresses" (
+    "id" bigserial,
+    "order_id" bigint NOT NULL,
+    "street" varchar(255) NOT NULL,
+    "city" varchar(100) NOT NULL,
+    "state" varchar(100) NOT NULL,
+    "zip_code" varchar(20) NOT NULL,
+    "country" varchar(100) NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_shipping_address" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+    "id" bigserial,
+    "actor_id" bigint NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "entity_type" varchar(50) NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "before" jsonb,
+    "after" jsonb,
+    "changes" jsonb,
+    "ip" varchar(45),
+    "request_id" varchar(64),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_request_id" ON "audit_logs" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity" ON "audit_logs" ("entity_type","entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_action" ON "audit_logs" ("action");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+
+CREATE TABLE IF NOT EXISTS "invoices" (
+    "id" bigserial,
+    "order_id" bigint NOT NULL,
+    "sequence" bigint NOT NULL,
+    "number" varchar(50) NOT NULL,
+    "issued_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_invoices_order" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invoices_number" ON "invoices" ("number");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invoices_sequence" ON "invoices" ("sequence");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invoices_order_id" ON "invoices" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "invoice_counters" (
+    "id" bigserial,
+    "last_sequence" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "import_jobs" (
+    "id" bigserial,
+    "format" varchar(10) NOT NULL,
+    "file_name" varchar(255),
+    "dry_run" boolean NOT NULL DEFAULT false,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "total_rows" bigint NOT NULL DEFAULT 0,
+    "created_count" bigint NOT NULL DEFAULT 0,
+    "updated_count" bigint NOT NULL DEFAULT 0,
+    "failed_count" bigint NOT NULL DEFAULT 0,
+    "errors" jsonb,
+    "message" text,
+    "created_by" bigint NOT NULL,
+    "created_at" timestamptz,
+    "started_at" timestamptz,
+    "finished_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "warehouses" (
+    "id" bigserial,
+    "code" varchar(20) NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "street" varchar(255),
+    "city" varchar(100),
+    "state" varchar(100),
+    "zip_code" varchar(20),
+    "country" varchar(100),
+    "priority" bigint NOT NULL DEFAULT 100,
+    "is_default" boolean NOT NULL DEFAULT false,
+    "is_active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_warehouses_code" ON "warehouses" ("code");
+
+CREATE TABLE IF NOT EXISTS "stock_movements" (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "warehouse_id" bigint,
+    "delta" bigint NOT NULL,
+    "balance_after" bigint NOT NULL,
+    "reason" varchar(20) NOT NULL,
+    "reference" varchar(100),
+    "note" text,
+    "actor_id" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_stock_movements_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_stock_movements_warehouse" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+ALTER TABLE "stock_movements"
+    ADD COLUMN IF NOT EXISTS "warehouse_id" bigint REFERENCES "warehouses"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_created_at" ON "stock_movements" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_reference" ON "stock_movements" ("reference");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_reason" ON "stock_movements" ("reason");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_warehouse_id" ON "stock_movements" ("warehouse_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_product_id" ON "stock_movements" ("product_id");
+
+CREATE TABLE IF NOT EXISTS "warehouse_stocks" (
+    "id" bigserial,
+    "warehouse_id" bigint NOT NULL,
+    "product_id" bigint NOT NULL,
+    "quantity" bigint NOT NULL DEFAULT 0,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_warehouse_stocks_warehouse" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "fk_warehouse_stocks_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_warehouse_stocks_product_id" ON "warehouse_stocks" ("product_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_warehouse_stocks_warehouse_product" ON "warehouse_stocks" ("warehouse_id","product_id");
+
+CREATE TABLE IF NOT EXISTS "shipments" (
+    "id" bigserial,
+    "order_id" bigint NOT NULL,
+    "warehouse_id" bigint NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "carrier" varchar(50),
+    "tracking_number" varchar(100),
+    "tracking_url" text,
+    "shipped_at" timestamptz,
+    "delivered_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_shipments" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_shipments_warehouse" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+ALTER TABLE "shipments"
+    ADD COLUMN IF NOT EXISTS "carrier" varchar(50),
+    ADD COLUMN IF NOT EXISTS "tracking_number" varchar(100),
+    ADD COLUMN IF NOT EXISTS "tracking_url" text,
+    ADD COLUMN IF NOT EXISTS "shipped_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "delivered_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_shipments_tracking_number" ON "shipments" ("tracking_number");
+CREATE INDEX IF NOT EXISTS "idx_shipments_warehouse_id" ON "shipments" ("warehouse_id");
+CREATE INDEX IF NOT EXISTS "idx_shipments_order_id" ON "shipments" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "shipment_items" (
+    "id" bigserial,
+    "shipment_id" bigint NOT NULL,
+    "order_item_id" bigint NOT NULL,
+    "product_id" bigint NOT NULL,
+    "quantity" bigint NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_shipment_items_order_item" FOREIGN KEY ("order_item_id") REFERENCES "order_items"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_shipments_items" FOREIGN KEY ("shipment_id") REFERENCES "shipments"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_shipment_items_order_item_id" ON "shipment_items" ("order_item_id");
+CREATE INDEX IF NOT EXISTS "idx_shipment_items_shipment_id" ON "shipment_items" ("shipment_id");
+
+CREATE TABLE IF NOT EXISTS "outbox_events" (
+    "id" bigserial,
+    "type" varchar(100) NOT NULL,
+    "payload" jsonb NOT NULL,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "last_error" text,
+    "created_at" timestamptz,
+    "processed_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_processed_at" ON "outbox_events" ("processed_at");
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_type" ON "outbox_events" ("type");
+
+CREATE TABLE IF NOT EXISTS "webhook_endpoints" (
+    "id" bigserial,
+    "url" text NOT NULL,
+    "description" varchar(255),
+    "secret" varchar(100) NOT NULL,
+    "events" jsonb NOT NULL,
+    "is_active" boolean NOT NULL DEFAULT true,
+    "created_by" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" bigserial,
+    "endpoint_id" bigint NOT NULL,
+    "event_id" bigint NOT NULL,
+    "event_type" varchar(100) NOT NULL,
+    "payload" jsonb NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL,
+    "last_status_code" bigint,
+    "last_error" text,
+    "delivered_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhook_deliveries_endpoint" FOREIGN KEY ("endpoint_id") REFERENCES "webhook_endpoints"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_next_attempt_at" ON "webhook_deliveries" ("next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_status" ON "webhook_deliveries" ("status");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_event_id" ON "webhook_deliveries" ("event_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_endpoint_id" ON "webhook_deliveries" ("endpoint_id");
+
+CREATE TABLE IF NOT EXISTS "refunds" (
+    "id" bigserial,
+    "order_id" bigint NOT NULL,
+    "amount" numeric(10,2) NOT NULL,
+    "reason" text,
+    "created_by" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_refunds_order" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_refunds_order_id" ON "refunds" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "email_messages" (
+    "id" bigserial,
+    "user_id" bigint,
+    "event_id" bigint,
+    "template" varchar(50) NOT NULL,
+    "to" varchar(255) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "html_body" text,
+    "text_body" text,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL,
+    "last_error" text,
+    "sent_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_email_messages_next_attempt_at" ON "email_messages" ("next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_email_messages_status" ON "email_messages" ("status");
+CREATE INDEX IF NOT EXISTS "idx_email_messages_event_id" ON "email_messages" ("event_id");
+CREATE INDEX IF NOT EXISTS "idx_email_messages_user_id" ON "email_messages" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "notification_preferences" (
+    "user_id" bigint,
+    "order_emails" boolean NOT NULL DEFAULT true,
+    "shipping_emails" boolean NOT NULL DEFAULT true,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("user_id"),
+    CONSTRAINT "fk_notification_preferences_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "key" varchar(255) NOT NULL,
+    "method" varchar(10) NOT NULL,
+    "path" text NOT NULL,
+    "fingerprint" char(64) NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'processing',
+    "response_code" bigint,
+    "content_type" varchar(255),
+    "response_body" bytea,
+    "created_at" timestamptz,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_keys_user_key" ON "idempotency_keys" ("user_id","key");
+
+CREATE TABLE IF NOT EXISTS "rate_limit_buckets" (
+    "key" varchar(255),
+    "tokens" decimal NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "expires_at" timestamptz,
+    PRIMARY KEY ("key")
+);
+CREATE INDEX IF NOT EXISTS "idx_rate_limit_buckets_expires_at" ON "rate_limit_buckets" ("expires_at");
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "code_hash" char(64) NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_recovery_codes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_recovery_codes_code_hash" ON "recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "user_identities" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" varchar(255),
+    "last_login_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_subject" ON "user_identities" ("provider","subject");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_user_id" ON "user_identities" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "oidc_login_states" (
+    "state" varchar(64),
+    "provider" varchar(50) NOT NULL,
+    "nonce" varchar(64) NOT NULL,
+    "verifier" varchar(128) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("state")
+);
+CREATE INDEX IF NOT EXISTS "idx_oidc_login_states_expires_at" ON "oidc_login_states" ("expires_at");
+
+CREATE TABLE IF NOT EXISTS "signing_keys" (
+    "id" bigserial,
+    "kid" varchar(64) NOT NULL,
+    "algorithm" varchar(10) NOT NULL,
+    "private_key" text NOT NULL,
+    "public_key" text NOT NULL,
+    "activates_at" timestamptz NOT NULL,
+    "retires_at" timestamptz NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_signing_keys_expires_at" ON "signing_keys" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_signing_keys_kid" ON "signing_keys" ("kid");
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" bigserial,
+    "name" varchar(100) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "key_hash" char(64) NOT NULL,
+    "scopes" jsonb NOT NULL,
+    "created_by" bigint NOT NULL,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "last_used_ip" varchar(45),
+    "revoked_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_api_keys_created_by" ON "api_keys" ("created_by");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_prefix" ON "api_keys" ("prefix");
+
+-- Order items created before product snapshots were recorded get one, using
+-- the price that was actually paid
+UPDATE order_items SET
+    product_name = products.name,
+    sku = products.sku,
+    category = products.category,
+    image_url = products.image_url,
+    attributes = products.attributes,
+    unit_price = CASE WHEN order_items.quantity > 0
+        THEN order_items.price / order_items.quantity
+        ELSE products.price END
+FROM products
+WHERE order_items.product_id = products.id AND order_items.product_name = '';
+
+-- Products that predate the stock ledger get an opening movement
+INSERT INTO stock_movements (product_id, delta, balance_after, reason, note, created_at)
+SELECT products.id, products.stock, products.stock, 'adjustment', 'opening balance', NOW()
+FROM products
+WHERE products.stock <> 0
+    AND NOT EXISTS (SELECT 1 FROM stock_movements WHERE stock_movements.product_id = products.id);
+
+-- The default warehouse, holding the stock recorded before warehouses existed
+WITH warehouse AS (
+    INSERT INTO warehouses (code, name, priority, is_default, is_active, created_at, updated_at)
+    SELECT 'MAIN', 'Main warehouse', 100, true, true, NOW(), NOW()
+    WHERE NOT EXISTS (SELECT 1 FROM warehouses)
+    RETURNING id
+)
+INSERT INTO warehouse_stocks (warehouse_id, product_id, quantity, updated_at)
+SELECT warehouse.id, products.id, products.stock, NOW()
+FROM warehouse, products
+WHERE products.stock <> 0;
+
+UPDATE stock_movements SET warehouse_id = (SELECT id FROM warehouses WHERE is_default ORDER BY id LIMIT 1)
+WHERE warehouse_id IS NULL;
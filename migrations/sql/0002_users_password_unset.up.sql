@@ -0,0 +1,3 @@
+-- Users who signed up through an identity provider may set a password
+-- without knowing the random one they were given
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "password_unset" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey lets a script call the API without signing in. It acts as the admin
+// who issued it, limited to its scopes. Only a hash of the key is stored;
+// the prefix identifies it in logs and listings.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16);not null;uniqueIndex"`
+	KeyHash    string     `json:"-" gorm:"type:char(64);not null"`
+	Scopes     StringList `json:"scopes" gorm:"type:jsonb;not null"`
+	CreatedBy  uint       `json:"created_by" gorm:"not null;index"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" gorm:"type:varchar(45)"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+func (APIKey) AuditEntity() string {
+	return "api_key"
+}
+
+// Active reports whether the key may be used at now
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Auditable is implemented by models whose admin mutations are recorded in the audit log
+type Auditable interface {
+	AuditEntity() string
+}
+
+var ErrAuditLogImmutable = errors.New("audit logs are append-only")
+
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorID    uint      `json:"actor_id" gorm:"not null;index"`
+	Action     string    `json:"action" gorm:"type:varchar(20);not null;index"`
+	EntityType string    `json:"entity_type" gorm:"type:varchar(50);not null;index:idx_audit_logs_entity"`
+	EntityID   uint      `json:"entity_id" gorm:"not null;index:idx_audit_logs_entity"`
+	Before     JSON      `json:"before" gorm:"type:jsonb"`
+	After      JSON      `json:"after" gorm:"type:jsonb"`
+	Changes    JSON      `json:"changes" gorm:"type:jsonb"`
+	IP         string    `json:"ip" gorm:"type:varchar(45)"`
+	RequestID  string    `json:"request_id" gorm:"type:varchar(64);index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
+
+// EmailMessage is a rendered email waiting in, or sent from, the mail queue
+type EmailMessage struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID        *uint      `json:"user_id" gorm:"index"`
+	EventID       *uint      `json:"event_id" gorm:"index"`
+	Template      string     `json:"template" gorm:"type:varchar(50);not null"`
+	To            string     `json:"to" gorm:"type:varchar(255);not null"`
+	Subject       string     `json:"subject" gorm:"type:varchar(255);not null"`
+	HTMLBody      string     `json:"-" gorm:"type:text"`
+	TextBody      string     `json:"-" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null;index"`
+	LastError     string     `json:"last_error" gorm:"type:text"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NotificationPreference holds the emails a user agreed to receive. Users
+// without a row get every email.
+type NotificationPreference struct {
+	UserID         uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	User           User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	OrderEmails    bool      `json:"order_emails" gorm:"not null;default:true"`
+	ShippingEmails bool      `json:"shipping_emails" gorm:"not null;default:true"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DefaultNotificationPreference is used for users who never saved theirs
+func DefaultNotificationPreference(userID uint) NotificationPreference {
+	return NotificationPreference{UserID: userID, OrderEmails: true, ShippingEmails: true}
+}
+
+func (EmailMessage) TableName() string {
+	return "email_messages"
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyKey remembers the response to a request sent with an
+// Idempotency-Key header so a retry of the same request gets the same
+// response instead of running again. Keys are scoped to the user.
+type IdempotencyKey struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Key          string    `json:"key" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Method       string    `json:"method" gorm:"type:varchar(10);not null"`
+	Path         string    `json:"path" gorm:"type:text;not null"`
+	Fingerprint  string    `json:"fingerprint" gorm:"type:char(64);not null"`
+	Status       string    `json:"status" gorm:"type:varchar(20);not null;default:'processing'"`
+	ResponseCode int       `json:"response_code"`
+	ContentType  string    `json:"content_type" gorm:"type:varchar(255)"`
+	ResponseBody []byte    `json:"-" gorm:"type:bytea"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
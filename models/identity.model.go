@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to their account at an OpenID Connect provider
+type UserIdentity struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Provider    string    `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_subject"`
+	Subject     string    `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_subject"`
+	Email       string    `json:"email" gorm:"type:varchar(255)"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCLoginState remembers a login sent to a provider until it comes back
+// to the callback. It is used once.
+type OIDCLoginState struct {
+	State     string    `json:"state" gorm:"primaryKey;type:varchar(64)"`
+	Provider  string    `json:"provider" gorm:"type:varchar(50);not null"`
+	Nonce     string    `json:"-" gorm:"type:varchar(64);not null"`
+	Verifier  string    `json:"-" gorm:"type:varchar(128);not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed"
+)
+
+// ImportJob tracks a bulk product import from upload to completion
+type ImportJob struct {
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Format       string     `json:"format" gorm:"type:varchar(10);not null"`
+	FileName     string     `json:"file_name" gorm:"type:varchar(255)"`
+	DryRun       bool       `json:"dry_run" gorm:"not null;default:false"`
+	Status       string     `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	TotalRows    int        `json:"total_rows" gorm:"not null;default:0"`
+	CreatedCount int        `json:"created_count" gorm:"not null;default:0"`
+	UpdatedCount int        `json:"updated_count" gorm:"not null;default:0"`
+	FailedCount  int        `json:"failed_count" gorm:"not null;default:0"`
+	Errors       JSON       `json:"errors" gorm:"type:jsonb"`
+	Message      string     `json:"message" gorm:"type:text"`
+	CreatedBy    uint       `json:"created_by" gorm:"not null"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+}
+
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Invoice records the number issued for an order. Numbers come from
+// InvoiceCounter and are never reused, even if an invoice row is removed.
+type Invoice struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID   uint      `json:"order_id" gorm:"not null;uniqueIndex"`
+	Order     Order     `json:"-" gorm:"foreignKey:OrderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Sequence  uint      `json:"sequence" gorm:"not null;uniqueIndex"`
+	Number    string    `json:"number" gorm:"type:varchar(50);not null;uniqueIndex"`
+	IssuedAt  time.Time `json:"issued_at" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// InvoiceCounter is a single-row table holding the last issued invoice sequence
+type InvoiceCounter struct {
+	ID           uint `gorm:"primaryKey"`
+	LastSequence uint `gorm:"not null;default:0"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+func (InvoiceCounter) TableName() string {
+	return "invoice_counters"
+}
@@ -5,11 +5,12 @@ import (
 )
 
 const (
-	OrderStatusPending    = "pending"
-	OrderStatusProcessing = "processing"
-	OrderStatusShipped    = "shipped"
-	OrderStatusDelivered  = "delivered"
-	OrderStatusCancelled  = "cancelled"
+	OrderStatusPending          = "pending"
+	OrderStatusProcessing       = "processing"
+	OrderStatusPartiallyShipped = "partially_shipped"
+	OrderStatusShipped          = "shipped"
+	OrderStatusDelivered        = "delivered"
+	OrderStatusCancelled        = "cancelled"
 )
 
 type Order struct {
@@ -18,18 +19,32 @@ type Order struct {
 	User            User            `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Items           []OrderItem     `json:"items" gorm:"foreignKey:OrderID;references:ID;constraint:OnDelete:CASCADE"`
 	TotalAmount     float64         `json:"total_amount" gorm:"not null" validate:"required,gt=0"`
-	Status          string          `json:"status" gorm:"type:varchar(20);default:'pending'" validate:"oneof=pending processing shipped delivered cancelled"`
+	RefundedAmount  float64         `json:"refunded_amount" gorm:"type:numeric(10,2);not null;default:0"`
+	Status          string          `json:"status" gorm:"type:varchar(20);default:'pending'" validate:"oneof=pending processing partially_shipped shipped delivered cancelled"`
 	ShippingAddress ShippingAddress `json:"shipping_address" gorm:"foreignKey:OrderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Shipments       []Shipment      `json:"shipments,omitempty" gorm:"foreignKey:OrderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	ContactNumber   string          `json:"contact_number" gorm:"type:varchar(10);not null" validate:"required"`
 	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
+// OrderItem keeps a snapshot of the product as it was at checkout, so later
+// edits to the catalog do not change how past orders look
 type OrderItem struct {
-	ID        uint    `json:"id" gorm:"primaryKey;autoIncrement"`
-	OrderID   uint    `json:"order_id" gorm:"not null"`
-	ProductID uint    `json:"product_id" gorm:"not null"`
-	Product   Product `json:"Product" gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
-	Quantity  int     `json:"quantity" gorm:"not null" validate:"required,min=1"`
-	Price     float64 `json:"price" gorm:"not null" validate:"required,gte=0"`
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID     uint       `json:"order_id" gorm:"not null"`
+	ProductID   uint       `json:"product_id" gorm:"not null"`
+	Product     Product    `json:"-" gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ProductName string     `json:"product_name" gorm:"type:varchar(255);not null;default:''"`
+	SKU         string     `json:"sku" gorm:"type:varchar(64);not null;default:''"`
+	Category    string     `json:"category" gorm:"type:varchar(100)"`
+	ImageURL    string     `json:"image_url" gorm:"type:text"`
+	Attributes  Attributes `json:"attributes" gorm:"type:jsonb"`
+	UnitPrice   float64    `json:"unit_price" gorm:"type:numeric(10,2);not null;default:0"`
+	Quantity    int        `json:"quantity" gorm:"not null" validate:"required,min=1"`
+	Price       float64    `json:"price" gorm:"not null" validate:"required,gte=0"`
+}
+
+func (Order) AuditEntity() string {
+	return "order"
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// it describes. A background dispatcher hands it to the event handlers, so an
+// event exists if and only if its change was committed.
+type OutboxEvent struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type        string     `json:"type" gorm:"type:varchar(100);not null;index"`
+	Payload     JSON       `json:"payload" gorm:"type:jsonb;not null"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	LastError   string     `json:"last_error" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	ProcessedAt *time.Time `json:"processed_at" gorm:"index"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
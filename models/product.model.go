@@ -1,18 +1,58 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// Attributes holds variant details of a product such as size or color
+type Attributes map[string]string
+
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(a)
+	return string(raw), err
+}
+
+func (a *Attributes) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for Attributes column: %T", value)
+	}
+	return json.Unmarshal(raw, a)
+}
+
 type Product struct {
-	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
-	Description string    `json:"description" gorm:"type:text"`
-	Price       float64   `json:"price" gorm:"type:numeric(10,2);not null"`
-	Category    string    `json:"category" gorm:"type:varchar(100)"`
-	ImageURL    string    `json:"image_url" gorm:"type:text"`
-	Stock       int       `json:"stock" gorm:"not null;default:0"`
-	IsAvailable bool      `json:"is_available" gorm:"default:true"`
-	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID                uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	SKU               string         `json:"sku" gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_products_sku,where:sku <> ''"`
+	Name              string         `json:"name" gorm:"type:varchar(255);not null"`
+	Description       string         `json:"description" gorm:"type:text"`
+	Price             float64        `json:"price" gorm:"type:numeric(10,2);not null"`
+	Category          string         `json:"category" gorm:"type:varchar(100)"`
+	ImageURL          string         `json:"image_url" gorm:"type:text"`
+	Attributes        Attributes     `json:"attributes" gorm:"type:jsonb"`
+	Stock             int            `json:"stock" gorm:"not null;default:0"`
+	LowStockThreshold int            `json:"low_stock_threshold" gorm:"not null;default:5"`
+	IsAvailable       bool           `json:"is_available" gorm:"default:true"`
+	CreatedAt         time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (Product) AuditEntity() string {
+	return "product"
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// RateLimitBucket is a token bucket shared by every instance of the API
+type RateLimitBucket struct {
+	Key       string    `json:"key" gorm:"primaryKey;type:varchar(255)"`
+	Tokens    float64   `json:"tokens" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+	// ExpiresAt is when the bucket has refilled and can be removed
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+}
+
+func (RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Refund is money given back to the customer for an order
+type Refund struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID   uint      `json:"order_id" gorm:"not null;index"`
+	Order     Order     `json:"-" gorm:"foreignKey:OrderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Amount    float64   `json:"amount" gorm:"type:numeric(10,2);not null"`
+	Reason    string    `json:"reason" gorm:"type:text"`
+	CreatedBy uint      `json:"created_by" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+func (Refund) AuditEntity() string {
+	return "refund"
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ShipmentStatusPending   = "pending"
+	ShipmentStatusShipped   = "shipped"
+	ShipmentStatusDelivered = "delivered"
+	ShipmentStatusCancelled = "cancelled"
+)
+
+// Shipment is the part of an order fulfilled from a single warehouse
+type Shipment struct {
+	ID             uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID        uint           `json:"order_id" gorm:"not null;index"`
+	WarehouseID    uint           `json:"warehouse_id" gorm:"not null;index"`
+	Warehouse      Warehouse      `json:"-" gorm:"foreignKey:WarehouseID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Status         string         `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Carrier        string         `json:"carrier" gorm:"type:varchar(50)"`
+	TrackingNumber string         `json:"tracking_number" gorm:"type:varchar(100);index"`
+	TrackingURL    string         `json:"tracking_url" gorm:"type:text"`
+	Items          []ShipmentItem `json:"items" gorm:"foreignKey:ShipmentID;references:ID;constraint:OnDelete:CASCADE"`
+	ShippedAt      *time.Time     `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ShipmentItem is the quantity of an order line packed in a shipment
+type ShipmentItem struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ShipmentID  uint      `json:"shipment_id" gorm:"not null;index"`
+	OrderItemID uint      `json:"order_item_id" gorm:"not null;index"`
+	OrderItem   OrderItem `json:"-" gorm:"foreignKey:OrderItemID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ProductID   uint      `json:"product_id" gorm:"not null"`
+	Quantity    int       `json:"quantity" gorm:"not null"`
+}
+
+func (Shipment) TableName() string {
+	return "shipments"
+}
+
+func (ShipmentItem) TableName() string {
+	return "shipment_items"
+}
+
+func (Shipment) AuditEntity() string {
+	return "shipment"
+}
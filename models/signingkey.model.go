@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is a key pair that signs access tokens. A key is published in
+// the JWKS from creation, signs tokens from ActivatesAt until RetiresAt, and
+// is still trusted for verification until ExpiresAt so tokens it signed
+// stay valid for their lifetime.
+type SigningKey struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Kid         string    `json:"kid" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Algorithm   string    `json:"algorithm" gorm:"type:varchar(10);not null"`
+	PrivateKey  string    `json:"-" gorm:"type:text;not null"`
+	PublicKey   string    `json:"public_key" gorm:"type:text;not null"`
+	ActivatesAt time.Time `json:"activates_at" gorm:"not null"`
+	RetiresAt   time.Time `json:"retires_at" gorm:"not null"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (SigningKey) TableName() string {
+	return "signing_keys"
+}
+
+func (SigningKey) AuditEntity() string {
+	return "signing_key"
+}
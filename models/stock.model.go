@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StockReasonSale       = "sale"
+	StockReasonCancel     = "cancel"
+	StockReasonReturn     = "return"
+	StockReasonAdjustment = "adjustment"
+	StockReasonImport     = "import"
+	StockReasonTransfer   = "transfer"
+)
+
+var ErrStockMovementImmutable = errors.New("stock movements are append-only")
+
+// StockMovement is an entry of the inventory ledger. Product.Stock always
+// equals the sum of Delta over a product's movements, and the stock held in
+// a warehouse the sum over that warehouse's movements.
+type StockMovement struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID    uint      `json:"product_id" gorm:"not null;index"`
+	Product      Product   `json:"-" gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	WarehouseID  *uint     `json:"warehouse_id" gorm:"index"`
+	Warehouse    Warehouse `json:"-" gorm:"foreignKey:WarehouseID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Delta        int       `json:"delta" gorm:"not null"`
+	BalanceAfter int       `json:"balance_after" gorm:"not null"`
+	Reason       string    `json:"reason" gorm:"type:varchar(20);not null;index"`
+	Reference    string    `json:"reference" gorm:"type:varchar(100);index"`
+	Note         string    `json:"note" gorm:"type:text"`
+	ActorID      *uint     `json:"actor_id"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+func (StockMovement) BeforeUpdate(tx *gorm.DB) error {
+	return ErrStockMovementImmutable
+}
+
+func (StockMovement) BeforeDelete(tx *gorm.DB) error {
+	return ErrStockMovementImmutable
+}
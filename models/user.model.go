@@ -4,16 +4,32 @@ import (
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uint      `gorm:"primarykey;autoIncrement" json:"id"`
-	Name      string    `gorm:"not null" json:"name" validate:"required,min=2,max=100"`
-	Email     string    `gorm:"unique;not null" json:"email" validate:"email,required"`
-	Password  string    `gorm:"not null" json:"password" validate:"required,min=6"`
-	Role      string    `gorm:"type:varchar(20);default:user" json:"role,omitempty" validate:"omitempty,oneof=user admin"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID        uint           `gorm:"primarykey;autoIncrement" json:"id"`
+	Name      string         `gorm:"not null" json:"name" validate:"required,min=2,max=100"`
+	Email     string         `gorm:"unique;not null" json:"email" validate:"email,required"`
+	Password  string         `gorm:"not null" json:"-" validate:"required,min=6"`
+	Role      string         `gorm:"type:varchar(20);default:user" json:"role,omitempty" validate:"omitempty,oneof=user admin"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Failed sign-ins since the last successful one, and the lockout they earned
+	FailedLogins int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil  *time.Time `json:"-"`
+
+	// TOTPSecret is set when enrollment starts and only used once it is
+	// confirmed and TwoFactorEnabled is set
+	TwoFactorEnabled bool   `gorm:"not null;default:false" json:"two_factor_enabled"`
+	TOTPSecret       string `gorm:"column:totp_secret;type:varchar(64)" json:"-"`
+	TOTPLastStep     int64  `gorm:"column:totp_last_step;not null;default:0" json:"-"`
+
+	// PasswordUnset marks users who signed up through an identity provider
+	// and have not chosen a password; Password is a random one until they do
+	PasswordUnset bool `gorm:"not null;default:false" json:"-"`
 }
 
 func (user *User) HashPassword() (string, error) {
@@ -25,3 +41,7 @@ func (user *User) ComparePassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	return err == nil
 }
+
+func (User) AuditEntity() string {
+	return "user"
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// Warehouse is a stock location orders are fulfilled from
+type Warehouse struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code      string    `json:"code" gorm:"type:varchar(20);not null;uniqueIndex" validate:"required,max=20"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null" validate:"required"`
+	Street    string    `json:"street" gorm:"type:varchar(255)"`
+	City      string    `json:"city" gorm:"type:varchar(100)"`
+	State     string    `json:"state" gorm:"type:varchar(100)"`
+	ZipCode   string    `json:"zip_code" gorm:"type:varchar(20)"`
+	Country   string    `json:"country" gorm:"type:varchar(100)"`
+	Priority  int       `json:"priority" gorm:"not null;default:100"`
+	IsDefault bool      `json:"is_default" gorm:"not null;default:false"`
+	IsActive  bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WarehouseStock is the quantity of a product held in a warehouse.
+// Product.Stock is the sum over all warehouses.
+type WarehouseStock struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	WarehouseID uint      `json:"warehouse_id" gorm:"not null;uniqueIndex:idx_warehouse_stocks_warehouse_product"`
+	Warehouse   Warehouse `json:"-" gorm:"foreignKey:WarehouseID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ProductID   uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_warehouse_stocks_warehouse_product;index"`
+	Product     Product   `json:"-" gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Quantity    int       `json:"quantity" gorm:"not null;default:0"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Warehouse) TableName() string {
+	return "warehouses"
+}
+
+func (WarehouseStock) TableName() string {
+	return "warehouse_stocks"
+}
+
+func (Warehouse) AuditEntity() string {
+	return "warehouse"
+}
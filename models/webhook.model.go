@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint is an external URL notified of the events it subscribes to.
+// The secret signs every payload and is only shown when it is generated.
+type WebhookEndpoint struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	URL         string     `json:"url" gorm:"type:text;not null"`
+	Description string     `json:"description" gorm:"type:varchar(255)"`
+	Secret      string     `json:"-" gorm:"type:varchar(100);not null"`
+	Events      StringList `json:"events" gorm:"type:jsonb;not null"`
+	IsActive    bool       `json:"is_active" gorm:"not null;default:true"`
+	CreatedBy   uint       `json:"created_by" gorm:"not null"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one endpoint
+type WebhookDelivery struct {
+	ID             uint            `json:"id" gorm:"primaryKey;autoIncrement"`
+	EndpointID     uint            `json:"endpoint_id" gorm:"not null;index"`
+	Endpoint       WebhookEndpoint `json:"-" gorm:"foreignKey:EndpointID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	EventID        uint            `json:"event_id" gorm:"not null;index"`
+	EventType      string          `json:"event_type" gorm:"type:varchar(100);not null"`
+	Payload        JSON            `json:"payload" gorm:"type:jsonb;not null"`
+	Status         string          `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts       int             `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" gorm:"not null;index"`
+	LastStatusCode int             `json:"last_status_code"`
+	LastError      string          `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (WebhookEndpoint) AuditEntity() string {
+	return "webhook_endpoint"
+}
@@ -0,0 +1,88 @@
+package oidc
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+var providers = map[string]*Provider{}
+
+// Register makes a provider available for sign-in under its name
+func Register(provider *Provider) {
+	providers[provider.Name] = provider
+}
+
+// Lookup returns the provider registered under name
+func Lookup(name string) (*Provider, bool) {
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// Names lists the registered providers
+func Names() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProvidersFromEnv registers the providers listed in OIDC_PROVIDERS, such as
+// "google,okta". Each is configured with OIDC_<NAME>_ISSUER,
+// OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET, OIDC_<NAME>_REDIRECT_URL
+// and optionally OIDC_<NAME>_SCOPES.
+func ProvidersFromEnv() error {
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := &Provider{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       defaultScopes,
+		}
+		if scopes := strings.Fields(strings.ReplaceAll(os.Getenv(prefix+"SCOPES"), ",", " ")); len(scopes) > 0 {
+			provider.Scopes = scopes
+		}
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			return fmt.Errorf("OIDC provider %q needs %sISSUER, %sCLIENT_ID and %sREDIRECT_URL", name, prefix, prefix, prefix)
+		}
+		Register(provider)
+	}
+	return nil
+}
+
+// MockFromEnv starts the mock provider when OIDC_MOCK_ENABLED is "true" and
+// registers it as "mock". baseURL is where this API is reachable and the
+// returned provider must be served under /mock-oidc. It returns nil when
+// the mock is disabled.
+func MockFromEnv(baseURL string) (*MockProvider, error) {
+	if os.Getenv("OIDC_MOCK_ENABLED") != "true" {
+		return nil, nil
+	}
+
+	mock, err := NewMockProvider(baseURL+"/mock-oidc", "mock-client", "mock-secret")
+	if err != nil {
+		return nil, err
+	}
+	Register(&Provider{
+		Name:         "mock",
+		Issuer:       mock.Issuer,
+		ClientID:     mock.ClientID,
+		ClientSecret: mock.ClientSecret,
+		RedirectURL:  baseURL + "/api/v1/auth/oidc/mock/callback",
+		Scopes:       defaultScopes,
+	})
+	return mock, nil
+}
@@ -0,0 +1,240 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	mockKeyID        = "mock-key"
+	mockCodeLifetime = time.Minute
+)
+
+// mockLoginForm asks for the identity to sign in as, keeping the
+// authorization request in hidden fields
+var mockLoginForm = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><body>
+<h1>Mock OpenID Provider</h1>
+<form method="get">
+{{range $name, $values := .}}{{if ne $name "email"}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}{{end}}<label>Email <input name="email" type="email" required></label>
+<label>Name <input name="name"></label>
+<label><input name="email_verified" type="checkbox" value="true" checked> Email verified</label>
+<button type="submit">Sign in</button>
+</form>
+</body></html>`))
+
+type mockGrant struct {
+	clientID      string
+	redirectURI   string
+	challenge     string
+	nonce         string
+	subject       string
+	email         string
+	emailVerified bool
+	name          string
+	expiresAt     time.Time
+}
+
+// MockProvider is a minimal OpenID provider for local development and
+// tests. It signs in whoever the authorization request names: pass email,
+// name, email_verified and sub as query parameters, or fill in the form it
+// shows when email is missing.
+type MockProvider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]mockGrant
+}
+
+func NewMockProvider(issuer, clientID, clientSecret string) (*MockProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &MockProvider{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		grants:       make(map[string]mockGrant),
+	}, nil
+}
+
+func (m *MockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		m.serveDiscovery(w)
+	case "/authorize":
+		m.serveAuthorize(w, r)
+	case "/token":
+		m.serveToken(w, r)
+	case "/jwks":
+		writeMockJSON(w, http.StatusOK, jsonWebKeySet{Keys: []jsonWebKey{publicJWK(mockKeyID, &m.key.PublicKey)}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeMockJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func mockError(w http.ResponseWriter, status int, code, description string) {
+	writeMockJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+func (m *MockProvider) serveDiscovery(w http.ResponseWriter) {
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                m.Issuer,
+		"authorization_endpoint":                m.Issuer + "/authorize",
+		"token_endpoint":                        m.Issuer + "/token",
+		"jwks_uri":                              m.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (m *MockProvider) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if query.Get("client_id") != m.ClientID || redirectURI == "" {
+		mockError(w, http.StatusBadRequest, "invalid_request", "unknown client_id or missing redirect_uri")
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		mockError(w, http.StatusBadRequest, "invalid_request", "only the code flow with S256 PKCE is supported")
+		return
+	}
+
+	email := query.Get("email")
+	if email == "" {
+		email = query.Get("login_hint")
+	}
+	if email == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		mockLoginForm.Execute(w, query)
+		return
+	}
+
+	subject := query.Get("sub")
+	if subject == "" {
+		subject = "mock|" + strings.ToLower(email)
+	}
+	code, err := RandomString()
+	if err != nil {
+		mockError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	m.mu.Lock()
+	m.grants[code] = mockGrant{
+		clientID:      m.ClientID,
+		redirectURI:   redirectURI,
+		challenge:     query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		subject:       subject,
+		email:         email,
+		emailVerified: query.Get("email_verified") != "false",
+		name:          query.Get("name"),
+		expiresAt:     time.Now().Add(mockCodeLifetime),
+	}
+	m.mu.Unlock()
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		mockError(w, http.StatusBadRequest, "invalid_request", "invalid redirect_uri")
+		return
+	}
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (m *MockProvider) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		mockError(w, http.StatusMethodNotAllowed, "invalid_request", "use POST")
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		mockError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != m.ClientID || clientSecret != m.ClientSecret {
+		mockError(w, http.StatusUnauthorized, "invalid_client", "unknown client")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	m.mu.Lock()
+	grant, found := m.grants[code]
+	delete(m.grants, code)
+	m.mu.Unlock()
+
+	switch {
+	case !found || time.Now().After(grant.expiresAt):
+		mockError(w, http.StatusBadRequest, "invalid_grant", "unknown or expired code")
+		return
+	case grant.redirectURI != r.PostForm.Get("redirect_uri"):
+		mockError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri mismatch")
+		return
+	case CodeChallenge(r.PostForm.Get("code_verifier")) != grant.challenge:
+		mockError(w, http.StatusBadRequest, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	now := time.Now()
+	claims := IDTokenClaims{
+		Email:         grant.email,
+		EmailVerified: grant.emailVerified,
+		Name:          grant.name,
+		Nonce:         grant.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.Issuer,
+			Subject:   grant.subject,
+			Audience:  jwt.ClaimStrings{grant.clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = mockKeyID
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		mockError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	accessToken, _ := RandomString()
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
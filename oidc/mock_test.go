@@ -0,0 +1,132 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testRedirectURL = "https://shop.example/api/v1/auth/oidc/mock/callback"
+
+// newTestProvider serves a MockProvider over HTTP and returns a Provider
+// registered against it
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+
+	var mock *MockProvider
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mock.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	mock, err := NewMockProvider(server.URL, "shop", "shop-secret")
+	if err != nil {
+		t.Fatalf("NewMockProvider: %v", err)
+	}
+	return &Provider{
+		Name:         "mock",
+		Issuer:       server.URL,
+		ClientID:     "shop",
+		ClientSecret: "shop-secret",
+		RedirectURL:  testRedirectURL,
+		Scopes:       defaultScopes,
+	}
+}
+
+// authorize sends the browser leg of a sign-in to the provider as identity
+// and returns the callback URL it redirects to
+func authorize(t *testing.T, provider *Provider, state, nonce, verifier string, identity url.Values) *url.URL {
+	t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	authURL += "&" + identity.Encode()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("GET authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned status %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	return callback
+}
+
+func TestMockProviderCodeFlowWithPKCE(t *testing.T) {
+	provider := newTestProvider(t)
+
+	callback := authorize(t, provider, "state-1", "nonce-1", "verifier-1", url.Values{
+		"email": {"ada@example.com"},
+		"name":  {"Ada"},
+	})
+	if !strings.HasPrefix(callback.String(), testRedirectURL+"?") {
+		t.Errorf("redirected to %s, want the redirect URL", callback)
+	}
+	if state := callback.Query().Get("state"); state != "state-1" {
+		t.Errorf("state is %q, want state-1", state)
+	}
+
+	code := callback.Query().Get("code")
+	claims, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "mock|ada@example.com" || claims.Email != "ada@example.com" || !claims.EmailVerified || claims.Name != "Ada" {
+		t.Errorf("claims are %+v, want a verified ada@example.com", claims)
+	}
+
+	// Codes can only be redeemed once
+	if _, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1"); err == nil {
+		t.Error("second Exchange of the same code succeeded")
+	}
+}
+
+func TestMockProviderRejectsWrongVerifier(t *testing.T) {
+	provider := newTestProvider(t)
+
+	callback := authorize(t, provider, "state-1", "nonce-1", "verifier-1", url.Values{"email": {"ada@example.com"}})
+	_, err := provider.Exchange(context.Background(), callback.Query().Get("code"), "someone-elses-verifier", "nonce-1")
+	if err == nil || !strings.Contains(err.Error(), "PKCE") {
+		t.Errorf("Exchange with the wrong verifier returned %v, want a PKCE failure", err)
+	}
+}
+
+func TestMockProviderRejectsWrongNonce(t *testing.T) {
+	provider := newTestProvider(t)
+
+	callback := authorize(t, provider, "state-1", "nonce-1", "verifier-1", url.Values{"email": {"ada@example.com"}})
+	_, err := provider.Exchange(context.Background(), callback.Query().Get("code"), "verifier-1", "nonce-2")
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("Exchange with the wrong nonce returned %v, want a nonce mismatch", err)
+	}
+}
+
+func TestMockProviderReportsUnverifiedEmail(t *testing.T) {
+	provider := newTestProvider(t)
+
+	callback := authorize(t, provider, "state-1", "nonce-1", "verifier-1", url.Values{
+		"email":          {"ada@example.com"},
+		"email_verified": {"false"},
+	})
+	claims, err := provider.Exchange(context.Background(), callback.Query().Get("code"), "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.EmailVerified {
+		t.Error("email is reported verified, want unverified")
+	}
+}
@@ -0,0 +1,252 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Options configure how a document is built
+type Options struct {
+	Info    Info
+	Servers []Server
+	// Error is the body of every error response
+	Error Body
+	// Types overrides the schema of types that marshal themselves
+	Types map[reflect.Type]*Schema
+	// Ignore lists path prefixes that are left out of the document, such as
+	// development-only routes
+	Ignore []string
+	// Scope returns the API key scope a route needs, if any
+	Scope func(method, path string) string
+}
+
+// CoverageError reports routes and documentation that do not match up
+type CoverageError struct {
+	// Undocumented routes are registered but have no operation
+	Undocumented []string
+	// Unknown operations document a route that is not registered
+	Unknown []string
+}
+
+func (e *CoverageError) Error() string {
+	var problems []string
+	if len(e.Undocumented) > 0 {
+		problems = append(problems, "routes missing from the OpenAPI document: "+strings.Join(e.Undocumented, ", "))
+	}
+	if len(e.Unknown) > 0 {
+		problems = append(problems, "documented routes that are not registered: "+strings.Join(e.Unknown, ", "))
+	}
+	return strings.Join(problems, "; ")
+}
+
+const (
+	bearerScheme = "bearerAuth"
+	cookieScheme = "cookieAuth"
+	apiKeyScheme = "apiKeyAuth"
+)
+
+var securitySchemes = map[string]*SecurityScheme{
+	bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Access token from sign-in"},
+	cookieScheme: {Type: "apiKey", In: "cookie", Name: "Authorization", Description: "Access token cookie set by sign-in"},
+	apiKeyScheme: {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "Admin API key, limited to its scopes"},
+}
+
+// Build documents every route the router serves. It reports a
+// *CoverageError when a route has no operation or an operation has no
+// route, so the document cannot silently fall behind the API; the document
+// of the routes that do match is returned with it.
+func Build(routes gin.RoutesInfo, operations []Operation, options Options) (*Document, error) {
+	documented := map[string]Operation{}
+	for _, operation := range operations {
+		documented[routeKey(operation.Method, operation.Path)] = operation
+	}
+
+	g := newGenerator(options.Types)
+	document := &Document{
+		OpenAPI: Version,
+		Info:    options.Info,
+		Servers: options.Servers,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:         g.schemas,
+			SecuritySchemes: securitySchemes,
+		},
+	}
+
+	// Response types are named first, so they keep plain names when a request
+	// binds another type of the same name
+	for _, operation := range operations {
+		if !operation.Response.empty() {
+			operation.Response.content(g)
+		}
+	}
+
+	coverage := &CoverageError{}
+	served := map[string]bool{}
+	tags := map[string]bool{}
+	for _, route := range routes {
+		if ignored(route.Path, options.Ignore) {
+			continue
+		}
+		key := routeKey(route.Method, route.Path)
+		served[key] = true
+
+		operation, ok := documented[key]
+		if !ok {
+			coverage.Undocumented = append(coverage.Undocumented, key)
+			continue
+		}
+
+		path := specPath(route.Path)
+		item, ok := document.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[path] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = buildOperation(g, route, operation, options)
+		if operation.Tag != "" {
+			tags[operation.Tag] = true
+		}
+	}
+
+	for _, operation := range operations {
+		if key := routeKey(operation.Method, operation.Path); !served[key] {
+			coverage.Unknown = append(coverage.Unknown, key)
+		}
+	}
+
+	for tag := range tags {
+		document.Tags = append(document.Tags, Tag{Name: tag})
+	}
+	sort.Slice(document.Tags, func(i, j int) bool { return document.Tags[i].Name < document.Tags[j].Name })
+
+	if len(coverage.Undocumented) > 0 || len(coverage.Unknown) > 0 {
+		sort.Strings(coverage.Undocumented)
+		sort.Strings(coverage.Unknown)
+		return document, coverage
+	}
+	return document, nil
+}
+
+func buildOperation(g *generator, route gin.RouteInfo, operation Operation, options Options) *OperationObject {
+	object := &OperationObject{
+		OperationID: operationID(route.Handler),
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Responses:   map[string]*Response{},
+	}
+	if operation.Tag != "" {
+		object.Tags = []string{operation.Tag}
+	}
+
+	for _, name := range pathParams(route.Path) {
+		object.Parameters = append(object.Parameters, ParameterObject{
+			Name: name, In: "path", Required: true, Schema: pathParamSchema(name),
+		})
+	}
+	for _, param := range operation.Query {
+		object.Parameters = append(object.Parameters, ParameterObject{
+			Name: param.Name, In: "query", Description: param.Description,
+			Required: param.Required, Schema: g.schemaOf(param.Type),
+		})
+	}
+	if operation.Idempotent {
+		object.Parameters = append(object.Parameters, ParameterObject{
+			Name: "Idempotency-Key", In: "header",
+			Description: "Makes the request safe to retry: a retry with the same key gets the first response back",
+			Schema:      &Schema{Type: "string", MaxLength: intPtr(255)},
+		})
+	}
+
+	if !operation.Request.empty() {
+		object.RequestBody = &RequestBody{Required: true, Content: operation.Request.content(g)}
+	}
+
+	status := operation.status()
+	response := &Response{Description: http.StatusText(status)}
+	if !operation.Response.empty() {
+		response.Content = operation.Response.content(g)
+	}
+	object.Responses[strconv.Itoa(status)] = response
+	if !options.Error.empty() {
+		object.Responses["default"] = &Response{Description: "Error", Content: options.Error.content(g)}
+	}
+
+	if operation.Access != Public {
+		object.Security = []SecurityRequirement{
+			{bearerScheme: {}}, {cookieScheme: {}}, {apiKeyScheme: {}},
+		}
+		if options.Scope != nil {
+			object.RequiredScope = options.Scope(route.Method, route.Path)
+		}
+	}
+	return object
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+func ignored(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// specPath turns gin's :name and *name segments into {name}
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			names = append(names, segment[1:])
+		}
+	}
+	return names
+}
+
+// pathParamSchema treats id, fooId and foo_id parameters as record IDs
+func pathParamSchema(name string) *Schema {
+	if name == "id" || strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "_id") {
+		minimum := 1.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &minimum}
+	}
+	return &Schema{Type: "string"}
+}
+
+// operationID derives an ID from the controller that handles the route,
+// e.g. "controllers.CreateOrder.func1" becomes "createOrder"
+func operationID(handler string) string {
+	if i := strings.LastIndex(handler, "/"); i >= 0 {
+		handler = handler[i+1:]
+	}
+	parts := strings.Split(handler, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	name := []rune(parts[1])
+	name[0] = unicode.ToLower(name[0])
+	return string(name)
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,9 @@
+package openapi
+
+import _ "embed"
+
+// DocsPage is a self-contained API reference that renders the document
+// served at /api/v1/openapi.json, so the docs work without reaching a CDN
+//
+//go:embed docs.html
+var DocsPage []byte
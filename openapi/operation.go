@@ -0,0 +1,161 @@
+package openapi
+
+import "net/http"
+
+// Access says who may call an operation
+type Access int
+
+const (
+	Public Access = iota
+	// Authenticated operations need a session token or an API key
+	Authenticated
+	// Admin operations need an admin session token or an API key
+	Admin
+)
+
+// Operation documents one route. Method and Path are written the way the
+// route is registered with gin, e.g. "/api/v1/orders/:id".
+type Operation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tag         string
+	Access      Access
+	// Idempotent operations accept an Idempotency-Key header
+	Idempotent bool
+	Query      []Param
+	Request    Body
+	// Status is the success status, 200 unless set
+	Status   int
+	Response Body
+}
+
+// Tagged groups operations under tag
+func Tagged(tag string, operations ...Operation) []Operation {
+	for i := range operations {
+		operations[i].Tag = tag
+	}
+	return operations
+}
+
+// Param is a query parameter. Type holds a value of the parameter's type.
+type Param struct {
+	Name        string
+	Description string
+	Type        interface{}
+	Required    bool
+}
+
+// Query describes an optional query parameter
+func Query(name string, typ interface{}, description string) Param {
+	return Param{Name: name, Description: description, Type: typ}
+}
+
+// PageQuery are the parameters of paginated lists
+var PageQuery = []Param{
+	Query("page", 0, "Page number, starting at 1"),
+	Query("limit", 0, "Items per page"),
+}
+
+// Pagination is the position of a page within a list
+type Pagination struct {
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+	Total int64 `json:"total"`
+}
+
+// Body describes a request or response body. The zero Body means there is
+// none.
+type Body struct {
+	contentType string
+	value       interface{}
+	// envelope bodies are the API's {success, message} object with fields
+	// added beside them
+	envelope bool
+	fields   Object
+	// alternatives are other content types accepted for a request
+	alternatives []Body
+}
+
+const jsonContentType = "application/json"
+
+// JSON is a JSON body shaped like value
+func JSON(value interface{}) Body {
+	return Body{contentType: jsonContentType, value: value}
+}
+
+// Content is a body of the given content type shaped like value; use Binary
+// for files and Text for plain text
+func Content(contentType string, value interface{}) Body {
+	return Body{contentType: contentType, value: value}
+}
+
+// Message is the {success, message} response
+func Message() Body {
+	return Body{contentType: jsonContentType, envelope: true, fields: Object{}}
+}
+
+// Data is a response carrying value under "data"
+func Data(value interface{}) Body {
+	return Message().With(Object{"data": value})
+}
+
+// Page is a response carrying one page of a list under "data"
+func Page(value interface{}) Body {
+	return Data(value).With(Object{"pagination": Pagination{}})
+}
+
+// Fields is a response with the given fields beside success and message
+func Fields(fields Object) Body {
+	return Message().With(fields)
+}
+
+// With adds fields to an envelope body
+func (b Body) With(fields Object) Body {
+	merged := Object{}
+	for name, value := range b.fields {
+		merged[name] = value
+	}
+	for name, value := range fields {
+		merged[name] = value
+	}
+	b.fields = merged
+	return b
+}
+
+// Or accepts other as well, for requests that take more than one format
+func (b Body) Or(other Body) Body {
+	b.alternatives = append(append([]Body{}, b.alternatives...), other)
+	return b
+}
+
+func (b Body) empty() bool {
+	return b.contentType == ""
+}
+
+func (b Body) content(g *generator) map[string]MediaType {
+	content := map[string]MediaType{}
+	for _, body := range append([]Body{b}, b.alternatives...) {
+		content[body.contentType] = MediaType{Schema: body.schema(g)}
+	}
+	return content
+}
+
+func (b Body) schema(g *generator) *Schema {
+	if !b.envelope {
+		return g.schemaOf(b.value)
+	}
+	fields := Object{"success": true, "message": ""}
+	for name, value := range b.fields {
+		fields[name] = value
+	}
+	return g.object(fields)
+}
+
+func (o Operation) status() int {
+	if o.Status == 0 {
+		return http.StatusOK
+	}
+	return o.Status
+}
@@ -0,0 +1,284 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Object describes an ad-hoc JSON object, such as a gin.H response, by
+// example: every key maps to a value of the type the field holds
+type Object map[string]interface{}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Binary is the schema of a file download or upload
+var Binary = &Schema{Type: "string", Format: "binary"}
+
+// Text is the schema of a plain text body
+var Text = &Schema{Type: "string"}
+
+// generator turns Go types into schemas. Named struct types become
+// components and are referenced from everywhere they are used.
+type generator struct {
+	schemas   map[string]*Schema
+	names     map[reflect.Type]string
+	overrides map[reflect.Type]*Schema
+}
+
+func newGenerator(overrides map[reflect.Type]*Schema) *generator {
+	return &generator{
+		schemas:   map[string]*Schema{},
+		names:     map[reflect.Type]string{},
+		overrides: overrides,
+	}
+}
+
+// schemaOf describes a value the way the catalog passes it: a *Schema is
+// used as is, an Object is described field by field and anything else by
+// its type
+func (g *generator) schemaOf(value interface{}) *Schema {
+	switch v := value.(type) {
+	case nil:
+		return &Schema{}
+	case *Schema:
+		return v
+	case Object:
+		return g.object(v)
+	}
+	return g.schemaFor(reflect.TypeOf(value))
+}
+
+func (g *generator) object(fields Object) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for name, value := range fields {
+		schema.Properties[name] = g.schemaOf(value)
+		schema.Required = append(schema.Required, name)
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	if override, ok := g.overrides[t]; ok {
+		copied := *override
+		return &copied
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schemaFor(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &minimum}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.component(t)
+	}
+	// Interfaces and anything else can hold any JSON value
+	return &Schema{}
+}
+
+// component registers a named struct once and returns a reference to it
+func (g *generator) component(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		// Registered before it is filled in so recursive types terminate
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName is the type's name, qualified by its package when another
+// package already has a type of that name
+func (g *generator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+	pkg := []rune(path.Base(t.PkgPath()))
+	pkg[0] = unicode.ToUpper(pkg[0])
+	return string(pkg) + name
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, t)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (g *generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a JSON name have their fields promoted
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schemaFor(field.Type)
+		required := applyRules(property, field.Tag.Get("binding"))
+		required = applyRules(property, field.Tag.Get("validate")) || required
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// applyRules copies the constraints of a validator tag onto the schema and
+// reports whether the field is required. Rules after "dive" apply to the
+// elements and are left out.
+func applyRules(schema *Schema, tag string) bool {
+	required := false
+	if tag == "" || tag == "-" {
+		return false
+	}
+	// Constraints cannot sit beside a reference in OpenAPI 3.0
+	constrain := schema.Ref == ""
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			break
+		}
+		if name == "required" {
+			required = true
+			continue
+		}
+		if !constrain {
+			continue
+		}
+
+		switch name {
+		case "email":
+			schema.Format = "email"
+		case "url", "http_url":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "oneof":
+			schema.Enum = nil
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema, value))
+			}
+		case "min", "gte":
+			setBound(schema, param, true, false)
+		case "gt":
+			setBound(schema, param, true, true)
+		case "max", "lte":
+			setBound(schema, param, false, false)
+		case "lt":
+			setBound(schema, param, false, true)
+		case "len":
+			setBound(schema, param, true, false)
+			setBound(schema, param, false, false)
+		}
+	}
+	return required
+}
+
+func enumValue(schema *Schema, value string) interface{} {
+	switch schema.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// setBound applies a min or max rule, which limits the length of strings,
+// the size of arrays and the value of numbers
+func setBound(schema *Schema, param string, lower, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "string", "array":
+		length := int(n)
+		if exclusive {
+			if lower {
+				length++
+			} else {
+				length--
+			}
+		}
+		switch {
+		case schema.Type == "string" && lower:
+			schema.MinLength = &length
+		case schema.Type == "string":
+			schema.MaxLength = &length
+		case lower:
+			schema.MinItems = &length
+		default:
+			schema.MaxItems = &length
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum, schema.ExclusiveMinimum = &n, exclusive
+		} else {
+			schema.Maximum, schema.ExclusiveMaximum = &n, exclusive
+		}
+	}
+}
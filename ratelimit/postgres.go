@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every
+// instance sees the same limits
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	var result Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		bucket := models.RateLimitBucket{Key: key, Tokens: float64(limit.Burst), UpdatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&bucket).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&bucket).Error; err != nil {
+			return err
+		}
+
+		elapsed := max(now.Sub(bucket.UpdatedAt), 0)
+		bucket.Tokens, result = take(bucket.Tokens, elapsed, limit)
+		return tx.Model(&bucket).Updates(map[string]interface{}{
+			"tokens":     bucket.Tokens,
+			"updated_at": now,
+			"expires_at": now.Add(result.Reset),
+		}).Error
+	})
+	return result, err
+}
@@ -0,0 +1,108 @@
+// Package ratelimit implements token bucket rate limiting with pluggable
+// stores, so limits can be kept in memory or shared between instances.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Limit is a token bucket that holds up to Burst tokens and refills at Rate
+// tokens per second. Every request takes one token.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Per returns a limit of count requests per period, all of which may be
+// spent at once
+func Per(count int, period time.Duration) Limit {
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: count}
+}
+
+// ParseLimit reads a limit written as "<count>/<period>", such as "5/1m"
+func ParseLimit(value string) (Limit, error) {
+	countText, periodText, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q must look like 5/1m", value)
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count < 1 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid count", value)
+	}
+	period, err := time.ParseDuration(periodText)
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid period", value)
+	}
+	return Per(count, period), nil
+}
+
+// LimitFromEnv reads the limit in the named environment variable, falling
+// back to fallback when it is unset or invalid
+func LimitFromEnv(name string, fallback Limit) Limit {
+	if limit, err := ParseLimit(os.Getenv(name)); err == nil {
+		return limit
+	}
+	return fallback
+}
+
+// Result is the state of a bucket after a request tried to take a token
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available, zero when allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps the buckets
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Default is the store used by the rate limiting middleware
+var Default Store = NewMemoryStore()
+
+// StoreFromEnv builds the store named by RATE_LIMIT_STORE, "memory" (the
+// default) or "postgres" to share limits between instances
+func StoreFromEnv(db *gorm.DB) (Store, error) {
+	switch store := os.Getenv("RATE_LIMIT_STORE"); store {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		return NewPostgresStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q", store)
+	}
+}
+
+// take refills a bucket holding tokens that was last updated elapsed ago and
+// takes one token from it when it can
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	burst := float64(limit.Burst)
+	tokens = math.Min(burst, tokens+elapsed.Seconds()*limit.Rate)
+
+	result := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = secondsToDuration((burst - tokens) / limit.Rate)
+	return tokens, result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository stores the API keys admins issue
+type APIKeyRepository interface {
+	// List returns keys newest first; with activeAt set, only the keys
+	// usable at that time
+	List(ctx context.Context, activeAt *time.Time) ([]models.APIKey, error)
+	FindByID(ctx context.Context, id uint) (models.APIKey, error)
+	Create(ctx context.Context, apiKey *models.APIKey) error
+	Revoke(ctx context.Context, apiKey *models.APIKey, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *apiKeyRepository) List(ctx context.Context, activeAt *time.Time) ([]models.APIKey, error) {
+	query := r.db.WithContext(ctx).Order("id DESC")
+	if activeAt != nil {
+		query = query.Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", *activeAt)
+	}
+	var keys []models.APIKey
+	err := query.Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) FindByID(ctx context.Context, id uint) (models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.WithContext(ctx).First(&apiKey, id).Error
+	return apiKey, translate(err)
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(apiKey).Error
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, apiKey *models.APIKey, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(apiKey).Update("revoked_at", at).Error; err != nil {
+		return err
+	}
+	apiKey.RevokedAt = &at
+	return nil
+}
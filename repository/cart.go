@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// CartRepository stores carts and their items
+type CartRepository interface {
+	// FindByUser returns the cart of a user with its items and their products
+	FindByUser(ctx context.Context, userID uint) (models.Cart, error)
+	Create(ctx context.Context, cart *models.Cart) error
+	// FindItem returns a cart item with its product
+	FindItem(ctx context.Context, id uint) (models.CartItem, error)
+	FindItemByProduct(ctx context.Context, cartID, productID uint) (models.CartItem, error)
+	SaveItem(ctx context.Context, item *models.CartItem) error
+	DeleteItem(ctx context.Context, item *models.CartItem) error
+	// DeleteItemsByProduct takes a product out of every cart
+	DeleteItemsByProduct(ctx context.Context, productID uint) error
+}
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+func (r *cartRepository) FindByUser(ctx context.Context, userID uint) (models.Cart, error) {
+	var cart models.Cart
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Preload("Items.Product").First(&cart).Error
+	return cart, translate(err)
+}
+
+func (r *cartRepository) Create(ctx context.Context, cart *models.Cart) error {
+	return r.db.WithContext(ctx).Create(cart).Error
+}
+
+func (r *cartRepository) FindItem(ctx context.Context, id uint) (models.CartItem, error) {
+	var item models.CartItem
+	err := r.db.WithContext(ctx).Preload("Product").First(&item, id).Error
+	return item, translate(err)
+}
+
+func (r *cartRepository) FindItemByProduct(ctx context.Context, cartID, productID uint) (models.CartItem, error) {
+	var item models.CartItem
+	err := r.db.WithContext(ctx).Where("cart_id = ? AND product_id = ?", cartID, productID).First(&item).Error
+	return item, translate(err)
+}
+
+func (r *cartRepository) SaveItem(ctx context.Context, item *models.CartItem) error {
+	return r.db.WithContext(ctx).Omit("Product").Save(item).Error
+}
+
+func (r *cartRepository) DeleteItem(ctx context.Context, item *models.CartItem) error {
+	return r.db.WithContext(ctx).Delete(item).Error
+}
+
+func (r *cartRepository) DeleteItemsByProduct(ctx context.Context, productID uint) error {
+	return r.db.WithContext(ctx).Where("product_id = ?", productID).Delete(&models.CartItem{}).Error
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// EventRepository reads the event outbox. Events are written with
+// Store.PublishEvent.
+type EventRepository interface {
+	// ListSince returns up to limit events of types published after the
+	// event with id since, oldest first
+	ListSince(ctx context.Context, since uint, types []string, limit int) ([]models.OutboxEvent, error)
+}
+
+type eventRepository struct {
+	db *gorm.DB
+}
+
+func (r *eventRepository) ListSince(ctx context.Context, since uint, types []string, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("id > ? AND type IN ?", since, types).
+		Order("id").Limit(limit).
+		Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailFilter narrows the mail queue. Zero fields match every email.
+type EmailFilter struct {
+	Status   string
+	Template string
+	UserID   uint
+}
+
+// NotificationRepository stores the email preferences of users and the
+// outgoing mail queue
+type NotificationRepository interface {
+	// FindPreference returns the preference of a user, or the default one
+	// when they never saved theirs
+	FindPreference(ctx context.Context, userID uint) (models.NotificationPreference, error)
+	SavePreference(ctx context.Context, preference *models.NotificationPreference) error
+
+	// ListEmails returns a page of the mail queue, newest first, and the
+	// number of emails matching filter
+	ListEmails(ctx context.Context, filter EmailFilter, limit, offset int) ([]models.EmailMessage, int64, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func (r *notificationRepository) FindPreference(ctx context.Context, userID uint) (models.NotificationPreference, error) {
+	return helpers.NotificationPreferenceFor(r.db.WithContext(ctx), userID)
+}
+
+func (r *notificationRepository) SavePreference(ctx context.Context, preference *models.NotificationPreference) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"order_emails", "shipping_emails", "updated_at"}),
+	}).Create(preference).Error
+}
+
+func (r *notificationRepository) ListEmails(ctx context.Context, filter EmailFilter, limit, offset int) ([]models.EmailMessage, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.EmailMessage{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Template != "" {
+		query = query.Where("template = ?", filter.Template)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var emails []models.EmailMessage
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&emails).Error
+	return emails, total, err
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderRepository stores orders and fulfils them from the warehouses. Orders
+// are returned with their shipping address, items and shipments.
+type OrderRepository interface {
+	// Create stores an order together with its shipping address and items
+	Create(ctx context.Context, order *models.Order) error
+	// SetStatus changes only the status of an order
+	SetStatus(ctx context.Context, id uint, status string) error
+	FindByID(ctx context.Context, id uint) (models.Order, error)
+	// FindForUpdate returns an order locked until the transaction ends
+	FindForUpdate(ctx context.Context, id uint) (models.Order, error)
+	// FindForUser returns an order only if it belongs to the user
+	FindForUser(ctx context.Context, userID, id uint) (models.Order, error)
+	List(ctx context.Context) ([]models.Order, error)
+	ListByUser(ctx context.Context, userID uint) ([]models.Order, error)
+	ListItems(ctx context.Context) ([]models.OrderItem, error)
+	ListItemsByProduct(ctx context.Context, productID uint) ([]models.OrderItem, error)
+	// CountShipped counts the shipments of an order that left the warehouse
+	CountShipped(ctx context.Context, orderID uint) (int64, error)
+
+	// Allocate picks the warehouses that ship each line. It fails with
+	// helpers.ErrInsufficientStock when they cannot cover the order.
+	Allocate(ctx context.Context, address models.ShippingAddress, lines []helpers.AllocationLine, strategy string) ([]helpers.Allocation, error)
+	// CreateShipments records the allocated shipments and takes their stock
+	CreateShipments(ctx context.Context, orderID uint, allocations []helpers.Allocation) error
+	// Restock returns the unshipped items of an order and cancels their
+	// shipments
+	Restock(ctx context.Context, orderID, actorID uint) error
+	// EnsureShipments gives an order placed before shipments existed a
+	// single pending shipment from the default warehouse
+	EnsureShipments(ctx context.Context, order *models.Order) error
+	ListShipments(ctx context.Context, orderID uint) ([]models.Shipment, error)
+	// FindShipment returns a shipment only if it belongs to the order
+	FindShipment(ctx context.Context, orderID, id uint) (models.Shipment, error)
+	CreateShipment(ctx context.Context, shipment *models.Shipment) error
+	// UpdateShipment stores the status, carrier and timestamps of a shipment
+	UpdateShipment(ctx context.Context, shipment *models.Shipment) error
+	// SetShipmentItemQuantity changes the quantity of a shipment line,
+	// removing the line at zero
+	SetShipmentItemQuantity(ctx context.Context, id uint, quantity int) error
+
+	ListRefunds(ctx context.Context, orderID uint) ([]models.Refund, error)
+	// AddRefund records a refund and the new refunded total of its order
+	AddRefund(ctx context.Context, refund *models.Refund, refundedAmount float64) error
+
+	// FindForDocument returns an order with what is printed on its invoice
+	// and packing slip, including its customer even if deleted
+	FindForDocument(ctx context.Context, id uint) (models.Order, error)
+	// FindOrIssueInvoice returns the invoice of an order, issuing the next
+	// number from the counter on first use
+	FindOrIssueInvoice(ctx context.Context, orderID uint, number func(sequence uint) string) (models.Invoice, error)
+}
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+func (r *orderRepository) withDetails(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Preload("ShippingAddress").Preload("Items").Preload("Shipments.Items")
+}
+
+func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Create(order).Error
+}
+
+func (r *orderRepository) SetStatus(ctx context.Context, id uint, status string) error {
+	return r.db.WithContext(ctx).Model(&models.Order{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *orderRepository) FindByID(ctx context.Context, id uint) (models.Order, error) {
+	var order models.Order
+	err := r.withDetails(ctx).First(&order, id).Error
+	return order, translate(err)
+}
+
+func (r *orderRepository) FindForUpdate(ctx context.Context, id uint) (models.Order, error) {
+	var order models.Order
+	err := r.withDetails(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error
+	return order, translate(err)
+}
+
+func (r *orderRepository) FindForUser(ctx context.Context, userID, id uint) (models.Order, error) {
+	var order models.Order
+	err := r.withDetails(ctx).Where("user_id = ?", userID).First(&order, id).Error
+	return order, translate(err)
+}
+
+func (r *orderRepository) List(ctx context.Context) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.withDetails(ctx).Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepository) ListByUser(ctx context.Context, userID uint) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.withDetails(ctx).Where("user_id = ?", userID).Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepository) ListItems(ctx context.Context) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	err := r.db.WithContext(ctx).Find(&items).Error
+	return items, err
+}
+
+func (r *orderRepository) ListItemsByProduct(ctx context.Context, productID uint) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Find(&items).Error
+	return items, err
+}
+
+func (r *orderRepository) CountShipped(ctx context.Context, orderID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Shipment{}).
+		Where("order_id = ? AND status IN ?", orderID, []string{models.ShipmentStatusShipped, models.ShipmentStatusDelivered}).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *orderRepository) Allocate(ctx context.Context, address models.ShippingAddress, lines []helpers.AllocationLine, strategy string) ([]helpers.Allocation, error) {
+	return helpers.AllocateOrder(r.db.WithContext(ctx), address, lines, strategy)
+}
+
+func (r *orderRepository) CreateShipments(ctx context.Context, orderID uint, allocations []helpers.Allocation) error {
+	return helpers.CreateShipments(r.db.WithContext(ctx), orderID, allocations)
+}
+
+func (r *orderRepository) Restock(ctx context.Context, orderID, actorID uint) error {
+	return helpers.RestockOrder(r.db.WithContext(ctx), orderID, actorID)
+}
+
+func (r *orderRepository) EnsureShipments(ctx context.Context, order *models.Order) error {
+	return helpers.EnsureOrderShipments(r.db.WithContext(ctx), order)
+}
+
+func (r *orderRepository) ListShipments(ctx context.Context, orderID uint) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Preload("Items").Order("id").Find(&shipments).Error
+	return shipments, err
+}
+
+func (r *orderRepository) FindShipment(ctx context.Context, orderID, id uint) (models.Shipment, error) {
+	var shipment models.Shipment
+	err := r.db.WithContext(ctx).Where("id = ? AND order_id = ?", id, orderID).Preload("Items").First(&shipment).Error
+	return shipment, translate(err)
+}
+
+func (r *orderRepository) CreateShipment(ctx context.Context, shipment *models.Shipment) error {
+	return r.db.WithContext(ctx).Create(shipment).Error
+}
+
+func (r *orderRepository) UpdateShipment(ctx context.Context, shipment *models.Shipment) error {
+	return r.db.WithContext(ctx).Model(&models.Shipment{ID: shipment.ID}).Updates(map[string]interface{}{
+		"status":          shipment.Status,
+		"carrier":         shipment.Carrier,
+		"tracking_number": shipment.TrackingNumber,
+		"tracking_url":    shipment.TrackingURL,
+		"shipped_at":      shipment.ShippedAt,
+		"delivered_at":    shipment.DeliveredAt,
+	}).Error
+}
+
+func (r *orderRepository) SetShipmentItemQuantity(ctx context.Context, id uint, quantity int) error {
+	db := r.db.WithContext(ctx)
+	if quantity == 0 {
+		return db.Delete(&models.ShipmentItem{}, id).Error
+	}
+	return db.Model(&models.ShipmentItem{}).Where("id = ?", id).Update("quantity", quantity).Error
+}
+
+func (r *orderRepository) ListRefunds(ctx context.Context, orderID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *orderRepository) AddRefund(ctx context.Context, refund *models.Refund, refundedAmount float64) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Create(refund).Error; err != nil {
+		return err
+	}
+	return db.Model(&models.Order{}).Where("id = ?", refund.OrderID).Update("refunded_amount", refundedAmount).Error
+}
+
+func (r *orderRepository) FindForDocument(ctx context.Context, id uint) (models.Order, error) {
+	var order models.Order
+	err := r.db.WithContext(ctx).
+		Preload("ShippingAddress").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&order, id).Error
+	return order, translate(err)
+}
+
+// FindOrIssueInvoice locks the counter row for the whole transaction, so
+// numbers are sequential and never handed out twice
+func (r *orderRepository) FindOrIssueInvoice(ctx context.Context, orderID uint, number func(sequence uint) string) (models.Invoice, error) {
+	var invoice models.Invoice
+	db := r.db.WithContext(ctx)
+	err := db.Where("order_id = ?", orderID).First(&invoice).Error
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return invoice, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.InvoiceCounter{ID: 1}).Error; err != nil {
+			return err
+		}
+
+		var counter models.InvoiceCounter
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&counter, 1).Error; err != nil {
+			return err
+		}
+
+		// Another request may have issued the invoice while we waited for the lock
+		err := tx.Where("order_id = ?", orderID).First(&invoice).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		counter.LastSequence++
+		if err := tx.Save(&counter).Error; err != nil {
+			return err
+		}
+
+		invoice = models.Invoice{
+			OrderID:  orderID,
+			Sequence: counter.LastSequence,
+			Number:   number(counter.LastSequence),
+			IssuedAt: time.Now(),
+		}
+		return tx.Create(&invoice).Error
+	})
+	return invoice, err
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// ProductRepository stores the catalog and changes stock through the
+// inventory ledger
+type ProductRepository interface {
+	FindByID(ctx context.Context, id uint) (models.Product, error)
+	// FindDeleted returns a soft-deleted product
+	FindDeleted(ctx context.Context, id uint) (models.Product, error)
+	List(ctx context.Context) ([]models.Product, error)
+	ListDeleted(ctx context.Context) ([]models.Product, error)
+	Create(ctx context.Context, product *models.Product) error
+	// Update writes the non-zero fields of changes to product
+	Update(ctx context.Context, product *models.Product, changes models.Product) error
+	Delete(ctx context.Context, product *models.Product) error
+	Restore(ctx context.Context, product *models.Product) error
+
+	// AdjustStock records a stock movement and returns it
+	AdjustStock(ctx context.Context, change helpers.StockChange) (*models.StockMovement, error)
+	// SetStock records the movement that brings a product's stock to level
+	SetStock(ctx context.Context, productID uint, level int, reason, note string, actorID uint) error
+	// ListMovements returns a page of a product's ledger, newest first, and
+	// the number of movements in it. An empty reason matches every movement.
+	ListMovements(ctx context.Context, productID uint, reason string, limit, offset int) ([]models.StockMovement, int64, error)
+	// ListLowStock returns the products at or below their low-stock threshold
+	ListLowStock(ctx context.Context) ([]models.Product, error)
+	// ReconcileStock compares stock with the ledger and, with apply, resets
+	// drifting values to the ledger value
+	ReconcileStock(ctx context.Context, apply bool) ([]helpers.StockDrift, []helpers.WarehouseStockDrift, error)
+
+	// EachBatch calls fn with every product, size at a time in id order,
+	// stopping at the first error
+	EachBatch(ctx context.Context, size int, fn func(products []models.Product) error) error
+	CreateImportJob(ctx context.Context, job *models.ImportJob) error
+	FindImportJob(ctx context.Context, id uint) (models.ImportJob, error)
+}
+
+type productRepository struct {
+	db *gorm.DB
+}
+
+func (r *productRepository) FindByID(ctx context.Context, id uint) (models.Product, error) {
+	var product models.Product
+	err := r.db.WithContext(ctx).First(&product, id).Error
+	return product, translate(err)
+}
+
+func (r *productRepository) FindDeleted(ctx context.Context, id uint) (models.Product, error) {
+	var product models.Product
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&product).Error
+	return product, translate(err)
+}
+
+func (r *productRepository) List(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) ListDeleted(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) Create(ctx context.Context, product *models.Product) error {
+	return r.db.WithContext(ctx).Create(product).Error
+}
+
+func (r *productRepository) Update(ctx context.Context, product *models.Product, changes models.Product) error {
+	return r.db.WithContext(ctx).Model(product).Updates(changes).Error
+}
+
+func (r *productRepository) Delete(ctx context.Context, product *models.Product) error {
+	return r.db.WithContext(ctx).Delete(product).Error
+}
+
+func (r *productRepository) Restore(ctx context.Context, product *models.Product) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(product).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	product.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (r *productRepository) AdjustStock(ctx context.Context, change helpers.StockChange) (*models.StockMovement, error) {
+	movement, err := helpers.AdjustStock(r.db.WithContext(ctx), change)
+	return movement, translate(err)
+}
+
+func (r *productRepository) SetStock(ctx context.Context, productID uint, level int, reason, note string, actorID uint) error {
+	return helpers.SetStock(r.db.WithContext(ctx), productID, level, reason, note, actorID)
+}
+
+func (r *productRepository) ListMovements(ctx context.Context, productID uint, reason string, limit, offset int) ([]models.StockMovement, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockMovement{}).Where("product_id = ?", productID)
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var movements []models.StockMovement
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&movements).Error
+	return movements, total, err
+}
+
+func (r *productRepository) ListLowStock(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Where("stock <= low_stock_threshold").Order("stock").Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) ReconcileStock(ctx context.Context, apply bool) ([]helpers.StockDrift, []helpers.WarehouseStockDrift, error) {
+	return helpers.ReconcileStock(r.db.WithContext(ctx), apply)
+}
+
+func (r *productRepository) EachBatch(ctx context.Context, size int, fn func(products []models.Product) error) error {
+	var products []models.Product
+	return r.db.WithContext(ctx).Order("id").FindInBatches(&products, size, func(tx *gorm.DB, batch int) error {
+		return fn(products)
+	}).Error
+}
+
+func (r *productRepository) CreateImportJob(ctx context.Context, job *models.ImportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *productRepository) FindImportJob(ctx context.Context, id uint) (models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.WithContext(ctx).First(&job, id).Error
+	return job, translate(err)
+}
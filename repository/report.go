@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// ReportRange is the date range shared by all reports. Nil ends are open
+// and To is exclusive.
+type ReportRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// apply restricts query to orders created in the range
+func (r ReportRange) apply(query *gorm.DB, table string) *gorm.DB {
+	if r.From != nil {
+		query = query.Where(table+".created_at >= ?", *r.From)
+	}
+	if r.To != nil {
+		query = query.Where(table+".created_at < ?", *r.To)
+	}
+	return query
+}
+
+// RevenueBucket is one period of the revenue report
+type RevenueBucket struct {
+	Period  time.Time `json:"period"`
+	Orders  int64     `json:"orders"`
+	Revenue float64   `json:"revenue"`
+}
+
+// StatusCount is one row of the orders by status report
+type StatusCount struct {
+	Status  string  `json:"status"`
+	Orders  int64   `json:"orders"`
+	Revenue float64 `json:"revenue"`
+}
+
+// AverageOrderValue is the result of the average order value report
+type AverageOrderValue struct {
+	Orders            int64   `json:"orders"`
+	Revenue           float64 `json:"revenue"`
+	AverageOrderValue float64 `json:"average_order_value"`
+}
+
+// TopProduct is one row of the top products report
+type TopProduct struct {
+	ProductID   uint    `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	SKU         string  `json:"sku"`
+	Units       int64   `json:"units"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// TopCategory is one row of the top categories report
+type TopCategory struct {
+	Category string  `json:"category"`
+	Units    int64   `json:"units"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// CustomerBucket is one period of the customers report
+type CustomerBucket struct {
+	Period             time.Time `json:"period"`
+	NewCustomers       int64     `json:"new_customers"`
+	ReturningCustomers int64     `json:"returning_customers"`
+}
+
+// ReportRepository aggregates orders for the sales reports. Interval is a
+// date_trunc unit: day, week or month. Every report but OrdersByStatus
+// leaves out cancelled orders.
+type ReportRepository interface {
+	Revenue(ctx context.Context, r ReportRange, interval string) ([]RevenueBucket, error)
+	OrdersByStatus(ctx context.Context, r ReportRange) ([]StatusCount, error)
+	AverageOrderValue(ctx context.Context, r ReportRange) (AverageOrderValue, error)
+	// TopProducts ranks products by the order clause, using the product
+	// snapshot stored on order items
+	TopProducts(ctx context.Context, r ReportRange, order string, limit int) ([]TopProduct, error)
+	TopCategories(ctx context.Context, r ReportRange, order string, limit int) ([]TopCategory, error)
+	// Customers splits ordering customers per period into new ones, whose
+	// first order falls in that period, and returning ones who ordered before
+	Customers(ctx context.Context, r ReportRange, interval string) ([]CustomerBucket, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+// orders restricts query to the non-cancelled orders created in r
+func (r *reportRepository) orders(query *gorm.DB, rng ReportRange) *gorm.DB {
+	return rng.apply(query.Where("orders.status <> ?", models.OrderStatusCancelled), "orders")
+}
+
+func (r *reportRepository) Revenue(ctx context.Context, rng ReportRange, interval string) ([]RevenueBucket, error) {
+	var buckets []RevenueBucket
+	query := r.db.WithContext(ctx).Table("orders").
+		Select("date_trunc(?, orders.created_at) AS period, COUNT(*) AS orders, COALESCE(SUM(orders.total_amount), 0) AS revenue", interval)
+	err := r.orders(query, rng).Group("period").Order("period").Scan(&buckets).Error
+	return buckets, err
+}
+
+func (r *reportRepository) OrdersByStatus(ctx context.Context, rng ReportRange) ([]StatusCount, error) {
+	var counts []StatusCount
+	query := r.db.WithContext(ctx).Table("orders").
+		Select("orders.status AS status, COUNT(*) AS orders, COALESCE(SUM(orders.total_amount), 0) AS revenue")
+	err := rng.apply(query, "orders").Group("orders.status").Order("orders DESC").Scan(&counts).Error
+	return counts, err
+}
+
+func (r *reportRepository) AverageOrderValue(ctx context.Context, rng ReportRange) (AverageOrderValue, error) {
+	var result AverageOrderValue
+	query := r.db.WithContext(ctx).Table("orders").
+		Select("COUNT(*) AS orders, COALESCE(SUM(orders.total_amount), 0) AS revenue, COALESCE(AVG(orders.total_amount), 0) AS average_order_value")
+	err := r.orders(query, rng).Scan(&result).Error
+	return result, err
+}
+
+func (r *reportRepository) TopProducts(ctx context.Context, rng ReportRange, order string, limit int) ([]TopProduct, error) {
+	var products []TopProduct
+	query := r.db.WithContext(ctx).Table("order_items").
+		Select("order_items.product_id AS product_id, MAX(order_items.product_name) AS product_name, MAX(order_items.sku) AS sku, SUM(order_items.quantity) AS units, COALESCE(SUM(order_items.price), 0) AS revenue").
+		Joins("JOIN orders ON orders.id = order_items.order_id")
+	err := r.orders(query, rng).Group("order_items.product_id").Order(order).Limit(limit).Scan(&products).Error
+	return products, err
+}
+
+func (r *reportRepository) TopCategories(ctx context.Context, rng ReportRange, order string, limit int) ([]TopCategory, error) {
+	var categories []TopCategory
+	query := r.db.WithContext(ctx).Table("order_items").
+		Select("COALESCE(NULLIF(order_items.category, ''), 'uncategorized') AS category, SUM(order_items.quantity) AS units, COALESCE(SUM(order_items.price), 0) AS revenue").
+		Joins("JOIN orders ON orders.id = order_items.order_id")
+	err := r.orders(query, rng).Group("1").Order(order).Limit(limit).Scan(&categories).Error
+	return categories, err
+}
+
+func (r *reportRepository) Customers(ctx context.Context, rng ReportRange, interval string) ([]CustomerBucket, error) {
+	db := r.db.WithContext(ctx)
+	firstOrders := db.Table("orders").
+		Select("user_id, MIN(created_at) AS first_order_at").
+		Where("status <> ?", models.OrderStatusCancelled).
+		Group("user_id")
+
+	query := db.Table("orders").
+		Select(`date_trunc(?, orders.created_at) AS period,
+			COUNT(DISTINCT orders.user_id) FILTER (WHERE first_orders.first_order_at >= date_trunc(?, orders.created_at)) AS new_customers,
+			COUNT(DISTINCT orders.user_id) FILTER (WHERE first_orders.first_order_at < date_trunc(?, orders.created_at)) AS returning_customers`,
+			interval, interval, interval).
+		Joins("JOIN (?) AS first_orders ON first_orders.user_id = orders.user_id", firstOrders)
+
+	var buckets []CustomerBucket
+	err := r.orders(query, rng).Group("period").Order("period").Scan(&buckets).Error
+	return buckets, err
+}
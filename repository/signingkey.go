@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/signing"
+	"gorm.io/gorm"
+)
+
+// SigningKeyRepository stores the keys access tokens are signed with. Keys
+// are created and retired by the signing package.
+type SigningKeyRepository interface {
+	// List returns every key, newest first
+	List(ctx context.Context) ([]models.SigningKey, error)
+	Rotate(ctx context.Context, policy signing.Policy, immediate bool) (models.SigningKey, error)
+	Revoke(ctx context.Context, policy signing.Policy, kid string) error
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *signingKeyRepository) List(ctx context.Context) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.db.WithContext(ctx).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *signingKeyRepository) Rotate(ctx context.Context, policy signing.Policy, immediate bool) (models.SigningKey, error) {
+	return signing.Rotate(ctx, r.db, policy, immediate)
+}
+
+func (r *signingKeyRepository) Revoke(ctx context.Context, policy signing.Policy, kid string) error {
+	return translate(signing.Revoke(ctx, r.db, policy, kid))
+}
@@ -0,0 +1,113 @@
+// Package repository is the data access layer. Services depend on the
+// interfaces here rather than on GORM or the global database handle, so
+// business rules can be exercised against any implementation.
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// Store hands out the repository of each aggregate. Repositories of a store
+// passed to Transaction share its transaction.
+type Store interface {
+	Users() UserRepository
+	Products() ProductRepository
+	Carts() CartRepository
+	Orders() OrderRepository
+	Warehouses() WarehouseRepository
+	Webhooks() WebhookRepository
+	Notifications() NotificationRepository
+	APIKeys() APIKeyRepository
+	AuditLogs() AuditLogRepository
+	SigningKeys() SigningKeyRepository
+	Reports() ReportRepository
+	Events() EventRepository
+
+	// Transaction runs fn in a transaction, committed when fn returns nil
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+	// PublishEvent adds an event to the outbox. Publish from inside a
+	// transaction so the event is only seen if the change commits.
+	PublishEvent(ctx context.Context, eventType string, data interface{}) error
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewStore returns a Store backed by db
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Users() UserRepository {
+	return &userRepository{db: s.db}
+}
+
+func (s *gormStore) Products() ProductRepository {
+	return &productRepository{db: s.db}
+}
+
+func (s *gormStore) Carts() CartRepository {
+	return &cartRepository{db: s.db}
+}
+
+func (s *gormStore) Orders() OrderRepository {
+	return &orderRepository{db: s.db}
+}
+
+func (s *gormStore) Warehouses() WarehouseRepository {
+	return &warehouseRepository{db: s.db}
+}
+
+func (s *gormStore) Webhooks() WebhookRepository {
+	return &webhookRepository{db: s.db}
+}
+
+func (s *gormStore) Notifications() NotificationRepository {
+	return &notificationRepository{db: s.db}
+}
+
+func (s *gormStore) APIKeys() APIKeyRepository {
+	return &apiKeyRepository{db: s.db}
+}
+
+func (s *gormStore) AuditLogs() AuditLogRepository {
+	return &auditLogRepository{db: s.db}
+}
+
+func (s *gormStore) SigningKeys() SigningKeyRepository {
+	return &signingKeyRepository{db: s.db}
+}
+
+func (s *gormStore) Reports() ReportRepository {
+	return &reportRepository{db: s.db}
+}
+
+func (s *gormStore) Events() EventRepository {
+	return &eventRepository{db: s.db}
+}
+
+func (s *gormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+func (s *gormStore) PublishEvent(ctx context.Context, eventType string, data interface{}) error {
+	return helpers.PublishEvent(s.db.WithContext(ctx), eventType, data)
+}
+
+// translate reports missing records as ErrNotFound
+func translate(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserRepository stores user accounts. Soft-deleted users are only returned
+// by the methods that say so.
+type UserRepository interface {
+	FindByID(ctx context.Context, id uint) (models.User, error)
+	FindByEmail(ctx context.Context, email string) (models.User, error)
+	// FindDeleted returns a soft-deleted user
+	FindDeleted(ctx context.Context, id uint) (models.User, error)
+	List(ctx context.Context) ([]models.User, error)
+	ListDeleted(ctx context.Context) ([]models.User, error)
+	// EmailTaken reports whether any user, deleted or not, has email
+	EmailTaken(ctx context.Context, email string) (bool, error)
+	Create(ctx context.Context, user *models.User) error
+	Save(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, user *models.User) error
+	Restore(ctx context.Context, user *models.User) error
+
+	// AddFailedLogin counts a failed sign-in in the database, so concurrent
+	// guesses are all counted, and reads the new count into user
+	AddFailedLogin(ctx context.Context, user *models.User) error
+	LockUntil(ctx context.Context, user *models.User, until time.Time) error
+	ResetFailedLogins(ctx context.Context, user *models.User) error
+
+	SetTOTPSecret(ctx context.Context, user *models.User, secret string) error
+	// UseTOTPStep records step as the last one a code was accepted for. It
+	// reports false when that step or a later one was already used.
+	UseTOTPStep(ctx context.Context, user *models.User, step int64) (bool, error)
+	EnableTwoFactor(ctx context.Context, user *models.User) error
+	// DisableTwoFactor turns two-factor authentication off and forgets the
+	// secret and recovery codes
+	DisableTwoFactor(ctx context.Context, user *models.User) error
+	// ReplaceRecoveryCodes discards the user's recovery codes and stores
+	// codeHashes instead
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	// UseRecoveryCode spends an unused recovery code. It reports false when
+	// the user has no such code.
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uint) (int64, error)
+
+	// FindAnyByEmail returns the user, deleted or not, with email in any case
+	FindAnyByEmail(ctx context.Context, email string) (models.User, error)
+	FindIdentity(ctx context.Context, provider, subject string) (models.UserIdentity, error)
+	ListIdentities(ctx context.Context, userID uint) ([]models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+	TouchIdentity(ctx context.Context, identity *models.UserIdentity) error
+
+	CreateOIDCLogin(ctx context.Context, login *models.OIDCLoginState) error
+	// TakeOIDCLogin deletes the sign-in started with state and returns it,
+	// so a callback cannot be replayed
+	TakeOIDCLogin(ctx context.Context, state string) (models.OIDCLoginState, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	return user, translate(err)
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	return user, translate(err)
+}
+
+func (r *userRepository) FindDeleted(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error
+	return user, translate(err)
+}
+
+func (r *userRepository) List(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) ListDeleted(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) EmailTaken(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *userRepository) Save(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+func (r *userRepository) Delete(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Delete(user).Error
+}
+
+func (r *userRepository) Restore(ctx context.Context, user *models.User) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(user).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (r *userRepository) AddFailedLogin(ctx context.Context, user *models.User) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Model(user).UpdateColumn("failed_logins", gorm.Expr("failed_logins + 1")).Error; err != nil {
+		return err
+	}
+	return db.Model(user).Select("failed_logins").First(user).Error
+}
+
+func (r *userRepository) LockUntil(ctx context.Context, user *models.User, until time.Time) error {
+	if err := r.db.WithContext(ctx).Model(user).UpdateColumn("locked_until", until).Error; err != nil {
+		return err
+	}
+	user.LockedUntil = &until
+	return nil
+}
+
+func (r *userRepository) ResetFailedLogins(ctx context.Context, user *models.User) error {
+	if user.FailedLogins == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Model(user).UpdateColumns(map[string]interface{}{
+		"failed_logins": 0,
+		"locked_until":  nil,
+	}).Error
+	if err != nil {
+		return err
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	return nil
+}
+
+func (r *userRepository) SetTOTPSecret(ctx context.Context, user *models.User, secret string) error {
+	err := r.db.WithContext(ctx).Model(user).Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error
+	if err != nil {
+		return err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	return nil
+}
+
+func (r *userRepository) UseTOTPStep(ctx context.Context, user *models.User, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		UpdateColumn("totp_last_step", step)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+func (r *userRepository) EnableTwoFactor(ctx context.Context, user *models.User) error {
+	if err := r.db.WithContext(ctx).Model(user).Update("two_factor_enabled", true).Error; err != nil {
+		return err
+	}
+	user.TwoFactorEnabled = true
+	return nil
+}
+
+func (r *userRepository) DisableTwoFactor(ctx context.Context, user *models.User) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return db.Model(user).Updates(map[string]interface{}{
+		"two_factor_enabled": false,
+		"totp_secret":        "",
+		"totp_last_step":     0,
+	}).Error
+}
+
+func (r *userRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	records := make([]models.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	return db.Create(&records).Error
+}
+
+func (r *userRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *userRepository) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) FindAnyByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+	return user, translate(err)
+}
+
+func (r *userRepository) FindIdentity(ctx context.Context, provider, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	return identity, translate(err)
+}
+
+func (r *userRepository) ListIdentities(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+func (r *userRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *userRepository) TouchIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	now := time.Now()
+	if err := r.db.WithContext(ctx).Model(identity).Update("last_login_at", now).Error; err != nil {
+		return err
+	}
+	identity.LastLoginAt = now
+	return nil
+}
+
+func (r *userRepository) CreateOIDCLogin(ctx context.Context, login *models.OIDCLoginState) error {
+	return r.db.WithContext(ctx).Create(login).Error
+}
+
+func (r *userRepository) TakeOIDCLogin(ctx context.Context, state string) (models.OIDCLoginState, error) {
+	var logins []models.OIDCLoginState
+	if err := r.db.WithContext(ctx).Clauses(clause.Returning{}).Where("state = ?", state).Delete(&logins).Error; err != nil {
+		return models.OIDCLoginState{}, err
+	}
+	if len(logins) == 0 {
+		return models.OIDCLoginState{}, ErrNotFound
+	}
+	return logins[0], nil
+}
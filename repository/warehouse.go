@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// WarehouseRepository stores the warehouses orders are fulfilled from and
+// the stock each one holds
+type WarehouseRepository interface {
+	// List returns every warehouse in allocation order
+	List(ctx context.Context) ([]models.Warehouse, error)
+	FindByID(ctx context.Context, id uint) (models.Warehouse, error)
+	Count(ctx context.Context) (int64, error)
+	// CountByIDs counts how many of ids are warehouses
+	CountByIDs(ctx context.Context, ids []uint) (int64, error)
+	CodeTaken(ctx context.Context, code string) (bool, error)
+	Create(ctx context.Context, warehouse *models.Warehouse) error
+	// Update writes updates, keyed by column, to warehouse
+	Update(ctx context.Context, warehouse *models.Warehouse, updates map[string]interface{}) error
+	// ClearDefault unsets the default flag of every warehouse except one
+	ClearDefault(ctx context.Context, exceptID uint) error
+
+	// ListStock returns the non-zero stock levels held in a warehouse
+	ListStock(ctx context.Context, warehouseID uint) ([]models.WarehouseStock, error)
+	// TransferStock moves stock of a product between two warehouses and
+	// returns the ledger movements
+	TransferStock(ctx context.Context, productID, fromID, toID uint, quantity int, reference, note string, actorID uint) ([]models.StockMovement, error)
+}
+
+type warehouseRepository struct {
+	db *gorm.DB
+}
+
+func (r *warehouseRepository) List(ctx context.Context) ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+	err := r.db.WithContext(ctx).Order("priority, id").Find(&warehouses).Error
+	return warehouses, err
+}
+
+func (r *warehouseRepository) FindByID(ctx context.Context, id uint) (models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.WithContext(ctx).First(&warehouse, id).Error
+	return warehouse, translate(err)
+}
+
+func (r *warehouseRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Warehouse{}).Count(&count).Error
+	return count, err
+}
+
+func (r *warehouseRepository) CountByIDs(ctx context.Context, ids []uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Warehouse{}).Where("id IN ?", ids).Count(&count).Error
+	return count, err
+}
+
+func (r *warehouseRepository) CodeTaken(ctx context.Context, code string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Warehouse{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *warehouseRepository) Create(ctx context.Context, warehouse *models.Warehouse) error {
+	return r.db.WithContext(ctx).Create(warehouse).Error
+}
+
+func (r *warehouseRepository) Update(ctx context.Context, warehouse *models.Warehouse, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(warehouse).Updates(updates).Error
+}
+
+func (r *warehouseRepository) ClearDefault(ctx context.Context, exceptID uint) error {
+	return r.db.WithContext(ctx).Model(&models.Warehouse{}).
+		Where("id <> ? AND is_default = ?", exceptID, true).
+		Update("is_default", false).Error
+}
+
+func (r *warehouseRepository) ListStock(ctx context.Context, warehouseID uint) ([]models.WarehouseStock, error) {
+	var stocks []models.WarehouseStock
+	err := r.db.WithContext(ctx).Where("warehouse_id = ? AND quantity <> 0", warehouseID).Order("product_id").Find(&stocks).Error
+	return stocks, err
+}
+
+func (r *warehouseRepository) TransferStock(ctx context.Context, productID, fromID, toID uint, quantity int, reference, note string, actorID uint) ([]models.StockMovement, error) {
+	movements, err := helpers.TransferStock(r.db.WithContext(ctx), productID, fromID, toID, quantity, reference, note, actorID)
+	return movements, translate(err)
+}
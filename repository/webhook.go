@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// DeliveryFilter narrows the webhook delivery log. Zero fields match every
+// delivery.
+type DeliveryFilter struct {
+	EndpointID uint
+	Status     string
+	EventType  string
+}
+
+// WebhookRepository stores webhook endpoints and their delivery log
+type WebhookRepository interface {
+	List(ctx context.Context) ([]models.WebhookEndpoint, error)
+	FindByID(ctx context.Context, id uint) (models.WebhookEndpoint, error)
+	Create(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	// Update writes updates, keyed by column, to endpoint
+	Update(ctx context.Context, endpoint *models.WebhookEndpoint, updates map[string]interface{}) error
+	// Delete removes an endpoint together with its delivery log
+	Delete(ctx context.Context, endpoint *models.WebhookEndpoint) error
+
+	// ListDeliveries returns a page of the delivery log, newest first, and
+	// the number of deliveries matching filter
+	ListDeliveries(ctx context.Context, filter DeliveryFilter, limit, offset int) ([]models.WebhookDelivery, int64, error)
+	FindDelivery(ctx context.Context, id uint) (models.WebhookDelivery, error)
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.WithContext(ctx).Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *webhookRepository) FindByID(ctx context.Context, id uint) (models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	err := r.db.WithContext(ctx).First(&endpoint, id).Error
+	return endpoint, translate(err)
+}
+
+func (r *webhookRepository) Create(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	return r.db.WithContext(ctx).Create(endpoint).Error
+}
+
+func (r *webhookRepository) Update(ctx context.Context, endpoint *models.WebhookEndpoint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(endpoint).Updates(updates).Error
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	return r.db.WithContext(ctx).Delete(endpoint).Error
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, filter DeliveryFilter, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.WebhookDelivery{})
+	if filter.EndpointID != 0 {
+		query = query.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []models.WebhookDelivery
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+func (r *webhookRepository) FindDelivery(ctx context.Context, id uint) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.WithContext(ctx).First(&delivery, id).Error
+	return delivery, translate(err)
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
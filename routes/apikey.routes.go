@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// APIKeyRoutes sets up the admin endpoints that issue and revoke API keys
+func APIKeyRoutes(incomingRoutes *gin.Engine, apiKeyService *services.APIKeyService) {
+	apiKeyRoutes := incomingRoutes.Group("/api/v1/admin/api-keys")
+	apiKeyRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+	apiKeyRoutes.GET("/", controllers.AdminGetAPIKeys(apiKeyService))
+	apiKeyRoutes.POST("/", controllers.AdminCreateAPIKey(apiKeyService))
+	apiKeyRoutes.GET("/scopes", controllers.AdminGetAPIKeyScopes())
+	apiKeyRoutes.DELETE("/:keyId", controllers.AdminRevokeAPIKey(apiKeyService))
+}
+
+var apiKeyOperations = openapi.Tagged("API keys",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/api-keys/", Summary: "List API keys", Access: openapi.Admin,
+		Query:    []openapi.Param{openapi.Query("active", false, "Only keys that still work")},
+		Response: openapi.Data([]dto.APIKey{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/api-keys/", Summary: "Issue an API key", Access: openapi.Admin,
+		Description: "Needs a session; API keys cannot manage API keys. The key is only shown in this response.",
+		Request:     openapi.JSON(controllers.APIKeyInput{}), Status: http.StatusCreated,
+		Response: openapi.Data(openapi.Object{"api_key": dto.APIKey{}, "key": ""}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/api-keys/scopes", Summary: "List the scopes keys can be given", Access: openapi.Admin,
+		Response: openapi.Data(openapi.Object{"resources": []string{}, "actions": []string{}}),
+	},
+	openapi.Operation{
+		Method: "DELETE", Path: "/api/v1/admin/api-keys/:keyId", Summary: "Revoke an API key", Access: openapi.Admin,
+		Response: openapi.Data(dto.APIKey{}),
+	},
+)
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// AuditRoutes sets up the API routes for browsing the admin audit log
+func AuditRoutes(incomingRoutes *gin.Engine, auditService *services.AuditService) {
+	auditRoutes := incomingRoutes.Group("/api/v1/admin/audit-logs")
+	auditRoutes.Use(middlewares.CheckAdmin())
+	auditRoutes.GET("/", controllers.AdminGetAuditLogs(auditService))
+}
+
+var auditOperations = openapi.Tagged("Audit",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/audit-logs/", Summary: "Search the audit log", Access: openapi.Admin,
+		Query: append([]openapi.Param{
+			openapi.Query("actor_id", uint(0), "Only changes made by this user"),
+			openapi.Query("action", "", "create, update or delete"),
+			openapi.Query("entity_type", "", "Only changes to this kind of record"),
+			openapi.Query("entity_id", "", "Only changes to this record"),
+			openapi.Query("request_id", "", "Only changes made by this request"),
+			openapi.Query("from", "", "Start date or RFC 3339 time"),
+			openapi.Query("to", "", "End date or RFC 3339 time"),
+		}, openapi.PageQuery...),
+		Response: openapi.Page([]dto.AuditLog{}),
+	},
+)
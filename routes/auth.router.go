@@ -1,13 +1,69 @@
 package routes
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	controller "github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/ratelimit"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-func AuthRoutes(incomingRoutes *gin.Engine) {
+func AuthRoutes(incomingRoutes *gin.Engine, userService *services.UserService) {
 	authRoutes := incomingRoutes.Group("/api/v1/auth")
-	authRoutes.POST("/signup", controller.Signup())
-	authRoutes.POST("/signin", controller.Signin())
+	authRoutes.POST("/signup", middlewares.RateLimit("signup", ratelimit.Per(10, time.Hour), middlewares.ByIP), controller.Signup(userService))
+	authRoutes.POST("/signin", middlewares.RateLimit("signin", ratelimit.Per(10, time.Minute), middlewares.ByIP, middlewares.ByAccount), controller.Signin(userService))
 	authRoutes.POST("/signout", controller.Signout())
+	authRoutes.GET("/oidc/providers", controller.GetOIDCProviders())
+	authRoutes.GET("/oidc/:provider/login", middlewares.RateLimit("oidc_login", ratelimit.Per(20, time.Minute), middlewares.ByIP), controller.OIDCLogin(userService))
+	authRoutes.GET("/oidc/:provider/callback", controller.OIDCCallback(userService))
+	authRoutes.POST("/2fa/verify", middlewares.RateLimit("2fa_verify", ratelimit.Per(10, time.Minute), middlewares.ByIP), controller.VerifyTwoFactor(userService))
 }
+
+var authOperations = openapi.Tagged("Auth",
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/auth/signup", Summary: "Create an account",
+		Request:  openapi.JSON(controller.SignupInput{}),
+		Response: openapi.Fields(openapi.Object{"user": dto.User{}}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/auth/signin", Summary: "Sign in with email and password",
+		Description: "Sets the Authorization cookie and returns the token. Users with two-factor authentication get " +
+			"two_factor_required and a challenge_token to pass to /api/v1/auth/2fa/verify instead. Repeated failures lock the account.",
+		Request:  openapi.JSON(controller.SigninInput{}),
+		Response: openapi.Fields(openapi.Object{"token": ""}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/auth/signout", Summary: "Clear the session cookie",
+		Response: openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/auth/oidc/providers", Summary: "List identity providers",
+		Response: openapi.Data([]string{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/auth/oidc/:provider/login", Summary: "Start sign-in with an identity provider",
+		Description: "Redirects to the provider's authorization endpoint and sets the oidc_state cookie the callback checks.",
+		Status:      http.StatusFound,
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/auth/oidc/:provider/callback", Summary: "Finish sign-in with an identity provider",
+		Description: "The provider redirects here. The state must match the oidc_state cookie set by the login endpoint. Answers like signin.",
+		Query: []openapi.Param{
+			openapi.Query("code", "", "Authorization code"),
+			openapi.Query("state", "", "State issued by the login endpoint"),
+			openapi.Query("error", "", "Error reported by the provider"),
+		},
+		Response: openapi.Fields(openapi.Object{"token": ""}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/auth/2fa/verify", Summary: "Finish sign-in with a second factor",
+		Description: "Takes the challenge token from signin and either a TOTP code or a recovery code.",
+		Request:     openapi.JSON(controller.TwoFactorVerifyInput{}),
+		Response:    openapi.Fields(openapi.Object{"token": "", "recovery_codes_remaining": int64(0)}),
+	},
+)
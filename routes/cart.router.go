@@ -1,16 +1,43 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	controller "github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
 	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-func CartRoutes(incomingRoutes *gin.Engine) {
+func CartRoutes(incomingRoutes *gin.Engine, cartService *services.CartService) {
 	incomingcartRoutes := incomingRoutes.Group("/api/v1/cart")
 	incomingcartRoutes.Use(middlewares.CheckUser())
-	incomingcartRoutes.GET("/", controller.GetCart())
-	incomingcartRoutes.POST("/", controller.AddToCart())
-	incomingcartRoutes.PUT("/update-quantity/:cartItemId", controller.UpdateCartItemQuantity())
-	incomingcartRoutes.DELETE("/:id", controller.DeleteCartItem())
+	incomingcartRoutes.GET("/", controller.GetCart(cartService))
+	incomingcartRoutes.POST("/", middlewares.Idempotency(), controller.AddToCart(cartService))
+	incomingcartRoutes.PUT("/update-quantity/:cartItemId", controller.UpdateCartItemQuantity(cartService))
+	incomingcartRoutes.DELETE("/:id", controller.DeleteCartItem(cartService))
 }
+
+var cartOperations = openapi.Tagged("Cart",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/cart/", Summary: "Get the cart", Access: openapi.Authenticated,
+		Response: openapi.Data(dto.Cart{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/cart/", Summary: "Add a product to the cart", Access: openapi.Authenticated,
+		Idempotent: true, Request: openapi.JSON(models.CartItem{}), Status: http.StatusCreated,
+		Response: openapi.Data(dto.Cart{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/cart/update-quantity/:cartItemId", Summary: "Change the quantity of a cart item",
+		Access: openapi.Authenticated, Request: openapi.JSON(controller.CartQuantityInput{}),
+		Response: openapi.Data(dto.CartItem{}),
+	},
+	openapi.Operation{
+		Method: "DELETE", Path: "/api/v1/cart/:id", Summary: "Remove an item from the cart", Access: openapi.Authenticated,
+		Response: openapi.Message(),
+	},
+)
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// EmailRoutes sets up the admin view of the outgoing mail queue
+func EmailRoutes(incomingRoutes *gin.Engine, notificationService *services.NotificationService) {
+	emailRoutes := incomingRoutes.Group("/api/v1/admin/emails")
+	emailRoutes.Use(middlewares.CheckAdmin())
+	emailRoutes.GET("/", controllers.AdminGetEmails(notificationService))
+}
+
+var emailOperations = openapi.Tagged("Emails",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/emails/", Summary: "List outgoing emails", Access: openapi.Admin,
+		Query: append([]openapi.Param{
+			openapi.Query("status", "", "Only emails with this status"),
+			openapi.Query("template", "", "Only emails rendered from this template"),
+			openapi.Query("user_id", uint(0), "Only emails to this user"),
+		}, openapi.PageQuery...),
+		Response: openapi.Page([]dto.EmailMessage{}),
+	},
+)
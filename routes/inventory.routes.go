@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// InventoryRoutes sets up the admin stock ledger endpoints
+func InventoryRoutes(incomingRoutes *gin.Engine, productService *services.ProductService) {
+	inventoryRoutes := incomingRoutes.Group("/api/v1/admin/inventory")
+	inventoryRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+	inventoryRoutes.GET("/low-stock", controllers.AdminGetLowStockProducts(productService))
+	inventoryRoutes.POST("/reconcile", controllers.AdminReconcileStock(productService))
+	inventoryRoutes.GET("/:productId/movements", controllers.AdminGetStockMovements(productService))
+	inventoryRoutes.POST("/:productId/adjustments", middlewares.Idempotency(), controllers.AdminAdjustStock(productService))
+}
+
+var inventoryOperations = openapi.Tagged("Inventory",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/inventory/low-stock", Summary: "List products low on stock", Access: openapi.Admin,
+		Response: openapi.Data([]dto.Product{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/inventory/reconcile", Summary: "Compare stock with the ledger", Access: openapi.Admin,
+		Query:    []openapi.Param{openapi.Query("apply", false, "Reset drifting stock to the ledger value")},
+		Response: openapi.Data([]helpers.StockDrift{}).With(openapi.Object{"warehouses": []helpers.WarehouseStockDrift{}}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/inventory/:productId/movements", Summary: "List the stock movements of a product",
+		Access:   openapi.Admin,
+		Query:    append([]openapi.Param{openapi.Query("reason", "", "Only movements with this reason")}, openapi.PageQuery...),
+		Response: openapi.Page([]dto.StockMovement{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/inventory/:productId/adjustments", Summary: "Adjust the stock of a product",
+		Access: openapi.Admin, Idempotent: true, Request: openapi.JSON(controllers.StockAdjustmentInput{}),
+		Status: http.StatusCreated, Response: openapi.Data(dto.StockMovement{}),
+	},
+)
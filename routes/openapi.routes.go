@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"gorm.io/gorm"
+)
+
+var docsOperations = openapi.Tagged("Docs",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/openapi.json", Summary: "This OpenAPI document",
+		Response: openapi.JSON(openapi.Object{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/docs", Summary: "API reference page",
+		Response: openapi.Content("text/html", openapi.Text),
+	},
+)
+
+// operations documents every route of the API
+func operations() []openapi.Operation {
+	groups := [][]openapi.Operation{
+		authOperations,
+		productOperations,
+		cartOperations,
+		orderOperations,
+		userOperations,
+		auditOperations,
+		reportOperations,
+		inventoryOperations,
+		warehouseOperations,
+		webhookOperations,
+		emailOperations,
+		signingKeyOperations,
+		apiKeyOperations,
+		docsOperations,
+	}
+
+	var all []openapi.Operation
+	for _, group := range groups {
+		all = append(all, group...)
+	}
+	return all
+}
+
+// OpenAPIRoutes serves the OpenAPI document and the API reference. It must
+// be registered after every other route: the document is built from the
+// routes on the router, and an error is returned when a route is missing
+// from the operations above or an operation has no route. The document is
+// served either way, without the routes that do not match.
+func OpenAPIRoutes(incomingRoutes *gin.Engine) error {
+	document := new(openapi.Document)
+
+	docsRoutes := incomingRoutes.Group("/api/v1")
+	docsRoutes.GET("/openapi.json", controllers.GetOpenAPISpec(document))
+	docsRoutes.GET("/docs", controllers.GetAPIDocs())
+
+	var servers []openapi.Server
+	if baseURL := os.Getenv("BASE_URL"); baseURL != "" {
+		servers = append(servers, openapi.Server{URL: strings.TrimSuffix(baseURL, "/")})
+	}
+
+	built, err := openapi.Build(incomingRoutes.Routes(), operations(), openapi.Options{
+		Info: openapi.Info{
+			Title:       "Ecommerce API",
+			Description: "Errors are returned as application/problem+json with a stable code.",
+			Version:     "1.0.0",
+		},
+		Servers: servers,
+		Error:   openapi.Content("application/problem+json", apperror.Problem{}),
+		Types: map[reflect.Type]*openapi.Schema{
+			reflect.TypeOf(gorm.DeletedAt{}): {Type: "string", Format: "date-time", Nullable: true},
+			reflect.TypeOf(models.JSON{}):    {Description: "Any JSON value"},
+		},
+		Ignore: []string{"/mock-oidc/"},
+		Scope:  helpers.RequiredScope,
+	})
+	if built != nil {
+		*document = *built
+	}
+	return err
+}
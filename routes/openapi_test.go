@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// TestOpenAPICoverage fails when a route is missing from the OpenAPI
+// document or the document describes a route that is not registered
+func TestOpenAPICoverage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Registering routes never touches the database
+	Register(router, services.New(repository.NewStore(nil)))
+
+	err := OpenAPIRoutes(router)
+	var coverage *openapi.CoverageError
+	if errors.As(err, &coverage) {
+		for _, route := range coverage.Undocumented {
+			t.Errorf("route %s has no operation", route)
+		}
+		for _, route := range coverage.Unknown {
+			t.Errorf("operation %s has no route", route)
+		}
+	} else if err != nil {
+		t.Fatal(err)
+	}
+}
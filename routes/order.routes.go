@@ -1,32 +1,140 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
 	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
 // OrderRoutes sets up the API routes for order management
-func OrderRoutes(incomingRoutes *gin.Engine) {
+func OrderRoutes(incomingRoutes *gin.Engine, orderService *services.OrderService) {
 	// User order routes
 	userOrderRoutes := incomingRoutes.Group("/api/v1/orders")
 	userOrderRoutes.Use(middlewares.CheckUser())
-	userOrderRoutes.POST("/checkout", controllers.CreateOrder())
-	userOrderRoutes.GET("/", controllers.GetUserOrders())
-	userOrderRoutes.GET("/:id", controllers.GetUserOrderByID())
-	userOrderRoutes.DELETE("/:id/cancel", controllers.CancelUserOrder())
+	userOrderRoutes.POST("/checkout", middlewares.Idempotency(), controllers.CreateOrder(orderService))
+	userOrderRoutes.GET("/", controllers.GetUserOrders(orderService))
+	userOrderRoutes.GET("/stream", controllers.StreamUserOrders(orderService))
+	userOrderRoutes.GET("/:id", controllers.GetUserOrderByID(orderService))
+	userOrderRoutes.GET("/:id/invoice.pdf", controllers.GetUserOrderInvoice(orderService))
+	userOrderRoutes.DELETE("/:id/cancel", controllers.CancelUserOrder(orderService))
 
 	// Admin order routes
 	adminOrderRoutes := incomingRoutes.Group("/api/v1/admin/orders")
-	adminOrderRoutes.Use(middlewares.CheckAdmin())
-	adminOrderRoutes.GET("/", controllers.AdminGetAllOrders())
-	adminOrderRoutes.GET("/:id", controllers.AdminGetOrderByID())
-	adminOrderRoutes.GET("/user/:user_id", controllers.AdminGetOrdersByUserID())
-	adminOrderRoutes.PUT("/:id/status", controllers.AdminUpdateOrderStatus())
+	adminOrderRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+	adminOrderRoutes.GET("/", controllers.AdminGetAllOrders(orderService))
+	adminOrderRoutes.GET("/stream", controllers.AdminStreamOrders(orderService))
+	adminOrderRoutes.GET("/:id", controllers.AdminGetOrderByID(orderService))
+	adminOrderRoutes.GET("/:id/invoice.pdf", controllers.AdminGetOrderInvoice(orderService))
+	adminOrderRoutes.GET("/:id/packing-slip.pdf", controllers.AdminGetPackingSlip(orderService))
+	adminOrderRoutes.GET("/user/:user_id", controllers.AdminGetOrdersByUserID(orderService))
+	adminOrderRoutes.PUT("/:id/status", controllers.AdminUpdateOrderStatus(orderService))
+	adminOrderRoutes.GET("/:id/shipments", controllers.AdminGetOrderShipments(orderService))
+	adminOrderRoutes.POST("/:id/shipments/:shipmentId/ship", controllers.AdminShipShipment(orderService))
+	adminOrderRoutes.POST("/:id/shipments/:shipmentId/deliver", controllers.AdminDeliverShipment(orderService))
+	adminOrderRoutes.GET("/:id/refunds", controllers.AdminGetOrderRefunds(orderService))
+	adminOrderRoutes.POST("/:id/refunds", middlewares.Idempotency(), controllers.AdminRefundOrder(orderService))
 
 	// Admin order item routes
 	adminOrderItemRoutes := incomingRoutes.Group("/api/v1/admin/order-items")
 	adminOrderItemRoutes.Use(middlewares.CheckAdmin())
-	adminOrderItemRoutes.GET("/", controllers.AdminGetAllOrderItems())
-	adminOrderItemRoutes.GET("/product/:product_id", controllers.AdminGetOrderItemsByProductID())
+	adminOrderItemRoutes.GET("/", controllers.AdminGetAllOrderItems(orderService))
+	adminOrderItemRoutes.GET("/product/:product_id", controllers.AdminGetOrderItemsByProductID(orderService))
 }
+
+var orderOperations = openapi.Tagged("Orders",
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/orders/checkout", Summary: "Place an order", Access: openapi.Authenticated,
+		Description: "Orders the items in the request body and reserves their stock from the warehouses that can ship them. The cart is left unchanged.",
+		Idempotent:  true, Request: openapi.JSON(controllers.CreateOrderInput{}),
+		Response: openapi.Fields(openapi.Object{"order_id": uint(0)}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/orders/", Summary: "List your orders", Access: openapi.Authenticated,
+		Response: openapi.Data([]dto.Order{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/orders/stream", Summary: "Stream updates to your orders", Access: openapi.Authenticated,
+		Description: "Server-Sent Events. Reconnect with Last-Event-ID to receive the events that were missed.",
+		Query:       []openapi.Param{openapi.Query("last_event_id", uint(0), "Resume after this event")},
+		Response:    openapi.Content("text/event-stream", openapi.Text),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/orders/:id", Summary: "Get one of your orders", Access: openapi.Authenticated,
+		Response: openapi.Data(dto.Order{}).With(openapi.Object{"tracking": []controllers.ShipmentTracking{}}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/orders/:id/invoice.pdf", Summary: "Download the invoice of your order",
+		Access: openapi.Authenticated, Response: openapi.Content("application/pdf", openapi.Binary),
+	},
+	openapi.Operation{
+		Method: "DELETE", Path: "/api/v1/orders/:id/cancel", Summary: "Cancel your order", Access: openapi.Authenticated,
+		Response: openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/", Summary: "List orders", Access: openapi.Admin,
+		Response: openapi.Data([]dto.Order{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/stream", Summary: "Stream new orders", Access: openapi.Admin,
+		Description: "Server-Sent Events. Reconnect with Last-Event-ID to receive the events that were missed.",
+		Query:       []openapi.Param{openapi.Query("last_event_id", uint(0), "Resume after this event")},
+		Response:    openapi.Content("text/event-stream", openapi.Text),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/:id", Summary: "Get an order", Access: openapi.Admin,
+		Response: openapi.Data(dto.Order{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/:id/invoice.pdf", Summary: "Download the invoice of an order",
+		Access: openapi.Admin, Response: openapi.Content("application/pdf", openapi.Binary),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/:id/packing-slip.pdf", Summary: "Download the packing slip of an order",
+		Access: openapi.Admin, Response: openapi.Content("application/pdf", openapi.Binary),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/user/:user_id", Summary: "List the orders of a user", Access: openapi.Admin,
+		Response: openapi.Data([]dto.Order{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/admin/orders/:id/status", Summary: "Change the status of an order", Access: openapi.Admin,
+		Description: "Moves an order to processing or cancels it, as long as nothing has shipped. Shipped and delivered follow from the shipments.",
+		Request:     openapi.JSON(controllers.OrderStatusInput{}), Response: openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/:id/shipments", Summary: "List the shipments of an order", Access: openapi.Admin,
+		Response: openapi.Data([]dto.Shipment{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/orders/:id/shipments/:shipmentId/ship", Summary: "Mark a shipment as shipped",
+		Description: "Sending items splits them off into a shipment of their own.",
+		Access:      openapi.Admin, Request: openapi.JSON(controllers.ShipShipmentInput{}),
+		Response: openapi.Data(dto.Shipment{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/orders/:id/shipments/:shipmentId/deliver", Summary: "Mark a shipment as delivered",
+		Access: openapi.Admin, Response: openapi.Data(dto.Shipment{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/orders/:id/refunds", Summary: "List the refunds of an order", Access: openapi.Admin,
+		Response: openapi.Data([]dto.Refund{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/orders/:id/refunds", Summary: "Refund an order", Access: openapi.Admin,
+		Idempotent: true, Request: openapi.JSON(controllers.RefundInput{}), Status: http.StatusCreated,
+		Response: openapi.Data(dto.Refund{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/order-items/", Summary: "List order items", Access: openapi.Admin,
+		Response: openapi.Data([]dto.OrderItem{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/order-items/product/:product_id", Summary: "List the order items of a product",
+		Access: openapi.Admin, Response: openapi.Data([]dto.OrderItem{}),
+	},
+)
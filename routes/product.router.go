@@ -1,20 +1,88 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	controller "github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
 	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-func ProductRoutes(incomingRoutes *gin.Engine) {
+func ProductRoutes(incomingRoutes *gin.Engine, productService *services.ProductService) {
 	productRoutes := incomingRoutes.Group("/api/v1/products")
-	productRoutes.GET("/", controller.GetAllProducts())
-	productRoutes.GET("/:productId", controller.GetProductById())
+	productRoutes.GET("/", controller.GetAllProducts(productService))
+	productRoutes.GET("/:productId", controller.GetProductById(productService))
 
 	adminRoutes := productRoutes.Group("")
-	adminRoutes.Use(middlewares.CheckAdmin())
+	adminRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+
+	adminRoutes.GET("/deleted", controller.GetDeletedProducts(productService))
+	adminRoutes.POST("/", controller.CreateProduct(productService))
+	adminRoutes.PUT("/:productId", controller.UpdateProduct(productService))
+	adminRoutes.DELETE("/:productId", controller.DeleteProduct(productService))
+	adminRoutes.PUT("/:productId/restore", controller.RestoreProduct(productService))
 
-	adminRoutes.POST("/", controller.CreateProduct())
-	adminRoutes.PUT("/:productId", controller.UpdateProduct())
-	adminRoutes.DELETE("/:productId", controller.DeleteProduct())
+	bulkRoutes := incomingRoutes.Group("/api/v1/admin/products")
+	bulkRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+	bulkRoutes.POST("/import", controller.ImportProducts(productService))
+	bulkRoutes.GET("/import/:jobId", controller.GetImportJob(productService))
+	bulkRoutes.GET("/export", controller.ExportProducts(productService))
 }
+
+var productOperations = openapi.Tagged("Products",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/products/", Summary: "List products",
+		Response: openapi.Data([]dto.Product{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/products/:productId", Summary: "Get a product",
+		Response: openapi.Data(dto.Product{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/products/deleted", Summary: "List deleted products", Access: openapi.Admin,
+		Response: openapi.Data([]dto.Product{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/products/", Summary: "Create a product", Access: openapi.Admin,
+		Request: openapi.JSON(models.Product{}), Status: http.StatusCreated,
+		Response: openapi.Data(dto.Product{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/products/:productId", Summary: "Update a product", Access: openapi.Admin,
+		Description: "Only the fields sent are changed. A stock value is recorded as an adjustment in the stock ledger.",
+		Request:     openapi.JSON(models.Product{}),
+		Response:    openapi.Data(dto.Product{}),
+	},
+	openapi.Operation{
+		Method: "DELETE", Path: "/api/v1/products/:productId", Summary: "Delete a product", Access: openapi.Admin,
+		Description: "Soft deletes the product and removes it from carts; order history keeps referring to it.",
+		Response:    openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/products/:productId/restore", Summary: "Restore a deleted product", Access: openapi.Admin,
+		Response: openapi.Data(dto.Product{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/products/import", Summary: "Import products", Access: openapi.Admin,
+		Description: "Upserts products by SKU from a CSV or NDJSON file, sent raw or as the file field of a form. " +
+			"Large files are imported in the background and answered with 202, the job_id and a status_url to poll.",
+		Query: []openapi.Param{openapi.Query("dry_run", false, "Only validate the file")},
+		Request: openapi.Content("text/csv", openapi.Text).
+			Or(openapi.Content("application/x-ndjson", openapi.Text)).
+			Or(openapi.Content("multipart/form-data", openapi.Object{"file": openapi.Binary})),
+		Response: openapi.Data(dto.ImportJob{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/products/import/:jobId", Summary: "Get an import job", Access: openapi.Admin,
+		Response: openapi.Data(dto.ImportJob{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/products/export", Summary: "Export products", Access: openapi.Admin,
+		Query:    []openapi.Param{openapi.Query("format", "", "csv (default) or ndjson")},
+		Response: openapi.Content("text/csv", openapi.Text).Or(openapi.Content("application/x-ndjson", openapi.Text)),
+	},
+)
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// ReportRoutes sets up the admin sales analytics endpoints. Every report
+// accepts from/to filters and format=csv for a CSV download.
+func ReportRoutes(incomingRoutes *gin.Engine, reportService *services.ReportService) {
+	reportRoutes := incomingRoutes.Group("/api/v1/admin/reports")
+	reportRoutes.Use(middlewares.CheckAdmin())
+	reportRoutes.GET("/revenue", controllers.AdminGetRevenueReport(reportService))
+	reportRoutes.GET("/orders-by-status", controllers.AdminGetOrdersByStatusReport(reportService))
+	reportRoutes.GET("/average-order-value", controllers.AdminGetAverageOrderValueReport(reportService))
+	reportRoutes.GET("/top-products", controllers.AdminGetTopProductsReport(reportService))
+	reportRoutes.GET("/top-categories", controllers.AdminGetTopCategoriesReport(reportService))
+	reportRoutes.GET("/customers", controllers.AdminGetCustomersReport(reportService))
+}
+
+var reportQuery = []openapi.Param{
+	openapi.Query("from", "", "Start date or RFC 3339 time"),
+	openapi.Query("to", "", "End date, inclusive, or RFC 3339 time, exclusive"),
+	openapi.Query("format", "", "csv to download the report as CSV"),
+}
+
+var intervalQuery = openapi.Query("interval", "", "day (default), week or month")
+
+var topQuery = []openapi.Param{
+	openapi.Query("by", "", "revenue (default) or units"),
+	openapi.Query("limit", 0, "Number of rows"),
+}
+
+// reportBody is the JSON report or its CSV download
+func reportBody(data interface{}) openapi.Body {
+	return openapi.Data(data).Or(openapi.Content("text/csv", openapi.Text))
+}
+
+var reportOperations = openapi.Tagged("Reports",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/reports/revenue", Summary: "Revenue per period", Access: openapi.Admin,
+		Query: append([]openapi.Param{intervalQuery}, reportQuery...), Response: reportBody([]repository.RevenueBucket{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/reports/orders-by-status", Summary: "Orders per status", Access: openapi.Admin,
+		Query: reportQuery, Response: reportBody([]repository.StatusCount{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/reports/average-order-value", Summary: "Average order value", Access: openapi.Admin,
+		Query: reportQuery, Response: reportBody(repository.AverageOrderValue{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/reports/top-products", Summary: "Best selling products", Access: openapi.Admin,
+		Query: append(topQuery, reportQuery...), Response: reportBody([]repository.TopProduct{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/reports/top-categories", Summary: "Best selling categories", Access: openapi.Admin,
+		Query: append(topQuery, reportQuery...), Response: reportBody([]repository.TopCategory{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/reports/customers", Summary: "New and returning customers per period",
+		Access: openapi.Admin, Query: append([]openapi.Param{intervalQuery}, reportQuery...),
+		Response: reportBody([]repository.CustomerBucket{}),
+	},
+)
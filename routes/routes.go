@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// Register sets up every API route. OpenAPIRoutes is registered separately,
+// after any other routes the server adds.
+func Register(router *gin.Engine, app *services.Services) {
+	AuthRoutes(router, app.Users)
+	ProductRoutes(router, app.Products)
+	CartRoutes(router, app.Carts)
+	OrderRoutes(router, app.Orders)
+	UserRoutes(router, app.Users, app.Notifications)
+	AuditRoutes(router, app.Audit)
+	ReportRoutes(router, app.Reports)
+	InventoryRoutes(router, app.Products)
+	WarehouseRoutes(router, app.Warehouses)
+	WebhookRoutes(router, app.Webhooks)
+	EmailRoutes(router, app.Notifications)
+	SigningKeyRoutes(router, app.SigningKeys)
+	APIKeyRoutes(router, app.APIKeys)
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+	"github.com/sajagsubedi/Ecommerce-Api/signing"
+)
+
+// SigningKeyRoutes publishes the JWKS and sets up admin key management
+func SigningKeyRoutes(incomingRoutes *gin.Engine, signingKeyService *services.SigningKeyService) {
+	incomingRoutes.GET("/.well-known/jwks.json", controllers.GetJWKS())
+
+	signingKeyRoutes := incomingRoutes.Group("/api/v1/admin/signing-keys")
+	signingKeyRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+	signingKeyRoutes.GET("/", controllers.AdminGetSigningKeys(signingKeyService))
+	signingKeyRoutes.POST("/rotate", controllers.AdminRotateSigningKey(signingKeyService))
+	signingKeyRoutes.POST("/:kid/revoke", controllers.AdminRevokeSigningKey(signingKeyService))
+}
+
+var signingKeyOperations = openapi.Tagged("Signing keys",
+	openapi.Operation{
+		Method: "GET", Path: "/.well-known/jwks.json", Summary: "Public keys access tokens are signed with",
+		Response: openapi.JSON(signing.JSONWebKeySet{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/signing-keys/", Summary: "List signing keys", Access: openapi.Admin,
+		Response: openapi.Data([]dto.SigningKey{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/signing-keys/rotate", Summary: "Add a new signing key", Access: openapi.Admin,
+		Description: "The key starts signing once the JWKS caches have had time to pick it up, or at once when immediate is set.",
+		Request:     openapi.JSON(controllers.RotateSigningKeyInput{}), Status: http.StatusCreated,
+		Response: openapi.Data(dto.SigningKey{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/signing-keys/:kid/revoke", Summary: "Revoke a signing key", Access: openapi.Admin,
+		Description: "Tokens signed with the key stop being accepted at once.",
+		Response:    openapi.Message(),
+	},
+)
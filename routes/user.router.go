@@ -3,21 +3,112 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	controller "github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
 	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
 )
 
-func UserRoutes(incomingRoutes *gin.Engine) {
+func UserRoutes(incomingRoutes *gin.Engine, userService *services.UserService, notificationService *services.NotificationService) {
 	authRoutes := incomingRoutes.Group("/api/v1/user")
 	authRoutes.Use(middlewares.CheckUser())
-	authRoutes.GET("/profile", controller.GetProfile())
-	authRoutes.PUT("/profile", controller.UpdateProfile())
-	authRoutes.POST("/change-password", controller.ChangePassword())
+	authRoutes.GET("/profile", controller.GetProfile(userService))
+	authRoutes.PUT("/profile", controller.UpdateProfile(userService))
+	authRoutes.POST("/change-password", controller.ChangePassword(userService))
+	authRoutes.GET("/notifications", controller.GetNotificationPreferences(notificationService))
+	authRoutes.PUT("/notifications", controller.UpdateNotificationPreferences(notificationService))
+	authRoutes.GET("/identities", controller.GetUserIdentities(userService))
+	authRoutes.POST("/2fa/enroll", controller.EnrollTwoFactor(userService))
+	authRoutes.POST("/2fa/confirm", controller.ConfirmTwoFactor(userService))
+	authRoutes.POST("/2fa/recovery-codes", controller.RegenerateRecoveryCodes(userService))
+	authRoutes.POST("/2fa/disable", controller.DisableTwoFactor(userService))
 
 	adminRoutes := incomingRoutes.Group("/api/v1/admin/users")
-	adminRoutes.Use(middlewares.CheckAdmin())
+	adminRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
 
-	adminRoutes.GET("/", controller.GetUsersByAdmin())
-	adminRoutes.GET("/:userId", controller.GetUserById())
-	adminRoutes.PUT("/:userId", controller.UpdateUserByAdmin())
-	adminRoutes.DELETE("/:userId", controller.DeleteUserByAdmin())
+	adminRoutes.GET("/", controller.GetUsersByAdmin(userService))
+	adminRoutes.GET("/deleted", controller.GetDeletedUsersByAdmin(userService))
+	adminRoutes.GET("/:userId", controller.GetUserById(userService))
+	adminRoutes.PUT("/:userId", controller.UpdateUserByAdmin(userService))
+	adminRoutes.DELETE("/:userId", controller.DeleteUserByAdmin(userService))
+	adminRoutes.PUT("/:userId/restore", controller.RestoreUserByAdmin(userService))
+	adminRoutes.POST("/:userId/2fa/reset", controller.AdminResetTwoFactor(userService))
 }
+
+var userOperations = openapi.Tagged("Users",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/user/profile", Summary: "Get the profile", Access: openapi.Authenticated,
+		Response: openapi.Data(dto.User{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/user/profile", Summary: "Update the profile", Access: openapi.Authenticated,
+		Request:  openapi.JSON(controller.UpdateProfileInput{}),
+		Response: openapi.Fields(openapi.Object{"user": openapi.Object{"id": uint(0), "name": "", "email": ""}}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/user/change-password", Summary: "Change the password", Access: openapi.Authenticated,
+		Description: "old_password is required unless has_password is false, as for users who signed up through an identity provider.",
+		Request:     openapi.JSON(controller.ChangePasswordInput{}), Response: openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/user/notifications", Summary: "Get email preferences", Access: openapi.Authenticated,
+		Response: openapi.Data(dto.NotificationPreference{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/user/notifications", Summary: "Update email preferences", Access: openapi.Authenticated,
+		Request:  openapi.JSON(controller.NotificationPreferenceInput{}),
+		Response: openapi.Data(dto.NotificationPreference{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/user/identities", Summary: "List linked identity providers", Access: openapi.Authenticated,
+		Response: openapi.Data([]dto.UserIdentity{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/user/2fa/enroll", Summary: "Start two-factor enrollment", Access: openapi.Authenticated,
+		Response: openapi.Data(openapi.Object{"secret": "", "otpauth_uri": ""}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/user/2fa/confirm", Summary: "Turn on two-factor authentication", Access: openapi.Authenticated,
+		Description: "Returns the recovery codes; they are not shown again.",
+		Request:     openapi.JSON(controller.TwoFactorCodeInput{}),
+		Response:    openapi.Data(openapi.Object{"recovery_codes": []string{}}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/user/2fa/recovery-codes", Summary: "Replace the recovery codes", Access: openapi.Authenticated,
+		Request:  openapi.JSON(controller.TwoFactorCodeInput{}),
+		Response: openapi.Data(openapi.Object{"recovery_codes": []string{}}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/user/2fa/disable", Summary: "Turn off two-factor authentication", Access: openapi.Authenticated,
+		Request: openapi.JSON(controller.TwoFactorDisableInput{}), Response: openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/users/", Summary: "List users", Access: openapi.Admin,
+		Response: openapi.Data([]dto.User{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/users/deleted", Summary: "List deleted users", Access: openapi.Admin,
+		Response: openapi.Data([]dto.User{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/users/:userId", Summary: "Get a user", Access: openapi.Admin,
+		Response: openapi.Data(dto.User{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/admin/users/:userId", Summary: "Update a user", Access: openapi.Admin,
+		Request:  openapi.JSON(controller.AdminUserInput{}),
+		Response: openapi.Fields(openapi.Object{"user": dto.User{}}),
+	},
+	openapi.Operation{
+		Method: "DELETE", Path: "/api/v1/admin/users/:userId", Summary: "Delete a user", Access: openapi.Admin,
+		Response: openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/admin/users/:userId/restore", Summary: "Restore a deleted user", Access: openapi.Admin,
+		Response: openapi.Fields(openapi.Object{"user": dto.User{}}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/users/:userId/2fa/reset", Summary: "Reset a user's two-factor authentication",
+		Access: openapi.Admin, Response: openapi.Message(),
+	},
+)
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// WarehouseRoutes sets up the admin warehouse and stock transfer endpoints
+func WarehouseRoutes(incomingRoutes *gin.Engine, warehouseService *services.WarehouseService) {
+	warehouseRoutes := incomingRoutes.Group("/api/v1/admin/warehouses")
+	warehouseRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+	warehouseRoutes.GET("/", controllers.AdminGetWarehouses(warehouseService))
+	warehouseRoutes.POST("/", middlewares.Idempotency(), controllers.AdminCreateWarehouse(warehouseService))
+	warehouseRoutes.POST("/transfers", middlewares.Idempotency(), controllers.AdminTransferStock(warehouseService))
+	warehouseRoutes.PUT("/:warehouseId", controllers.AdminUpdateWarehouse(warehouseService))
+	warehouseRoutes.GET("/:warehouseId/stock", controllers.AdminGetWarehouseStock(warehouseService))
+}
+
+var warehouseOperations = openapi.Tagged("Warehouses",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/warehouses/", Summary: "List warehouses", Access: openapi.Admin,
+		Response: openapi.Data([]dto.Warehouse{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/warehouses/", Summary: "Create a warehouse", Access: openapi.Admin,
+		Idempotent: true, Request: openapi.JSON(models.Warehouse{}), Status: http.StatusCreated,
+		Response: openapi.Data(dto.Warehouse{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/warehouses/transfers", Summary: "Move stock between warehouses", Access: openapi.Admin,
+		Idempotent: true, Request: openapi.JSON(controllers.StockTransferInput{}), Status: http.StatusCreated,
+		Response: openapi.Data([]dto.StockMovement{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/admin/warehouses/:warehouseId", Summary: "Update a warehouse", Access: openapi.Admin,
+		Request: openapi.JSON(controllers.WarehouseUpdateInput{}), Response: openapi.Data(dto.Warehouse{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/warehouses/:warehouseId/stock", Summary: "List the stock held in a warehouse",
+		Access: openapi.Admin, Response: openapi.Data([]dto.WarehouseStock{}),
+	},
+)
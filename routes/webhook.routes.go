@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sajagsubedi/Ecommerce-Api/controllers"
+	"github.com/sajagsubedi/Ecommerce-Api/dto"
+	"github.com/sajagsubedi/Ecommerce-Api/middlewares"
+	"github.com/sajagsubedi/Ecommerce-Api/openapi"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+)
+
+// WebhookRoutes sets up the admin webhook endpoints and delivery log
+func WebhookRoutes(incomingRoutes *gin.Engine, webhookService *services.WebhookService) {
+	webhookRoutes := incomingRoutes.Group("/api/v1/admin/webhooks")
+	webhookRoutes.Use(middlewares.CheckAdmin(), middlewares.AuditTrail())
+	webhookRoutes.GET("/", controllers.AdminGetWebhooks(webhookService))
+	webhookRoutes.POST("/", controllers.AdminCreateWebhook(webhookService))
+	webhookRoutes.GET("/events", controllers.AdminGetWebhookEventTypes())
+	webhookRoutes.GET("/deliveries", controllers.AdminGetWebhookDeliveries(webhookService))
+	webhookRoutes.POST("/deliveries/:deliveryId/redeliver", controllers.AdminRedeliverWebhook(webhookService))
+	webhookRoutes.PUT("/:webhookId", controllers.AdminUpdateWebhook(webhookService))
+	webhookRoutes.DELETE("/:webhookId", controllers.AdminDeleteWebhook(webhookService))
+	webhookRoutes.POST("/:webhookId/rotate-secret", controllers.AdminRotateWebhookSecret(webhookService))
+}
+
+var webhookOperations = openapi.Tagged("Webhooks",
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/webhooks/", Summary: "List webhooks", Access: openapi.Admin,
+		Response: openapi.Data([]dto.WebhookEndpoint{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/webhooks/", Summary: "Create a webhook", Access: openapi.Admin,
+		Description: "Returns the signing secret; it is not shown again.",
+		Request:     openapi.JSON(controllers.WebhookInput{}), Status: http.StatusCreated,
+		Response: openapi.Data(dto.WebhookEndpoint{}).With(openapi.Object{"secret": ""}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/webhooks/events", Summary: "List the event types webhooks can subscribe to",
+		Access: openapi.Admin, Response: openapi.Data([]string{}),
+	},
+	openapi.Operation{
+		Method: "GET", Path: "/api/v1/admin/webhooks/deliveries", Summary: "List webhook deliveries", Access: openapi.Admin,
+		Query: append([]openapi.Param{
+			openapi.Query("webhook_id", uint(0), "Only deliveries to this webhook"),
+			openapi.Query("status", "", "Only deliveries with this status"),
+			openapi.Query("event_type", "", "Only deliveries of this event type"),
+		}, openapi.PageQuery...),
+		Response: openapi.Page([]dto.WebhookDelivery{}),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/webhooks/deliveries/:deliveryId/redeliver", Summary: "Send a delivery again",
+		Access: openapi.Admin, Status: http.StatusAccepted, Response: openapi.Data(dto.WebhookDelivery{}),
+	},
+	openapi.Operation{
+		Method: "PUT", Path: "/api/v1/admin/webhooks/:webhookId", Summary: "Update a webhook", Access: openapi.Admin,
+		Request: openapi.JSON(controllers.WebhookInput{}), Response: openapi.Data(dto.WebhookEndpoint{}),
+	},
+	openapi.Operation{
+		Method: "DELETE", Path: "/api/v1/admin/webhooks/:webhookId", Summary: "Delete a webhook", Access: openapi.Admin,
+		Response: openapi.Message(),
+	},
+	openapi.Operation{
+		Method: "POST", Path: "/api/v1/admin/webhooks/:webhookId/rotate-secret", Summary: "Replace the signing secret of a webhook",
+		Access: openapi.Admin, Response: openapi.Fields(openapi.Object{"secret": ""}),
+	},
+)
@@ -0,0 +1,118 @@
+package seed
+
+// The word lists the demo data is drawn from. Changing them changes what a
+// seed produces, so append rather than reorder.
+
+var firstNames = []string{
+	"Aarav", "Olivia", "Liam", "Sita", "Noah", "Emma", "Kiran", "Ava", "Mateo", "Priya",
+	"Lucas", "Mia", "Ethan", "Anjali", "James", "Sofia", "Rohan", "Isabella", "Daniel", "Chloe",
+	"Arjun", "Grace", "Leo", "Hannah", "Samir", "Zoe", "Oscar", "Maya", "Felix", "Nora",
+}
+
+var lastNames = []string{
+	"Sharma", "Smith", "Garcia", "Thapa", "Johnson", "Müller", "Brown", "Rossi", "Khan", "Williams",
+	"Silva", "Nguyen", "Adhikari", "Martin", "Kim", "Lopez", "Wilson", "Gurung", "Taylor", "Dubois",
+}
+
+// address is a city with the state, country and postal code prefix used for it
+type address struct {
+	City    string
+	State   string
+	Country string
+	Zip     string
+}
+
+var addresses = []address{
+	{"Kathmandu", "Bagmati", "Nepal", "446"},
+	{"Pokhara", "Gandaki", "Nepal", "337"},
+	{"New York", "NY", "USA", "100"},
+	{"Austin", "TX", "USA", "787"},
+	{"Seattle", "WA", "USA", "981"},
+	{"London", "England", "UK", "EC1"},
+	{"Manchester", "England", "UK", "M1"},
+	{"Berlin", "Berlin", "Germany", "101"},
+	{"Toronto", "ON", "Canada", "M5V"},
+	{"Sydney", "NSW", "Australia", "200"},
+}
+
+var streets = []string{
+	"Main Street", "Oak Avenue", "Lakeside Road", "Station Road", "Park Lane",
+	"Hill Street", "Maple Drive", "Church Road", "Garden Path", "River Walk",
+}
+
+var deliveryNotes = []string{
+	"", "", "", "Leave at the front door", "Call on arrival", "Ring the bell twice", "Deliver after 5pm",
+}
+
+var carriers = []string{"UPS", "FedEx", "DHL", "USPS"}
+
+// category describes the products generated for one category
+type category struct {
+	Name       string
+	SKUPrefix  string
+	Adjectives []string
+	Nouns      []string
+	MinPrice   float64
+	MaxPrice   float64
+	// Attributes maps an attribute to the values a product picks from
+	Attributes map[string][]string
+}
+
+var categories = []category{
+	{
+		Name: "Electronics", SKUPrefix: "ELE",
+		Adjectives: []string{"Wireless", "Portable", "Smart", "Noise-Cancelling", "Compact", "Pro"},
+		Nouns:      []string{"Headphones", "Speaker", "Charger", "Keyboard", "Mouse", "Webcam", "Smartwatch"},
+		MinPrice:   15, MaxPrice: 400,
+		Attributes: map[string][]string{"color": {"Black", "White", "Silver", "Blue"}},
+	},
+	{
+		Name: "Clothing", SKUPrefix: "CLO",
+		Adjectives: []string{"Classic", "Slim-Fit", "Organic Cotton", "Vintage", "Everyday", "Linen"},
+		Nouns:      []string{"T-Shirt", "Hoodie", "Jeans", "Jacket", "Sweater", "Shorts"},
+		MinPrice:   12, MaxPrice: 150,
+		Attributes: map[string][]string{"size": {"S", "M", "L", "XL"}, "color": {"Navy", "Grey", "Olive", "Black"}},
+	},
+	{
+		Name: "Home & Kitchen", SKUPrefix: "HOM",
+		Adjectives: []string{"Stainless", "Ceramic", "Non-Stick", "Bamboo", "Cast Iron", "Glass"},
+		Nouns:      []string{"Frying Pan", "Mug Set", "Cutting Board", "Kettle", "Storage Jars", "Knife Block"},
+		MinPrice:   8, MaxPrice: 180,
+		Attributes: map[string][]string{"material": {"Steel", "Ceramic", "Wood", "Glass"}},
+	},
+	{
+		Name: "Books", SKUPrefix: "BOO",
+		Adjectives: []string{"The Quiet", "A Brief History of", "Learning", "The Art of", "Beyond", "Mastering"},
+		Nouns:      []string{"Mountains", "Go", "Cooking", "Time", "Gardening", "Photography"},
+		MinPrice:   6, MaxPrice: 60,
+		Attributes: map[string][]string{"format": {"Paperback", "Hardcover"}},
+	},
+	{
+		Name: "Sports", SKUPrefix: "SPO",
+		Adjectives: []string{"Lightweight", "Trail", "Training", "Adjustable", "Waterproof", "Foldable"},
+		Nouns:      []string{"Running Shoes", "Yoga Mat", "Dumbbells", "Water Bottle", "Backpack", "Tent"},
+		MinPrice:   10, MaxPrice: 250,
+		Attributes: map[string][]string{"color": {"Red", "Black", "Green", "Orange"}},
+	},
+	{
+		Name: "Beauty", SKUPrefix: "BEA",
+		Adjectives: []string{"Hydrating", "Gentle", "Herbal", "Vitamin C", "Fragrance-Free", "Daily"},
+		Nouns:      []string{"Face Cream", "Shampoo", "Sunscreen", "Lip Balm", "Serum", "Body Wash"},
+		MinPrice:   4, MaxPrice: 70,
+		Attributes: map[string][]string{"volume": {"50ml", "100ml", "250ml"}},
+	},
+	{
+		Name: "Toys", SKUPrefix: "TOY",
+		Adjectives: []string{"Wooden", "Educational", "Remote Control", "Plush", "Magnetic", "Classic"},
+		Nouns:      []string{"Puzzle", "Building Blocks", "Car", "Teddy Bear", "Train Set", "Board Game"},
+		MinPrice:   7, MaxPrice: 120,
+		Attributes: map[string][]string{"age": {"3+", "6+", "8+", "12+"}},
+	},
+	{
+		Name: "Grocery", SKUPrefix: "GRO",
+		Adjectives: []string{"Organic", "Himalayan", "Roasted", "Cold-Pressed", "Wildflower", "Whole Grain"},
+		Nouns:      []string{"Coffee Beans", "Green Tea", "Honey", "Olive Oil", "Pink Salt", "Oats"},
+		MinPrice:   3, MaxPrice: 40,
+		Attributes: map[string][]string{"weight": {"250g", "500g", "1kg"}},
+	},
+}
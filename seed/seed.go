@@ -0,0 +1,500 @@
+// Package seed fills a database with demo users, products, carts and orders.
+// Everything is generated from a fixed random seed, and record N of a kind
+// is derived from the seed and N alone, so a seed always produces the same
+// data and raising a volume only adds records.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/services"
+	"gorm.io/gorm"
+)
+
+// Options set what is generated
+type Options struct {
+	Seed     int64
+	Users    int
+	Products int
+	Orders   int
+	// Password is shared by every demo user so they can sign in
+	Password string
+}
+
+// DefaultOptions are enough to click through every screen of the API
+var DefaultOptions = Options{
+	Seed:     42,
+	Users:    25,
+	Products: 60,
+	Orders:   120,
+	Password: "password123",
+}
+
+// Report counts the records a run created; records that already existed
+// are left as they are
+type Report struct {
+	Users     int
+	Products  int
+	CartItems int
+	Orders    int
+}
+
+// orderStatuses are the statuses seeded orders end up in. The first orders
+// take each one in turn so every status is present.
+var orderStatuses = []string{
+	models.OrderStatusPending,
+	models.OrderStatusProcessing,
+	models.OrderStatusPartiallyShipped,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+	models.OrderStatusCancelled,
+}
+
+// statusWeights make most orders delivered, as in a shop that has been open
+// for a while
+var statusWeights = []int{10, 10, 5, 15, 50, 10}
+
+// seedEpoch is the day the demo data is dated from, so the same seed gives
+// the same timestamps whenever it is run
+var seedEpoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// restockQuantity is added to a product that runs out while orders are seeded
+const restockQuantity = 100
+
+// Run generates the demo data. It can be run again: users, products and
+// cart items that exist are skipped, and a user only gets the orders they
+// do not have yet.
+func Run(ctx context.Context, db *gorm.DB, app *services.Services, options Options) (Report, error) {
+	var report Report
+	// A partly shipped order needs two lines, so two products
+	if options.Users < 1 || options.Products < 2 || options.Orders < 0 {
+		return report, errors.New("seeding needs at least one user and two products, and no negative volumes")
+	}
+	if len(options.Password) < 6 {
+		return report, errors.New("the demo password needs at least 6 characters")
+	}
+	db = db.WithContext(ctx)
+
+	userIDs, err := seedUsers(db, options, &report)
+	if err != nil {
+		return report, fmt.Errorf("users: %w", err)
+	}
+	productIDs, err := seedProducts(ctx, db, app, options, &report)
+	if err != nil {
+		return report, fmt.Errorf("products: %w", err)
+	}
+	if err := seedOrders(ctx, db, app, options, userIDs, productIDs, &report); err != nil {
+		return report, fmt.Errorf("orders: %w", err)
+	}
+	if err := seedCarts(db, options, userIDs, productIDs, &report); err != nil {
+		return report, fmt.Errorf("carts: %w", err)
+	}
+	return report, nil
+}
+
+// random returns the generator of record index of kind
+func random(seed int64, kind string, index int) *rand.Rand {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d/%s/%d", seed, kind, index)
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+func pick[T any](r *rand.Rand, values []T) T {
+	return values[r.Intn(len(values))]
+}
+
+// seedUsers creates the demo customers with their carts and returns the
+// IDs of the active ones. The password is hashed once rather than per user,
+// which keeps large volumes fast.
+func seedUsers(db *gorm.DB, options Options, report *Report) ([]uint, error) {
+	hashed, err := (&models.User{Password: options.Password}).HashPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for i := 0; i < options.Users; i++ {
+		r := random(options.Seed, "user", i)
+		first, last := pick(r, firstNames), pick(r, lastNames)
+		email := fmt.Sprintf("%s.%s%d@example.com", asciiLower(first), asciiLower(last), i+1)
+
+		var user models.User
+		// Deleted demo users keep their email and get no new orders
+		err := db.Unscoped().Where("email = ?", email).First(&user).Error
+		if err == nil {
+			if !user.DeletedAt.Valid {
+				ids = append(ids, user.ID)
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		user = models.User{Name: first + " " + last, Email: email, Password: hashed, Role: "user"}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.Cart{UserID: user.ID}).Error; err != nil {
+				return err
+			}
+			// Demo customers have example.com addresses, so nothing is mailed
+			// for the orders seeded for them
+			preference := models.NotificationPreference{UserID: user.ID}
+			return tx.Select("UserID", "OrderEmails", "ShippingEmails").Create(&preference).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, user.ID)
+		report.Users++
+	}
+	return ids, nil
+}
+
+// asciiLower turns a name into the local part of an email address
+func asciiLower(name string) string {
+	replacer := strings.NewReplacer("ü", "u", "é", "e", " ", "")
+	return strings.ToLower(replacer.Replace(name))
+}
+
+// seedProducts creates the demo catalogue, recording the opening stock in
+// the ledger, and returns the IDs of the products that can be ordered
+func seedProducts(ctx context.Context, db *gorm.DB, app *services.Services, options Options, report *Report) ([]uint, error) {
+	var ids []uint
+	for i := 0; i < options.Products; i++ {
+		product := generateProduct(options.Seed, i)
+
+		var existing models.Product
+		err := db.Unscoped().Where("sku = ?", product.SKU).First(&existing).Error
+		if err == nil {
+			if !existing.DeletedAt.Valid && existing.IsAvailable {
+				ids = append(ids, existing.ID)
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		created, err := app.Products.Create(ctx, product, 0)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, created.ID)
+		report.Products++
+	}
+	return ids, nil
+}
+
+func generateProduct(seed int64, index int) models.Product {
+	r := random(seed, "product", index)
+	c := pick(r, categories)
+	name := pick(r, c.Adjectives) + " " + pick(r, c.Nouns)
+
+	attributes := models.Attributes{}
+	keys := make([]string, 0, len(c.Attributes))
+	for key := range c.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attributes[key] = pick(r, c.Attributes[key])
+	}
+
+	price := c.MinPrice + r.Float64()*(c.MaxPrice-c.MinPrice)
+	sku := fmt.Sprintf("%s-%05d", c.SKUPrefix, index+1)
+	return models.Product{
+		SKU:               sku,
+		Name:              name,
+		Description:       fmt.Sprintf("%s from our %s range.", name, strings.ToLower(c.Name)),
+		Price:             math.Floor(price) + 0.99,
+		Category:          c.Name,
+		ImageURL:          "https://picsum.photos/seed/" + strings.ToLower(sku) + "/600/600",
+		Attributes:        attributes,
+		Stock:             20 + r.Intn(300),
+		LowStockThreshold: 5 + r.Intn(10),
+		IsAvailable:       true,
+	}
+}
+
+// plannedOrder is a generated order before it is placed
+type plannedOrder struct {
+	input    services.CheckoutInput
+	status   string
+	placedAt time.Time
+	r        *rand.Rand
+}
+
+func planOrder(seed int64, index int, productIDs []uint) plannedOrder {
+	r := random(seed, "order", index)
+
+	status := orderStatuses[index%len(orderStatuses)]
+	if index >= len(orderStatuses) {
+		total := 0
+		for _, weight := range statusWeights {
+			total += weight
+		}
+		n := r.Intn(total)
+		for i, weight := range statusWeights {
+			if n < weight {
+				status = orderStatuses[i]
+				break
+			}
+			n -= weight
+		}
+	}
+
+	lines := 1 + r.Intn(4)
+	// A partly shipped order needs one line shipped and another left behind
+	if status == models.OrderStatusPartiallyShipped && lines < 2 {
+		lines = 2
+	}
+	if lines > len(productIDs) {
+		lines = len(productIDs)
+	}
+
+	var items []services.CheckoutItem
+	for _, p := range r.Perm(len(productIDs))[:lines] {
+		items = append(items, services.CheckoutItem{ProductID: productIDs[p], Quantity: 1 + r.Intn(3)})
+	}
+
+	place := pick(r, addresses)
+	return plannedOrder{
+		input: services.CheckoutInput{
+			ContactNumber: fmt.Sprintf("98%08d", r.Intn(100000000)),
+			ShippingAddress: models.ShippingAddress{
+				Street:  fmt.Sprintf("%d %s", 1+r.Intn(250), pick(r, streets)),
+				City:    place.City,
+				State:   place.State,
+				Country: place.Country,
+				ZipCode: fmt.Sprintf("%s%02d", place.Zip, r.Intn(100)),
+				Notes:   pick(r, deliveryNotes),
+			},
+			Items: items,
+		},
+		status:   status,
+		placedAt: seedEpoch.Add(-time.Duration(r.Intn(90*24)) * time.Hour),
+		r:        r,
+	}
+}
+
+// seedOrders places the demo orders. Order N belongs to user N modulo the
+// number of users, so a user's planned orders are known in advance and the
+// ones they already have are skipped.
+func seedOrders(ctx context.Context, db *gorm.DB, app *services.Services, options Options, userIDs, productIDs []uint, report *Report) error {
+	if len(userIDs) == 0 || len(productIDs) < 2 {
+		return errors.New("needs an active demo user and two active demo products to order with")
+	}
+	placed := map[uint]int64{}
+	for _, id := range userIDs {
+		var count int64
+		if err := db.Model(&models.Order{}).Where("user_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		placed[id] = count
+	}
+
+	for i := 0; i < options.Orders; i++ {
+		userID := userIDs[i%len(userIDs)]
+		if placed[userID] > 0 {
+			placed[userID]--
+			continue
+		}
+
+		plan := planOrder(options.Seed, i, productIDs)
+		if err := ensureStock(ctx, app, plan.input.Items); err != nil {
+			return err
+		}
+		order, err := app.Orders.Checkout(ctx, userID, plan.input)
+		if err != nil {
+			return err
+		}
+		if err := applyStatus(ctx, db, app, order, plan); err != nil {
+			return err
+		}
+
+		if err := db.Model(&models.Order{}).Where("id = ?", order.ID).UpdateColumn("created_at", plan.placedAt).Error; err != nil {
+			return err
+		}
+		report.Orders++
+	}
+	return nil
+}
+
+// ensureStock restocks products an order would run out of, so large
+// volumes do not fail on stock
+func ensureStock(ctx context.Context, app *services.Services, items []services.CheckoutItem) error {
+	for _, item := range items {
+		product, err := app.Products.Get(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		if product.Stock >= item.Quantity {
+			continue
+		}
+		_, err = app.Products.Update(ctx, product.ID, models.Product{Stock: product.Stock + restockQuantity}, 0)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyStatus moves a placed order to its planned status the way an admin
+// would, so stock and shipments match the status
+func applyStatus(ctx context.Context, db *gorm.DB, app *services.Services, order models.Order, plan plannedOrder) error {
+	switch plan.status {
+	case models.OrderStatusPending:
+		return nil
+	case models.OrderStatusPartiallyShipped:
+		return shipFirstLine(db, order, plan)
+	}
+
+	if plan.status == models.OrderStatusShipped || plan.status == models.OrderStatusDelivered {
+		return shipAll(ctx, app, order.ID, plan)
+	}
+	return app.Orders.UpdateStatus(ctx, order.ID, plan.status, 0)
+}
+
+// shipFirstLine ships the first line of an order on its own and leaves the
+// rest pending. It is shipped the day after the order was placed.
+func shipFirstLine(db *gorm.DB, order models.Order, plan plannedOrder) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var item models.ShipmentItem
+		err := tx.Joins("JOIN shipments ON shipments.id = shipment_items.shipment_id").
+			Where("shipments.order_id = ?", order.ID).Order("shipment_items.id").First(&item).Error
+		if err != nil {
+			return err
+		}
+		var shipment models.Shipment
+		if err := tx.Preload("Items").First(&shipment, item.ShipmentID).Error; err != nil {
+			return err
+		}
+
+		shippedAt := plan.placedAt.Add(24 * time.Hour)
+		carrier, number := tracking(plan.r)
+		ship := map[string]interface{}{
+			"status":          models.ShipmentStatusShipped,
+			"carrier":         carrier,
+			"tracking_number": number,
+			"tracking_url":    helpers.TrackingURL(carrier, number),
+			"shipped_at":      shippedAt,
+		}
+		// A line the order split off to another warehouse already has a
+		// shipment to itself
+		if len(shipment.Items) == 1 {
+			if err := tx.Model(&models.Shipment{ID: shipment.ID}).Updates(ship).Error; err != nil {
+				return err
+			}
+			return syncStatus(tx, order)
+		}
+
+		shipped := models.Shipment{
+			OrderID:        order.ID,
+			WarehouseID:    shipment.WarehouseID,
+			Status:         models.ShipmentStatusShipped,
+			Carrier:        carrier,
+			TrackingNumber: number,
+			TrackingURL:    helpers.TrackingURL(carrier, number),
+			ShippedAt:      &shippedAt,
+			Items:          []models.ShipmentItem{{OrderItemID: item.OrderItemID, ProductID: item.ProductID, Quantity: item.Quantity}},
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&shipped).Error; err != nil {
+			return err
+		}
+		return syncStatus(tx, order)
+	})
+}
+
+// syncStatus stores the order status derived from its shipments
+func syncStatus(tx *gorm.DB, order models.Order) error {
+	var shipments []models.Shipment
+	if err := tx.Where("order_id = ?", order.ID).Find(&shipments).Error; err != nil {
+		return err
+	}
+	status := helpers.DeriveOrderStatus(order.Status, shipments)
+	return tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("status", status).Error
+}
+
+// shipAll ships every shipment of an order with tracking and, for delivered
+// orders, delivers them
+func shipAll(ctx context.Context, app *services.Services, orderID uint, plan plannedOrder) error {
+	shipments, err := app.Orders.Shipments(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	for _, shipment := range shipments {
+		carrier, number := tracking(plan.r)
+		_, err := app.Orders.ShipShipment(ctx, orderID, shipment.ID, services.ShipInput{Carrier: carrier, TrackingNumber: number})
+		if err != nil {
+			return err
+		}
+		if plan.status != models.OrderStatusDelivered {
+			continue
+		}
+		if _, err := app.Orders.DeliverShipment(ctx, orderID, shipment.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tracking(r *rand.Rand) (carrier, number string) {
+	carrier = pick(r, carriers)
+	return carrier, fmt.Sprintf("%s%012d", strings.ToUpper(carrier[:2]), r.Int63n(1e12))
+}
+
+// seedCarts leaves items in the carts of every third user. Carts are filled
+// last because they reflect what customers are shopping for now.
+func seedCarts(db *gorm.DB, options Options, userIDs, productIDs []uint, report *Report) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	for i, userID := range userIDs {
+		if i%3 != 0 {
+			continue
+		}
+		r := random(options.Seed, "cart", i)
+
+		var cart models.Cart
+		if err := db.Where("user_id = ?", userID).FirstOrCreate(&cart, models.Cart{UserID: userID}).Error; err != nil {
+			return err
+		}
+
+		lines := 1 + r.Intn(3)
+		if lines > len(productIDs) {
+			lines = len(productIDs)
+		}
+		for _, p := range r.Perm(len(productIDs))[:lines] {
+			quantity := 1 + r.Intn(2)
+			var count int64
+			if err := db.Model(&models.CartItem{}).Where("cart_id = ? AND product_id = ?", cart.ID, productIDs[p]).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			item := models.CartItem{CartID: cart.ID, ProductID: productIDs[p], Quantity: quantity}
+			if err := db.Omit("Product").Create(&item).Error; err != nil {
+				return err
+			}
+			report.CartItems++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// AuditService reads the audit log of admin changes
+type AuditService struct {
+	store repository.Store
+}
+
+func NewAuditService(store repository.Store) *AuditService {
+	return &AuditService{store: store}
+}
+
+// Logs returns a page of audit log entries, newest first, with the number
+// of entries matching filter
+func (s *AuditService) Logs(ctx context.Context, filter repository.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error) {
+	logs, total, err := s.store.AuditLogs().List(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apperror.Internal("Failed to fetch audit logs").WithCause(err)
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/oidc"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// Session is the outcome of a sign-in whose first factor checked out. Users
+// with two-factor authentication get a ChallengeToken to pass to
+// VerifyTwoFactor, everyone else gets a Token.
+type Session struct {
+	User           models.User
+	Token          string
+	ChallengeToken string
+	// RecoveryCodesRemaining is set once a second factor was verified
+	RecoveryCodesRemaining int64
+}
+
+// AccountLockedError is returned while repeated failures lock an account.
+// It unwraps to the *apperror.Error sent to the client.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+	err        *apperror.Error
+}
+
+func (e *AccountLockedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *AccountLockedError) Unwrap() error {
+	return e.err
+}
+
+func accountLocked(retryAfter time.Duration) error {
+	return &AccountLockedError{
+		RetryAfter: retryAfter,
+		err: apperror.TooManyRequests("Too many failed sign-in attempts, please try again later").
+			WithCode(apperror.CodeAccountLocked),
+	}
+}
+
+// Identity is who an identity provider says signed in
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// TwoFactorEnrollment is the secret to add to an authenticator app
+type TwoFactorEnrollment struct {
+	Secret     string
+	OTPAuthURI string
+}
+
+func invalidTwoFactorCode() *apperror.Error {
+	return apperror.BadRequest("Invalid two-factor code")
+}
+
+// SignIn checks a password. Repeated wrong passwords lock the account for
+// progressively longer.
+func (s *UserService) SignIn(ctx context.Context, email, password string) (Session, error) {
+	user, err := s.store.Users().FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return Session{}, apperror.Unauthorized("Invalid credentials!")
+	}
+	if err != nil {
+		return Session{}, apperror.Internal("Internal Server Error").WithCause(err)
+	}
+
+	if locked := helpers.LockedFor(user); locked > 0 {
+		return Session{}, accountLocked(locked)
+	}
+	if !user.ComparePassword(password) {
+		return Session{}, s.failedSignIn(ctx, &user, apperror.Unauthorized("Invalid credentials!"))
+	}
+	return s.startSession(ctx, user)
+}
+
+// SignInWithIdentity signs in the user an identity belongs to. The identity
+// is matched to a linked user, then to a user with the same verified email,
+// and otherwise a new user is created.
+func (s *UserService) SignInWithIdentity(ctx context.Context, identity Identity) (Session, error) {
+	var user models.User
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		user, err = findOrCreateIdentityUser(ctx, tx, identity)
+		return err
+	})
+	if err != nil {
+		return Session{}, clientError(err, "Failed to complete sign-in")
+	}
+	return s.startSession(ctx, user)
+}
+
+// VerifyTwoFactor exchanges the challenge token from a sign-in and either an
+// authenticator code or a recovery code for a session. Wrong codes count
+// towards the account lockout.
+func (s *UserService) VerifyTwoFactor(ctx context.Context, challengeToken, code, recoveryCode string) (Session, error) {
+	userID, err := helpers.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return Session{}, apperror.Unauthorized(err.Error())
+	}
+
+	user, err := s.store.Users().FindByID(ctx, userID)
+	if err != nil || !user.TwoFactorEnabled {
+		return Session{}, apperror.Unauthorized("Invalid or expired challenge token").WithCode(apperror.CodeInvalidToken)
+	}
+	if locked := helpers.LockedFor(user); locked > 0 {
+		return Session{}, accountLocked(locked)
+	}
+
+	var ok bool
+	if code != "" {
+		ok, err = useTOTPCode(ctx, s.store.Users(), &user, code)
+	} else {
+		ok, err = s.store.Users().UseRecoveryCode(ctx, user.ID, helpers.HashRecoveryCode(recoveryCode))
+	}
+	if err != nil {
+		return Session{}, apperror.Internal("Failed to verify two-factor code").WithCause(err)
+	}
+	if !ok {
+		return Session{}, s.failedSignIn(ctx, &user, apperror.Unauthorized("Invalid two-factor code"))
+	}
+
+	session, err := s.issueToken(ctx, user)
+	if err != nil {
+		return Session{}, err
+	}
+	session.RecoveryCodesRemaining, err = s.store.Users().CountRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		log.Printf("Failed to count recovery codes: %v", err)
+	}
+	return session, nil
+}
+
+// startSession signs in a user whose password or identity provider checked
+// out
+func (s *UserService) startSession(ctx context.Context, user models.User) (Session, error) {
+	// The failures are only forgiven once the second factor is passed too,
+	// so knowing the password does not allow unlimited code guesses
+	if user.TwoFactorEnabled {
+		challenge, err := helpers.GenerateChallengeToken(user.ID)
+		if err != nil {
+			return Session{}, apperror.Internal("Something went wrong!").WithCause(err)
+		}
+		return Session{User: user, ChallengeToken: challenge}, nil
+	}
+	return s.issueToken(ctx, user)
+}
+
+// issueToken forgives earlier failures and hands out a session token
+func (s *UserService) issueToken(ctx context.Context, user models.User) (Session, error) {
+	if err := s.store.Users().ResetFailedLogins(ctx, &user); err != nil {
+		log.Printf("Failed to reset failed sign-ins: %v", err)
+	}
+	token, err := helpers.GenerateToken(user.ID, user.Role)
+	if err != nil {
+		return Session{}, apperror.Internal("Something went wrong!").WithCause(err)
+	}
+	return Session{User: user, Token: token}, nil
+}
+
+// failedSignIn counts a failed sign-in and returns err, or the lockout it
+// earned
+func (s *UserService) failedSignIn(ctx context.Context, user *models.User, err *apperror.Error) error {
+	if recordErr := s.store.Users().AddFailedLogin(ctx, user); recordErr != nil {
+		log.Printf("Failed to record failed sign-in: %v", recordErr)
+		return err
+	}
+	lockout := s.lockout.LockoutFor(user.FailedLogins)
+	if lockout == 0 {
+		return err
+	}
+	if lockErr := s.store.Users().LockUntil(ctx, user, time.Now().Add(lockout)); lockErr != nil {
+		log.Printf("Failed to record failed sign-in: %v", lockErr)
+	}
+	return accountLocked(lockout)
+}
+
+// useTOTPCode accepts a code at most once per time step, so an observed code
+// cannot be replayed
+func useTOTPCode(ctx context.Context, users repository.UserRepository, user *models.User, code string) (bool, error) {
+	step, ok := helpers.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return users.UseTOTPStep(ctx, user, step)
+}
+
+// replaceRecoveryCodes gives the user a new set of recovery codes. Only the
+// hashes are stored.
+func replaceRecoveryCodes(ctx context.Context, users repository.UserRepository, userID uint) ([]string, error) {
+	codes, err := helpers.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = helpers.HashRecoveryCode(code)
+	}
+	return codes, users.ReplaceRecoveryCodes(ctx, userID, hashes)
+}
+
+// StartTwoFactorEnrollment generates a new secret for the user. It takes
+// effect once ConfirmTwoFactor sees a code from it.
+func (s *UserService) StartTwoFactorEnrollment(ctx context.Context, id uint) (TwoFactorEnrollment, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+	if user.TwoFactorEnabled {
+		return TwoFactorEnrollment{}, apperror.Conflict("Two-factor authentication is already enabled")
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return TwoFactorEnrollment{}, apperror.Internal("Failed to generate secret").WithCause(err)
+	}
+	if err := s.store.Users().SetTOTPSecret(ctx, &user, secret); err != nil {
+		return TwoFactorEnrollment{}, apperror.Internal("Failed to start enrollment").WithCause(err)
+	}
+	return TwoFactorEnrollment{Secret: secret, OTPAuthURI: helpers.TOTPURI(user.Email, secret)}, nil
+}
+
+// ConfirmTwoFactor turns two-factor authentication on once the user proves
+// their authenticator works, and returns the recovery codes
+func (s *UserService) ConfirmTwoFactor(ctx context.Context, id uint, code string) ([]string, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, apperror.Conflict("Two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, apperror.BadRequest("Start enrollment first")
+	}
+
+	var codes []string
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		ok, err := useTOTPCode(ctx, tx.Users(), &user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidTwoFactorCode()
+		}
+		if err := tx.Users().EnableTwoFactor(ctx, &user); err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(ctx, tx.Users(), user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, clientError(err, "Failed to enable two-factor authentication")
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, id uint, code string) ([]string, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, apperror.BadRequest("Two-factor authentication is not enabled")
+	}
+
+	var codes []string
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		ok, err := useTOTPCode(ctx, tx.Users(), &user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidTwoFactorCode()
+		}
+		codes, err = replaceRecoveryCodes(ctx, tx.Users(), user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, clientError(err, "Failed to regenerate recovery codes")
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off for a user who gives
+// their password and a current code
+func (s *UserService) DisableTwoFactor(ctx context.Context, id uint, password, code string) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return apperror.BadRequest("Two-factor authentication is not enabled")
+	}
+	if !user.ComparePassword(password) {
+		return apperror.Unauthorized("Invalid credentials!")
+	}
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		ok, err := useTOTPCode(ctx, tx.Users(), &user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidTwoFactorCode()
+		}
+		return tx.Users().DisableTwoFactor(ctx, &user)
+	})
+	if err != nil {
+		return clientError(err, "Failed to disable two-factor authentication")
+	}
+	return nil
+}
+
+// ResetTwoFactor turns two-factor authentication off for a user who lost
+// their authenticator and recovery codes
+func (s *UserService) ResetTwoFactor(ctx context.Context, id uint) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		return tx.Users().DisableTwoFactor(ctx, &user)
+	})
+	if err != nil {
+		return apperror.Internal("Failed to reset two-factor authentication").WithCause(err)
+	}
+	return nil
+}
+
+// Identities lists the identity providers linked to a user
+func (s *UserService) Identities(ctx context.Context, id uint) ([]models.UserIdentity, error) {
+	identities, err := s.store.Users().ListIdentities(ctx, id)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch identities").WithCause(err)
+	}
+	return identities, nil
+}
+
+// StartOIDCLogin records a sign-in sent to an identity provider, to be
+// finished by FinishOIDCLogin
+func (s *UserService) StartOIDCLogin(ctx context.Context, login *models.OIDCLoginState) error {
+	if err := s.store.Users().CreateOIDCLogin(ctx, login); err != nil {
+		return apperror.Internal("Failed to start sign-in").WithCause(err)
+	}
+	return nil
+}
+
+// FinishOIDCLogin takes the sign-in started with state at provider. Each
+// sign-in can only be finished once, before it expires.
+func (s *UserService) FinishOIDCLogin(ctx context.Context, provider, state string) (models.OIDCLoginState, error) {
+	login, err := s.store.Users().TakeOIDCLogin(ctx, state)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return models.OIDCLoginState{}, apperror.Internal("Failed to complete sign-in").WithCause(err)
+	}
+	if err != nil || login.Provider != provider || time.Now().After(login.ExpiresAt) {
+		return models.OIDCLoginState{}, apperror.BadRequest("Invalid or expired sign-in state")
+	}
+	return login, nil
+}
+
+// findOrCreateIdentityUser returns the user an identity belongs to, linking
+// or creating one when the identity is new
+func findOrCreateIdentityUser(ctx context.Context, tx repository.Store, identity Identity) (models.User, error) {
+	linked, err := tx.Users().FindIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := tx.Users().FindByID(ctx, linked.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.User{}, apperror.Forbidden("the account has been deleted")
+		}
+		if err != nil {
+			return models.User{}, err
+		}
+		return user, tx.Users().TouchIdentity(ctx, &linked)
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return models.User{}, err
+	}
+
+	// Linking by email is only safe when the provider vouches for it
+	email := strings.TrimSpace(identity.Email)
+	if email == "" || !identity.EmailVerified {
+		return models.User{}, apperror.Forbidden("the provider did not verify the email address")
+	}
+
+	user, err := tx.Users().FindAnyByEmail(ctx, email)
+	switch {
+	case err == nil && user.DeletedAt.Valid:
+		return models.User{}, apperror.Forbidden("the account has been deleted")
+	case errors.Is(err, repository.ErrNotFound):
+		if user, err = createIdentityUser(ctx, tx, email, identity.Name); err != nil {
+			return models.User{}, err
+		}
+	case err != nil:
+		return models.User{}, err
+	}
+
+	return user, tx.Users().CreateIdentity(ctx, &models.UserIdentity{
+		UserID:      user.ID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       email,
+		LastLoginAt: time.Now(),
+	})
+}
+
+// createIdentityUser registers a user who signed up through a provider. They
+// get an unguessable password and can set their own through the password
+// change flow once signed in, without giving an old one.
+func createIdentityUser(ctx context.Context, tx repository.Store, email, name string) (models.User, error) {
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	password, err := oidc.RandomString()
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user := models.User{Name: name, Email: email, Password: password, Role: "user", PasswordUnset: true}
+	hashedPassword, err := user.HashPassword()
+	if err != nil {
+		return models.User{}, err
+	}
+	user.Password = hashedPassword
+
+	if err := tx.Users().Create(ctx, &user); err != nil {
+		return models.User{}, fmt.Errorf("create user: %w", err)
+	}
+	cart := models.Cart{UserID: user.ID, Items: []models.CartItem{}}
+	if err := tx.Carts().Create(ctx, &cart); err != nil {
+		return models.User{}, fmt.Errorf("create cart: %w", err)
+	}
+	return user, tx.PublishEvent(ctx, helpers.EventUserCreated, helpers.NewUserEventData(user))
+}
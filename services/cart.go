@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// CartService manages the cart of each customer
+type CartService struct {
+	store repository.Store
+}
+
+func NewCartService(store repository.Store) *CartService {
+	return &CartService{store: store}
+}
+
+// Get returns the cart of a user with its products
+func (s *CartService) Get(ctx context.Context, userID uint) (models.Cart, error) {
+	cart, err := s.store.Carts().FindByUser(ctx, userID)
+	if err != nil {
+		return models.Cart{}, lookupError(err, "Cart not found")
+	}
+	return cart, nil
+}
+
+// AddItem puts quantity of a product in the cart of a user, on top of what
+// is already there, and returns the updated cart
+func (s *CartService) AddItem(ctx context.Context, userID, productID uint, quantity int) (models.Cart, error) {
+	if _, err := s.store.Products().FindByID(ctx, productID); err != nil {
+		return models.Cart{}, lookupError(err, "Product not found")
+	}
+
+	cart, err := s.Get(ctx, userID)
+	if err != nil {
+		return models.Cart{}, err
+	}
+
+	item, err := s.store.Carts().FindItemByProduct(ctx, cart.ID, productID)
+	switch {
+	case err == nil:
+		item.Quantity += quantity
+	case errors.Is(err, repository.ErrNotFound):
+		item = models.CartItem{CartID: cart.ID, ProductID: productID, Quantity: quantity}
+	default:
+		return models.Cart{}, apperror.Internal("Database error").WithCause(err)
+	}
+	if err := s.store.Carts().SaveItem(ctx, &item); err != nil {
+		return models.Cart{}, apperror.Internal("Failed to add cart item").WithCause(err)
+	}
+
+	cart, err = s.store.Carts().FindByUser(ctx, userID)
+	if err != nil {
+		return models.Cart{}, apperror.Internal("Failed to fetch updated cart").WithCause(err)
+	}
+	return cart, nil
+}
+
+// UpdateItemQuantity sets the quantity of an item in the cart of a user
+func (s *CartService) UpdateItemQuantity(ctx context.Context, userID, itemID uint, quantity int) (models.CartItem, error) {
+	item, err := s.ownedItem(ctx, userID, itemID)
+	if err != nil {
+		return models.CartItem{}, err
+	}
+
+	item.Quantity = quantity
+	if err := s.store.Carts().SaveItem(ctx, &item); err != nil {
+		return models.CartItem{}, apperror.Internal("Failed to update cart item").WithCause(err)
+	}
+	return item, nil
+}
+
+// RemoveItem takes an item out of the cart of a user
+func (s *CartService) RemoveItem(ctx context.Context, userID, itemID uint) error {
+	item, err := s.ownedItem(ctx, userID, itemID)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Carts().DeleteItem(ctx, &item); err != nil {
+		return apperror.Internal("Failed to delete cart item").WithCause(err)
+	}
+	return nil
+}
+
+// ownedItem returns a cart item, refusing items of other users' carts
+func (s *CartService) ownedItem(ctx context.Context, userID, itemID uint) (models.CartItem, error) {
+	item, err := s.store.Carts().FindItem(ctx, itemID)
+	if err != nil {
+		return models.CartItem{}, lookupError(err, "Cart item not found")
+	}
+
+	cart, err := s.store.Carts().FindByUser(ctx, userID)
+	if err != nil || cart.ID != item.CartID {
+		return models.CartItem{}, apperror.Forbidden("Unauthorized access to cart item")
+	}
+	return item, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/oidc"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// identityStore keeps users and their linked identities in memory
+type identityStore struct {
+	repository.Store
+	users      []models.User
+	identities []models.UserIdentity
+}
+
+func (s *identityStore) Users() repository.UserRepository { return identityUsers{store: s} }
+
+func (s *identityStore) Transaction(ctx context.Context, fn func(tx repository.Store) error) error {
+	return fn(s)
+}
+
+type identityUsers struct {
+	repository.UserRepository
+	store *identityStore
+}
+
+func (r identityUsers) FindByID(ctx context.Context, id uint) (models.User, error) {
+	for _, user := range r.store.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+func (r identityUsers) FindAnyByEmail(ctx context.Context, email string) (models.User, error) {
+	for _, user := range r.store.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+func (r identityUsers) FindIdentity(ctx context.Context, provider, subject string) (models.UserIdentity, error) {
+	for _, identity := range r.store.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return models.UserIdentity{}, repository.ErrNotFound
+}
+
+func (r identityUsers) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	identity.ID = uint(len(r.store.identities) + 1)
+	r.store.identities = append(r.store.identities, *identity)
+	return nil
+}
+
+func (r identityUsers) TouchIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	return nil
+}
+
+// mockSignIn signs in at a mock identity provider served over HTTP, passing
+// query to its authorization endpoint, and returns who it says signed in
+func mockSignIn(t *testing.T, query url.Values) Identity {
+	t.Helper()
+	ctx := context.Background()
+
+	var mock *oidc.MockProvider
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	mock, err := oidc.NewMockProvider(server.URL, "shop", "shop-secret")
+	if err != nil {
+		t.Fatalf("NewMockProvider: %v", err)
+	}
+	provider := &oidc.Provider{
+		Name:         "mock",
+		Issuer:       server.URL,
+		ClientID:     "shop",
+		ClientSecret: "shop-secret",
+		RedirectURL:  "https://shop.example/callback",
+		Scopes:       []string{"openid", "email"},
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL + "&" + query.Encode())
+	if err != nil {
+		t.Fatalf("GET authorize: %v", err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+
+	claims, err := provider.Exchange(ctx, callback.Query().Get("code"), "verifier", "nonce")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	return Identity{
+		Provider:      provider.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}
+}
+
+func TestIdentityLinksUserByVerifiedEmail(t *testing.T) {
+	store := &identityStore{users: []models.User{{ID: 3, Email: "ada@example.com", Role: "user"}}}
+	identity := mockSignIn(t, url.Values{"email": {"Ada@Example.com"}})
+
+	user, err := findOrCreateIdentityUser(context.Background(), store, identity)
+	if err != nil {
+		t.Fatalf("findOrCreateIdentityUser: %v", err)
+	}
+	if user.ID != 3 {
+		t.Errorf("signed in user %d, want the existing user 3", user.ID)
+	}
+	if len(store.identities) != 1 {
+		t.Fatalf("%d identities linked, want 1", len(store.identities))
+	}
+	if linked := store.identities[0]; linked.UserID != 3 || linked.Provider != "mock" || linked.Subject != identity.Subject {
+		t.Errorf("linked %+v, want mock subject %q on user 3", linked, identity.Subject)
+	}
+
+	// The next sign-in is matched by the linked identity
+	user, err = findOrCreateIdentityUser(context.Background(), store, identity)
+	if err != nil || user.ID != 3 || len(store.identities) != 1 {
+		t.Errorf("second sign-in returned user %d, %v with %d identities, want user 3 and no new identity", user.ID, err, len(store.identities))
+	}
+}
+
+func TestIdentityRejectsUnverifiedEmail(t *testing.T) {
+	store := &identityStore{users: []models.User{{ID: 3, Email: "ada@example.com", Role: "user"}}}
+	identity := mockSignIn(t, url.Values{"email": {"ada@example.com"}, "email_verified": {"false"}})
+
+	_, err := findOrCreateIdentityUser(context.Background(), store, identity)
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Status != http.StatusForbidden {
+		t.Fatalf("findOrCreateIdentityUser returned %v, want forbidden", err)
+	}
+	if len(store.identities) != 0 {
+		t.Errorf("%d identities linked, want none", len(store.identities))
+	}
+}
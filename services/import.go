@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+// CreateImportJob records a product import before its rows are processed
+func (s *ProductService) CreateImportJob(ctx context.Context, job *models.ImportJob) error {
+	if err := s.store.Products().CreateImportJob(ctx, job); err != nil {
+		return apperror.Internal("Failed to create import job").WithCause(err)
+	}
+	return nil
+}
+
+// ImportJob returns the status and row errors of an import job
+func (s *ProductService) ImportJob(ctx context.Context, id uint) (models.ImportJob, error) {
+	job, err := s.store.Products().FindImportJob(ctx, id)
+	if err != nil {
+		return models.ImportJob{}, lookupError(err, "Import job not found")
+	}
+	return job, nil
+}
+
+// Export calls fn with the whole catalog, size products at a time in id
+// order. Errors returned by fn are passed on unchanged.
+func (s *ProductService) Export(ctx context.Context, size int, fn func(products []models.Product) error) error {
+	return s.store.Products().EachBatch(ctx, size, fn)
+}
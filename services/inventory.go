@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// AdjustStock records a manual stock movement for a product. Without a
+// warehouse the default one is adjusted.
+func (s *ProductService) AdjustStock(ctx context.Context, change helpers.StockChange) (models.StockMovement, error) {
+	var movement *models.StockMovement
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		movement, err = tx.Products().AdjustStock(ctx, change)
+		return err
+	})
+	switch {
+	case errors.Is(err, helpers.ErrInsufficientStock):
+		return models.StockMovement{}, apperror.BadRequest("Stock cannot go below zero").WithCode(apperror.CodeInsufficientStock)
+	case errors.Is(err, helpers.ErrNoDefaultWarehouse):
+		return models.StockMovement{}, apperror.BadRequest("No default warehouse configured")
+	case err != nil:
+		return models.StockMovement{}, lookupError(err, "Product not found")
+	}
+	return *movement, nil
+}
+
+// Movements returns a page of a product's ledger, newest first, with the
+// number of movements in it
+func (s *ProductService) Movements(ctx context.Context, productID uint, reason string, page, limit int) ([]models.StockMovement, int64, error) {
+	movements, total, err := s.store.Products().ListMovements(ctx, productID, reason, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apperror.Internal("Failed to fetch stock movements").WithCause(err)
+	}
+	return movements, total, nil
+}
+
+// LowStock lists the products at or below their low-stock threshold
+func (s *ProductService) LowStock(ctx context.Context) ([]models.Product, error) {
+	products, err := s.store.Products().ListLowStock(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch products").WithCause(err)
+	}
+	return products, nil
+}
+
+// ReconcileStock compares every product's stock, and every warehouse stock
+// level, with the sum of its ledger. With apply, drifting values are reset
+// to the ledger value in the same transaction the report is taken in.
+func (s *ProductService) ReconcileStock(ctx context.Context, apply bool) ([]helpers.StockDrift, []helpers.WarehouseStockDrift, error) {
+	var (
+		drifts          []helpers.StockDrift
+		warehouseDrifts []helpers.WarehouseStockDrift
+	)
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		drifts, warehouseDrifts, err = tx.Products().ReconcileStock(ctx, apply)
+		return err
+	})
+	if err != nil {
+		return nil, nil, apperror.Internal("Failed to reconcile stock").WithCause(err)
+	}
+	return drifts, warehouseDrifts, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+)
+
+// Document returns an order with everything printed on its invoice and
+// packing slip. A non-zero userID restricts it to that customer's orders.
+func (s *OrderService) Document(ctx context.Context, orderID, userID uint) (models.Order, error) {
+	order, err := s.store.Orders().FindForDocument(ctx, orderID)
+	if err != nil {
+		return models.Order{}, lookupError(err, "Order not found")
+	}
+	if userID != 0 && order.UserID != userID {
+		return models.Order{}, apperror.NotFound("Order not found")
+	}
+	return order, nil
+}
+
+// Invoice returns the invoice of an order with the order it is printed
+// from, issuing its number on first use
+func (s *OrderService) Invoice(ctx context.Context, orderID, userID uint, branding helpers.StoreBranding) (models.Invoice, models.Order, error) {
+	order, err := s.Document(ctx, orderID, userID)
+	if err != nil {
+		return models.Invoice{}, models.Order{}, err
+	}
+	if order.Status == models.OrderStatusCancelled {
+		return models.Invoice{}, models.Order{}, apperror.BadRequest("Invoices are not issued for cancelled orders")
+	}
+
+	invoice, err := s.store.Orders().FindOrIssueInvoice(ctx, order.ID, branding.FormatInvoiceNumber)
+	if err != nil {
+		return models.Invoice{}, models.Order{}, apperror.Internal("Failed to issue invoice").WithCause(err)
+	}
+	return invoice, order, nil
+}
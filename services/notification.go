@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// NotificationChanges holds the emails a user turns on or off; nil fields
+// are left as they are
+type NotificationChanges struct {
+	OrderEmails    *bool
+	ShippingEmails *bool
+}
+
+// NotificationService manages the emails users receive and shows admins the
+// mail queue
+type NotificationService struct {
+	store repository.Store
+}
+
+func NewNotificationService(store repository.Store) *NotificationService {
+	return &NotificationService{store: store}
+}
+
+// Preferences returns the email preferences of a user
+func (s *NotificationService) Preferences(ctx context.Context, userID uint) (models.NotificationPreference, error) {
+	preference, err := s.store.Notifications().FindPreference(ctx, userID)
+	if err != nil {
+		return models.NotificationPreference{}, apperror.Internal("Failed to fetch notification preferences").WithCause(err)
+	}
+	return preference, nil
+}
+
+// UpdatePreferences changes the email preferences of a user
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uint, changes NotificationChanges) (models.NotificationPreference, error) {
+	preference, err := s.Preferences(ctx, userID)
+	if err != nil {
+		return models.NotificationPreference{}, err
+	}
+	if changes.OrderEmails != nil {
+		preference.OrderEmails = *changes.OrderEmails
+	}
+	if changes.ShippingEmails != nil {
+		preference.ShippingEmails = *changes.ShippingEmails
+	}
+
+	if err := s.store.Notifications().SavePreference(ctx, &preference); err != nil {
+		return models.NotificationPreference{}, apperror.Internal("Failed to update notification preferences").WithCause(err)
+	}
+	return preference, nil
+}
+
+// Emails returns a page of the mail queue, newest first, with the number of
+// emails matching filter
+func (s *NotificationService) Emails(ctx context.Context, filter repository.EmailFilter, page, limit int) ([]models.EmailMessage, int64, error) {
+	emails, total, err := s.store.Notifications().ListEmails(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apperror.Internal("Failed to fetch emails").WithCause(err)
+	}
+	return emails, total, nil
+}
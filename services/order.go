@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// CheckoutInput is what a customer orders and where it is shipped
+type CheckoutInput struct {
+	ShippingAddress models.ShippingAddress
+	ContactNumber   string
+	Items           []CheckoutItem
+}
+
+// CheckoutItem is a quantity of one product to order
+type CheckoutItem struct {
+	ProductID uint
+	Quantity  int
+}
+
+// OrderService places orders and moves them through their statuses
+type OrderService struct {
+	store repository.Store
+}
+
+func NewOrderService(store repository.Store) *OrderService {
+	return &OrderService{store: store}
+}
+
+func insufficientStock() *apperror.Error {
+	return apperror.BadRequest("Insufficient stock for product").WithCode(apperror.CodeInsufficientStock)
+}
+
+// Checkout places an order for the products in input. Prices and product
+// details are copied onto the order, the warehouses that ship it are picked
+// and their stock is taken, all in one transaction.
+func (s *OrderService) Checkout(ctx context.Context, userID uint, input CheckoutInput) (models.Order, error) {
+	order := models.Order{
+		UserID:          userID,
+		Status:          models.OrderStatusPending,
+		ContactNumber:   input.ContactNumber,
+		ShippingAddress: input.ShippingAddress,
+		Items:           make([]models.OrderItem, 0, len(input.Items)),
+	}
+	order.ShippingAddress.ID = 0
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		for _, item := range input.Items {
+			product, err := tx.Products().FindByID(ctx, item.ProductID)
+			if err != nil {
+				return lookupError(err, "Product not found")
+			}
+			if !product.IsAvailable {
+				return apperror.BadRequest("Product is not available")
+			}
+			if product.Stock < item.Quantity {
+				return insufficientStock()
+			}
+
+			itemPrice := product.Price * float64(item.Quantity)
+			order.Items = append(order.Items, models.OrderItem{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				SKU:         product.SKU,
+				Category:    product.Category,
+				ImageURL:    product.ImageURL,
+				Attributes:  product.Attributes,
+				UnitPrice:   product.Price,
+				Quantity:    item.Quantity,
+				Price:       itemPrice,
+			})
+			order.TotalAmount += itemPrice
+		}
+
+		if err := tx.Orders().Create(ctx, &order); err != nil {
+			return apperror.Internal("Failed to create order").WithCause(err)
+		}
+
+		// Pick the warehouses that ship the order, one shipment each, and
+		// take the stock from them
+		lines := make([]helpers.AllocationLine, 0, len(order.Items))
+		for _, item := range order.Items {
+			lines = append(lines, helpers.AllocationLine{
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				Quantity:    item.Quantity,
+			})
+		}
+		allocations, err := tx.Orders().Allocate(ctx, order.ShippingAddress, lines, helpers.AllocationStrategyFromEnv())
+		if errors.Is(err, helpers.ErrInsufficientStock) {
+			return insufficientStock()
+		}
+		if err != nil {
+			return apperror.Internal("Failed to allocate order").WithCause(err)
+		}
+		err = tx.Orders().CreateShipments(ctx, order.ID, allocations)
+		if errors.Is(err, helpers.ErrInsufficientStock) {
+			return insufficientStock()
+		}
+		if err != nil {
+			return apperror.Internal("Failed to update product stock").WithCause(err)
+		}
+
+		if err := tx.PublishEvent(ctx, helpers.EventOrderCreated, helpers.NewOrderEventData(order, order.Items)); err != nil {
+			return apperror.Internal("Failed to record order event").WithCause(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Order{}, apperror.From(err)
+	}
+	return order, nil
+}
+
+// UserOrders lists the orders of a customer. Customers follow shipments
+// through the tracking of a single order, so they are left out here.
+func (s *OrderService) UserOrders(ctx context.Context, userID uint) ([]models.Order, error) {
+	orders, err := s.store.Orders().ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch orders").WithCause(err)
+	}
+	for i := range orders {
+		orders[i].Shipments = nil
+	}
+	return orders, nil
+}
+
+// UserOrder returns an order of a customer with its shipments
+func (s *OrderService) UserOrder(ctx context.Context, userID, orderID uint) (models.Order, error) {
+	order, err := s.store.Orders().FindForUser(ctx, userID, orderID)
+	if err != nil {
+		return models.Order{}, lookupError(err, "Order not found")
+	}
+	return order, nil
+}
+
+// Cancel cancels a pending order of a customer and returns its stock. The
+// order is locked while its status is checked, so it is cancelled once.
+func (s *OrderService) Cancel(ctx context.Context, userID, orderID uint) error {
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		order, err := tx.Orders().FindForUpdate(ctx, orderID)
+		if err != nil {
+			return lookupError(err, "Order not found")
+		}
+		if order.UserID != userID {
+			return apperror.NotFound("Order not found")
+		}
+		if order.Status != models.OrderStatusPending {
+			return apperror.BadRequest("Only pending orders can be cancelled")
+		}
+
+		order.Status = models.OrderStatusCancelled
+		if err := tx.Orders().SetStatus(ctx, order.ID, order.Status); err != nil {
+			return err
+		}
+		if err := tx.Orders().Restock(ctx, order.ID, userID); err != nil {
+			return err
+		}
+		return publishStatusChanged(ctx, tx, order, models.OrderStatusPending)
+	})
+	if err != nil {
+		return clientError(err, "Failed to cancel order")
+	}
+	return nil
+}
+
+func (s *OrderService) List(ctx context.Context) ([]models.Order, error) {
+	orders, err := s.store.Orders().List(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch orders").WithCause(err)
+	}
+	return orders, nil
+}
+
+func (s *OrderService) Get(ctx context.Context, orderID uint) (models.Order, error) {
+	order, err := s.store.Orders().FindByID(ctx, orderID)
+	if err != nil {
+		return models.Order{}, lookupError(err, "Order not found")
+	}
+	return order, nil
+}
+
+// ListByUser lists the orders of a customer with their shipments
+func (s *OrderService) ListByUser(ctx context.Context, userID uint) ([]models.Order, error) {
+	orders, err := s.store.Orders().ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperror.NotFound("Orders not found for this user").WithCause(err)
+	}
+	return orders, nil
+}
+
+func (s *OrderService) Items(ctx context.Context) ([]models.OrderItem, error) {
+	items, err := s.store.Orders().ListItems(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch order items").WithCause(err)
+	}
+	return items, nil
+}
+
+func (s *OrderService) ItemsByProduct(ctx context.Context, productID uint) ([]models.OrderItem, error) {
+	items, err := s.store.Orders().ListItemsByProduct(ctx, productID)
+	if err != nil {
+		return nil, apperror.NotFound("Order items not found for this product").WithCause(err)
+	}
+	return items, nil
+}
+
+// UpdateStatus moves an order to processing or cancels it on behalf of an
+// admin. Later statuses follow from its shipments, so neither change is
+// allowed once something has shipped. Cancelling returns the stock of the
+// order. The order is locked while the change is checked and applied.
+func (s *OrderService) UpdateStatus(ctx context.Context, orderID uint, status string, adminID uint) error {
+	if status != models.OrderStatusProcessing && status != models.OrderStatusCancelled {
+		return apperror.BadRequest("Orders are shipped and delivered through their shipments").WithCode(apperror.CodeInvalidStatusTransition)
+	}
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		order, err := tx.Orders().FindForUpdate(ctx, orderID)
+		if err != nil {
+			return lookupError(err, "Order not found")
+		}
+		previousStatus := order.Status
+
+		// Stock has already been returned for cancelled orders
+		if previousStatus == models.OrderStatusCancelled && status != models.OrderStatusCancelled {
+			return apperror.BadRequest("Cancelled orders cannot change status").WithCode(apperror.CodeInvalidStatusTransition)
+		}
+		if previousStatus != models.OrderStatusCancelled {
+			shipped, err := tx.Orders().CountShipped(ctx, order.ID)
+			if err != nil {
+				return err
+			}
+			if shipped > 0 {
+				return apperror.BadRequest("Orders with shipped items cannot change status").WithCode(apperror.CodeInvalidStatusTransition)
+			}
+		}
+
+		order.Status = status
+		if err := tx.Orders().SetStatus(ctx, order.ID, status); err != nil {
+			return err
+		}
+
+		if status == models.OrderStatusCancelled && previousStatus != models.OrderStatusCancelled {
+			if err := tx.Orders().Restock(ctx, order.ID, adminID); err != nil {
+				return err
+			}
+		}
+		return publishStatusChanged(ctx, tx, order, previousStatus)
+	})
+	if errors.Is(err, helpers.ErrNoDefaultWarehouse) {
+		return apperror.BadRequest("No default warehouse configured")
+	}
+	if err != nil {
+		return clientError(err, "Failed to update order status")
+	}
+	return nil
+}
+
+// publishStatusChanged publishes order.status_changed when the status of
+// order moved away from previous
+func publishStatusChanged(ctx context.Context, tx repository.Store, order models.Order, previous string) error {
+	if order.Status == previous {
+		return nil
+	}
+	data := helpers.NewOrderEventData(order, nil)
+	data.PreviousStatus = previous
+	return tx.PublishEvent(ctx, helpers.EventOrderStatusChanged, data)
+}
+
+// MissedEvents returns up to limit order events of types published after
+// the event with id since, for clients catching up on a stream
+func (s *OrderService) MissedEvents(ctx context.Context, since uint, types []string, limit int) ([]models.OutboxEvent, error) {
+	events, err := s.store.Events().ListSince(ctx, since, types, limit)
+	if err != nil {
+		return nil, apperror.Internal("Failed to replay events").WithCause(err)
+	}
+	return events, nil
+}
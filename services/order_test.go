@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// fakeStore keeps products, warehouse stock and orders in memory. Only the
+// methods checkout uses are implemented; the embedded interfaces are nil.
+type fakeStore struct {
+	repository.Store
+	products map[uint]models.Product
+	// available is what the warehouses hold of each product
+	available map[uint]int
+	orders    []models.Order
+	shipped   []helpers.Allocation
+	events    []string
+}
+
+func newFakeStore(products ...models.Product) *fakeStore {
+	s := &fakeStore{products: map[uint]models.Product{}, available: map[uint]int{}}
+	for _, product := range products {
+		s.products[product.ID] = product
+		s.available[product.ID] = product.Stock
+	}
+	return s
+}
+
+func (s *fakeStore) Products() repository.ProductRepository { return fakeProducts{store: s} }
+func (s *fakeStore) Orders() repository.OrderRepository     { return fakeOrders{store: s} }
+
+// Transaction does not roll back, so tests check that nothing was written
+// before a failure instead
+func (s *fakeStore) Transaction(ctx context.Context, fn func(tx repository.Store) error) error {
+	return fn(s)
+}
+
+func (s *fakeStore) PublishEvent(ctx context.Context, eventType string, data interface{}) error {
+	s.events = append(s.events, eventType)
+	return nil
+}
+
+type fakeProducts struct {
+	repository.ProductRepository
+	store *fakeStore
+}
+
+func (r fakeProducts) FindByID(ctx context.Context, id uint) (models.Product, error) {
+	product, ok := r.store.products[id]
+	if !ok {
+		return models.Product{}, repository.ErrNotFound
+	}
+	return product, nil
+}
+
+type fakeOrders struct {
+	repository.OrderRepository
+	store *fakeStore
+}
+
+func (r fakeOrders) Create(ctx context.Context, order *models.Order) error {
+	order.ID = uint(len(r.store.orders) + 1)
+	for i := range order.Items {
+		order.Items[i].ID = uint(i + 1)
+		order.Items[i].OrderID = order.ID
+	}
+	r.store.orders = append(r.store.orders, *order)
+	return nil
+}
+
+// Allocate ships everything from a single warehouse
+func (r fakeOrders) Allocate(ctx context.Context, address models.ShippingAddress, lines []helpers.AllocationLine, strategy string) ([]helpers.Allocation, error) {
+	for _, line := range lines {
+		if r.store.available[line.ProductID] < line.Quantity {
+			return nil, helpers.ErrInsufficientStock
+		}
+	}
+	return []helpers.Allocation{{WarehouseID: 1, Lines: lines}}, nil
+}
+
+func (r fakeOrders) CreateShipments(ctx context.Context, orderID uint, allocations []helpers.Allocation) error {
+	for _, allocation := range allocations {
+		for _, line := range allocation.Lines {
+			r.store.available[line.ProductID] -= line.Quantity
+		}
+	}
+	r.store.shipped = append(r.store.shipped, allocations...)
+	return nil
+}
+
+func TestCheckoutPlacesOrder(t *testing.T) {
+	store := newFakeStore(
+		models.Product{ID: 1, Name: "Kettle", SKU: "HOM-1", Price: 10, Stock: 5, IsAvailable: true},
+		models.Product{ID: 2, Name: "Green Tea", SKU: "GRO-1", Price: 2.5, Stock: 3, IsAvailable: true},
+	)
+
+	order, err := NewOrderService(store).Checkout(context.Background(), 7, CheckoutInput{
+		ContactNumber: "9800000000",
+		Items:         []CheckoutItem{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	if order.UserID != 7 || order.Status != models.OrderStatusPending {
+		t.Errorf("order belongs to user %d with status %q, want user 7 and pending", order.UserID, order.Status)
+	}
+	if order.TotalAmount != 27.5 {
+		t.Errorf("total is %v, want 27.5", order.TotalAmount)
+	}
+	if len(order.Items) != 2 {
+		t.Fatalf("order has %d items, want 2", len(order.Items))
+	}
+	if item := order.Items[0]; item.ProductName != "Kettle" || item.SKU != "HOM-1" || item.UnitPrice != 10 || item.Price != 20 {
+		t.Errorf("first item is %+v, want a snapshot of the kettle at 10 each", item)
+	}
+
+	if len(store.orders) != 1 || len(store.shipped) != 1 {
+		t.Errorf("stored %d orders and %d shipments, want 1 of each", len(store.orders), len(store.shipped))
+	}
+	if store.available[1] != 3 || store.available[2] != 0 {
+		t.Errorf("stock left is %d and %d, want 3 and 0", store.available[1], store.available[2])
+	}
+	if len(store.events) != 1 || store.events[0] != helpers.EventOrderCreated {
+		t.Errorf("published %v, want one %s event", store.events, helpers.EventOrderCreated)
+	}
+}
+
+func TestCheckoutInsufficientStock(t *testing.T) {
+	tests := []struct {
+		name      string
+		stock     int
+		available int
+	}{
+		// The product's own stock is checked before the order is created
+		{name: "product stock", stock: 1, available: 1},
+		// The warehouses may hold less than the product total says
+		{name: "warehouse stock", stock: 5, available: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newFakeStore(models.Product{ID: 1, Name: "Kettle", Price: 10, Stock: test.stock, IsAvailable: true})
+			store.available[1] = test.available
+
+			_, err := NewOrderService(store).Checkout(context.Background(), 7, CheckoutInput{
+				Items: []CheckoutItem{{ProductID: 1, Quantity: 2}},
+			})
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != apperror.CodeInsufficientStock {
+				t.Fatalf("Checkout returned %v, want an %s error", err, apperror.CodeInsufficientStock)
+			}
+			if len(store.shipped) != 0 || len(store.events) != 0 {
+				t.Errorf("shipped %v and published %v for a failed checkout", store.shipped, store.events)
+			}
+			if store.available[1] != test.available {
+				t.Errorf("stock changed from %d to %d", test.available, store.available[1])
+			}
+		})
+	}
+}
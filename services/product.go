@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"gorm.io/gorm"
+)
+
+// ProductService manages the catalog
+type ProductService struct {
+	store repository.Store
+}
+
+func NewProductService(store repository.Store) *ProductService {
+	return &ProductService{store: store}
+}
+
+func (s *ProductService) List(ctx context.Context) ([]models.Product, error) {
+	products, err := s.store.Products().List(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch products").WithCause(err)
+	}
+	return products, nil
+}
+
+func (s *ProductService) ListDeleted(ctx context.Context) ([]models.Product, error) {
+	products, err := s.store.Products().ListDeleted(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch products").WithCause(err)
+	}
+	return products, nil
+}
+
+func (s *ProductService) Get(ctx context.Context, id uint) (models.Product, error) {
+	product, err := s.store.Products().FindByID(ctx, id)
+	if err != nil {
+		return models.Product{}, lookupError(err, "Product not found")
+	}
+	return product, nil
+}
+
+// Create adds a product to the catalog. Its opening stock goes through the
+// ledger like every other change.
+func (s *ProductService) Create(ctx context.Context, product models.Product, actorID uint) (models.Product, error) {
+	product.ID = 0
+	product.DeletedAt = gorm.DeletedAt{}
+	initialStock := product.Stock
+	product.Stock = 0
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Products().Create(ctx, &product); err != nil {
+			return err
+		}
+		if initialStock != 0 {
+			movement, err := tx.Products().AdjustStock(ctx, helpers.StockChange{
+				ProductID: product.ID,
+				Delta:     initialStock,
+				Reason:    models.StockReasonAdjustment,
+				Note:      "initial stock",
+				ActorID:   actorID,
+			})
+			if err != nil {
+				return err
+			}
+			product.Stock = movement.BalanceAfter
+		}
+		return tx.PublishEvent(ctx, helpers.EventProductCreated, helpers.NewProductEventData(product))
+	})
+	if err != nil {
+		return models.Product{}, apperror.Internal("Failed to create product").WithCause(err)
+	}
+	return product, nil
+}
+
+// Update writes the non-zero fields of changes to a product. A new stock
+// level is recorded in the ledger.
+func (s *ProductService) Update(ctx context.Context, id uint, changes models.Product, actorID uint) (models.Product, error) {
+	product, err := s.Get(ctx, id)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	changes.ID = 0
+	changes.DeletedAt = gorm.DeletedAt{}
+	newStock := changes.Stock
+	changes.Stock = 0
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Products().Update(ctx, &product, changes); err != nil {
+			return err
+		}
+		if newStock != 0 {
+			if err := tx.Products().SetStock(ctx, product.ID, newStock, models.StockReasonAdjustment, "updated with product", actorID); err != nil {
+				return err
+			}
+		}
+		updated, err := tx.Products().FindByID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		product = updated
+		return tx.PublishEvent(ctx, helpers.EventProductUpdated, helpers.NewProductEventData(product))
+	})
+	if err != nil {
+		return models.Product{}, apperror.Internal("Failed to update product").WithCause(err)
+	}
+	return product, nil
+}
+
+// Delete soft-deletes a product, which stays resolvable from order history,
+// and takes it out of every cart
+func (s *ProductService) Delete(ctx context.Context, id uint) error {
+	product, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Products().Delete(ctx, &product); err != nil {
+			return err
+		}
+		if err := tx.Carts().DeleteItemsByProduct(ctx, product.ID); err != nil {
+			return err
+		}
+		return tx.PublishEvent(ctx, helpers.EventProductDeleted, helpers.NewProductEventData(product))
+	})
+	if err != nil {
+		return apperror.Internal("Failed to delete product").WithCause(err)
+	}
+	return nil
+}
+
+// Restore brings a soft-deleted product back into the catalog
+func (s *ProductService) Restore(ctx context.Context, id uint) (models.Product, error) {
+	product, err := s.store.Products().FindDeleted(ctx, id)
+	if err != nil {
+		return models.Product{}, lookupError(err, "Deleted product not found")
+	}
+	if err := s.store.Products().Restore(ctx, &product); err != nil {
+		return models.Product{}, apperror.Internal("Failed to restore product").WithCause(err)
+	}
+	return product, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// Refund records a full or partial refund of an order. The total refunded
+// can never exceed what the order cost.
+func (s *OrderService) Refund(ctx context.Context, orderID uint, amount float64, reason string, adminID uint) (models.Refund, error) {
+	amount = math.Round(amount*100) / 100
+
+	var refund models.Refund
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		order, err := tx.Orders().FindForUpdate(ctx, orderID)
+		if err != nil {
+			return lookupError(err, "Order not found")
+		}
+
+		refunded := math.Round((order.RefundedAmount+amount)*100) / 100
+		if refunded > math.Round(order.TotalAmount*100)/100 {
+			return apperror.BadRequest("Refund exceeds the amount left to refund")
+		}
+
+		refund = models.Refund{
+			OrderID:   order.ID,
+			Amount:    amount,
+			Reason:    reason,
+			CreatedBy: adminID,
+		}
+		if err := tx.Orders().AddRefund(ctx, &refund, refunded); err != nil {
+			return err
+		}
+
+		return tx.PublishEvent(ctx, helpers.EventOrderRefunded, helpers.RefundEventData{
+			RefundID:       refund.ID,
+			OrderID:        order.ID,
+			UserID:         order.UserID,
+			Amount:         refund.Amount,
+			Reason:         refund.Reason,
+			RefundedAmount: refunded,
+			TotalAmount:    order.TotalAmount,
+		})
+	})
+	if err != nil {
+		return models.Refund{}, clientError(err, "Failed to refund order")
+	}
+	return refund, nil
+}
+
+// Refunds lists the refunds of an order
+func (s *OrderService) Refunds(ctx context.Context, orderID uint) ([]models.Refund, error) {
+	refunds, err := s.store.Orders().ListRefunds(ctx, orderID)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch refunds").WithCause(err)
+	}
+	return refunds, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+var reportIntervals = map[string]bool{"day": true, "week": true, "month": true}
+
+// topReportOrders maps the ranking a report can be asked for to its ORDER BY
+var topReportOrders = map[string]string{
+	"revenue": "revenue DESC, units DESC",
+	"units":   "units DESC, revenue DESC",
+}
+
+// ReportService builds the admin sales reports
+type ReportService struct {
+	store repository.Store
+}
+
+func NewReportService(store repository.Store) *ReportService {
+	return &ReportService{store: store}
+}
+
+func reportError(err error) error {
+	return apperror.Internal("Failed to generate report").WithCause(err)
+}
+
+func checkInterval(interval string) error {
+	if !reportIntervals[interval] {
+		return apperror.BadRequest("interval must be one of day, week, month")
+	}
+	return nil
+}
+
+func topOrder(by string) (string, error) {
+	order, ok := topReportOrders[by]
+	if !ok {
+		return "", apperror.BadRequest("by must be one of revenue, units")
+	}
+	return order, nil
+}
+
+// Revenue returns revenue and order count per day, week or month
+func (s *ReportService) Revenue(ctx context.Context, r repository.ReportRange, interval string) ([]repository.RevenueBucket, error) {
+	if err := checkInterval(interval); err != nil {
+		return nil, err
+	}
+	buckets, err := s.store.Reports().Revenue(ctx, r, interval)
+	if err != nil {
+		return nil, reportError(err)
+	}
+	return buckets, nil
+}
+
+// OrdersByStatus counts orders per status, including cancelled ones
+func (s *ReportService) OrdersByStatus(ctx context.Context, r repository.ReportRange) ([]repository.StatusCount, error) {
+	counts, err := s.store.Reports().OrdersByStatus(ctx, r)
+	if err != nil {
+		return nil, reportError(err)
+	}
+	return counts, nil
+}
+
+// AverageOrderValue returns the average value of non-cancelled orders
+func (s *ReportService) AverageOrderValue(ctx context.Context, r repository.ReportRange) (repository.AverageOrderValue, error) {
+	result, err := s.store.Reports().AverageOrderValue(ctx, r)
+	if err != nil {
+		return result, reportError(err)
+	}
+	return result, nil
+}
+
+// TopProducts ranks products by revenue or units sold
+func (s *ReportService) TopProducts(ctx context.Context, r repository.ReportRange, by string, limit int) ([]repository.TopProduct, error) {
+	order, err := topOrder(by)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.store.Reports().TopProducts(ctx, r, order, limit)
+	if err != nil {
+		return nil, reportError(err)
+	}
+	return products, nil
+}
+
+// TopCategories ranks categories by revenue or units sold
+func (s *ReportService) TopCategories(ctx context.Context, r repository.ReportRange, by string, limit int) ([]repository.TopCategory, error) {
+	order, err := topOrder(by)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.store.Reports().TopCategories(ctx, r, order, limit)
+	if err != nil {
+		return nil, reportError(err)
+	}
+	return categories, nil
+}
+
+// Customers counts new and returning customers per day, week or month
+func (s *ReportService) Customers(ctx context.Context, r repository.ReportRange, interval string) ([]repository.CustomerBucket, error) {
+	if err := checkInterval(interval); err != nil {
+		return nil, err
+	}
+	buckets, err := s.store.Reports().Customers(ctx, r, interval)
+	if err != nil {
+		return nil, reportError(err)
+	}
+	return buckets, nil
+}
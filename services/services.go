@@ -0,0 +1,62 @@
+// Package services holds the business rules of the API. Services read and
+// write through the repository interfaces and report failures as
+// *apperror.Error values, so handlers only bind requests and map responses.
+package services
+
+import (
+	"errors"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// Services are the services the HTTP layer is built on
+type Services struct {
+	Users         *UserService
+	Products      *ProductService
+	Carts         *CartService
+	Orders        *OrderService
+	Warehouses    *WarehouseService
+	Webhooks      *WebhookService
+	Notifications *NotificationService
+	APIKeys       *APIKeyService
+	Audit         *AuditService
+	SigningKeys   *SigningKeyService
+	Reports       *ReportService
+}
+
+// New builds every service on top of store
+func New(store repository.Store) *Services {
+	return &Services{
+		Users:         NewUserService(store),
+		Products:      NewProductService(store),
+		Carts:         NewCartService(store),
+		Orders:        NewOrderService(store),
+		Warehouses:    NewWarehouseService(store),
+		Webhooks:      NewWebhookService(store),
+		Notifications: NewNotificationService(store),
+		APIKeys:       NewAPIKeyService(store),
+		Audit:         NewAuditService(store),
+		SigningKeys:   NewSigningKeyService(store),
+		Reports:       NewReportService(store),
+	}
+}
+
+// lookupError reports a missing record as not found with message and any
+// other failure as an internal error
+func lookupError(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound(message)
+	}
+	return apperror.Internal("Internal Server Error").WithCause(err)
+}
+
+// clientError passes on the errors meant for the client and reports any
+// other failure as an internal error with message
+func clientError(err error, message string) error {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return apperror.Internal(message).WithCause(err)
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// ShipInput is the carrier a shipment leaves with. When Items is set, only
+// those quantities are shipped and the rest of the shipment stays pending.
+type ShipInput struct {
+	Carrier        string
+	TrackingNumber string
+	TrackingURL    string
+	Items          []ShipmentQuantity
+}
+
+// ShipmentQuantity is the quantity of an order line put in a shipment
+type ShipmentQuantity struct {
+	OrderItemID uint
+	Quantity    int
+}
+
+func invalidShipmentTransition() *apperror.Error {
+	return apperror.BadRequest("Shipment cannot change to this status").WithCode(apperror.CodeInvalidStatusTransition)
+}
+
+// Shipments lists the shipments of an order
+func (s *OrderService) Shipments(ctx context.Context, orderID uint) ([]models.Shipment, error) {
+	if _, err := s.Get(ctx, orderID); err != nil {
+		return nil, err
+	}
+	shipments, err := s.store.Orders().ListShipments(ctx, orderID)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch shipments").WithCause(err)
+	}
+	return shipments, nil
+}
+
+// ShipShipment hands a pending shipment, or part of it, to a carrier and
+// updates the order status
+func (s *OrderService) ShipShipment(ctx context.Context, orderID, shipmentID uint, input ShipInput) (models.Shipment, error) {
+	var shipped models.Shipment
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		order, shipment, err := lockOrderShipment(ctx, tx, orderID, shipmentID)
+		if err != nil {
+			return err
+		}
+		if order.Status == models.OrderStatusCancelled || shipment.Status != models.ShipmentStatusPending {
+			return invalidShipmentTransition()
+		}
+
+		shipped = shipment
+		if len(input.Items) > 0 {
+			if shipped, err = splitShipment(ctx, tx, shipment, input.Items); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		shipped.Status = models.ShipmentStatusShipped
+		shipped.Carrier = input.Carrier
+		shipped.TrackingNumber = input.TrackingNumber
+		shipped.TrackingURL = input.TrackingURL
+		if shipped.TrackingURL == "" {
+			shipped.TrackingURL = helpers.TrackingURL(input.Carrier, input.TrackingNumber)
+		}
+		shipped.ShippedAt = &now
+		if err := tx.Orders().UpdateShipment(ctx, &shipped); err != nil {
+			return err
+		}
+		if err := tx.PublishEvent(ctx, helpers.EventShipmentShipped, helpers.NewShipmentEventData(shipped)); err != nil {
+			return err
+		}
+		return syncOrderStatus(ctx, tx, order)
+	})
+	if err != nil {
+		return models.Shipment{}, shipmentError(err)
+	}
+	return shipped, nil
+}
+
+// DeliverShipment marks a shipped shipment as delivered and updates the
+// order status
+func (s *OrderService) DeliverShipment(ctx context.Context, orderID, shipmentID uint) (models.Shipment, error) {
+	var delivered models.Shipment
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		order, shipment, err := lockOrderShipment(ctx, tx, orderID, shipmentID)
+		if err != nil {
+			return err
+		}
+		if shipment.Status != models.ShipmentStatusShipped {
+			return invalidShipmentTransition()
+		}
+
+		now := time.Now()
+		shipment.Status = models.ShipmentStatusDelivered
+		shipment.DeliveredAt = &now
+		if err := tx.Orders().UpdateShipment(ctx, &shipment); err != nil {
+			return err
+		}
+		if err := tx.PublishEvent(ctx, helpers.EventShipmentDelivered, helpers.NewShipmentEventData(shipment)); err != nil {
+			return err
+		}
+		delivered = shipment
+		return syncOrderStatus(ctx, tx, order)
+	})
+	if err != nil {
+		return models.Shipment{}, shipmentError(err)
+	}
+	return delivered, nil
+}
+
+// shipmentError maps the failures of a shipment change to client errors
+func shipmentError(err error) error {
+	if errors.Is(err, helpers.ErrNoDefaultWarehouse) {
+		return apperror.BadRequest("No default warehouse configured")
+	}
+	return clientError(err, "Failed to update shipment")
+}
+
+// lockOrderShipment locks the order and loads one of its shipments
+func lockOrderShipment(ctx context.Context, tx repository.Store, orderID, shipmentID uint) (models.Order, models.Shipment, error) {
+	order, err := tx.Orders().FindForUpdate(ctx, orderID)
+	if err != nil {
+		return models.Order{}, models.Shipment{}, lookupError(err, "Order not found")
+	}
+	if err := tx.Orders().EnsureShipments(ctx, &order); err != nil {
+		return models.Order{}, models.Shipment{}, err
+	}
+	shipment, err := tx.Orders().FindShipment(ctx, order.ID, shipmentID)
+	if err != nil {
+		return models.Order{}, models.Shipment{}, lookupError(err, "Shipment not found")
+	}
+	return order, shipment, nil
+}
+
+// splitShipment moves the requested quantities out of a pending shipment into
+// a new one and returns it. Shipping everything returns the shipment itself.
+func splitShipment(ctx context.Context, tx repository.Store, shipment models.Shipment, items []ShipmentQuantity) (models.Shipment, error) {
+	invalid := apperror.BadRequest("Shipment items must be lines of this shipment within their quantity")
+
+	requested := map[uint]int{}
+	for _, item := range items {
+		requested[item.OrderItemID] += item.Quantity
+	}
+
+	whole := true
+	for _, item := range shipment.Items {
+		if requested[item.OrderItemID] != item.Quantity {
+			whole = false
+		}
+	}
+	if whole && len(requested) == len(shipment.Items) {
+		return shipment, nil
+	}
+
+	split := models.Shipment{OrderID: shipment.OrderID, WarehouseID: shipment.WarehouseID, Status: models.ShipmentStatusPending}
+	for _, item := range shipment.Items {
+		quantity, ok := requested[item.OrderItemID]
+		if !ok {
+			continue
+		}
+		delete(requested, item.OrderItemID)
+		if quantity > item.Quantity {
+			return models.Shipment{}, invalid
+		}
+
+		split.Items = append(split.Items, models.ShipmentItem{
+			OrderItemID: item.OrderItemID,
+			ProductID:   item.ProductID,
+			Quantity:    quantity,
+		})
+		if err := tx.Orders().SetShipmentItemQuantity(ctx, item.ID, item.Quantity-quantity); err != nil {
+			return models.Shipment{}, err
+		}
+	}
+	if len(requested) > 0 {
+		return models.Shipment{}, invalid
+	}
+
+	if err := tx.Orders().CreateShipment(ctx, &split); err != nil {
+		return models.Shipment{}, err
+	}
+	return split, nil
+}
+
+// syncOrderStatus stores the order status derived from its shipments
+func syncOrderStatus(ctx context.Context, tx repository.Store, order models.Order) error {
+	shipments, err := tx.Orders().ListShipments(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	previous := order.Status
+	order.Status = helpers.DeriveOrderStatus(previous, shipments)
+	if order.Status == previous {
+		return nil
+	}
+	if err := tx.Orders().SetStatus(ctx, order.ID, order.Status); err != nil {
+		return err
+	}
+	return publishStatusChanged(ctx, tx, order, previous)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+	"github.com/sajagsubedi/Ecommerce-Api/signing"
+)
+
+// SigningKeyService lets admins see and replace the keys access tokens are
+// signed with, under the configured rotation policy
+type SigningKeyService struct {
+	store repository.Store
+}
+
+func NewSigningKeyService(store repository.Store) *SigningKeyService {
+	return &SigningKeyService{store: store}
+}
+
+// List lists the signing keys and their schedules, newest first
+func (s *SigningKeyService) List(ctx context.Context) ([]models.SigningKey, error) {
+	keys, err := s.store.SigningKeys().List(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch signing keys").WithCause(err)
+	}
+	return keys, nil
+}
+
+// Rotate generates a new signing key ahead of schedule. With immediate it
+// signs at once instead of after the publish lead.
+func (s *SigningKeyService) Rotate(ctx context.Context, immediate bool) (models.SigningKey, error) {
+	key, err := s.store.SigningKeys().Rotate(ctx, signing.Configured(), immediate)
+	if errors.Is(err, signing.ErrKeysManaged) {
+		return models.SigningKey{}, apperror.Conflict("Signing keys are managed through JWT_PRIVATE_KEY_FILE")
+	}
+	if err != nil {
+		return models.SigningKey{}, apperror.Internal("Failed to rotate signing key").WithCause(err)
+	}
+	return key, nil
+}
+
+// Revoke stops trusting a key at once. Every token it signed stops working.
+func (s *SigningKeyService) Revoke(ctx context.Context, kid string) error {
+	err := s.store.SigningKeys().Revoke(ctx, signing.Configured(), kid)
+	if errors.Is(err, signing.ErrLastSigningKey) {
+		return apperror.Conflict("Revoking the only signing key would stop sign-ins")
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("Signing key not found")
+	}
+	if err != nil {
+		return apperror.Internal("Failed to revoke signing key").WithCause(err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// Registration is what a new customer signs up with
+type Registration struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+// UserChanges are the fields an admin changes on a user. Empty fields are
+// left as they are.
+type UserChanges struct {
+	Name     string
+	Email    string
+	Password string
+	Role     string
+}
+
+// UserService manages accounts and signs users in
+type UserService struct {
+	store   repository.Store
+	lockout helpers.LockoutPolicy
+}
+
+func NewUserService(store repository.Store) *UserService {
+	return &UserService{store: store, lockout: helpers.LockoutPolicyFromEnv()}
+}
+
+// Register creates a customer account and its empty cart
+func (s *UserService) Register(ctx context.Context, registration Registration) (models.User, error) {
+	return s.register(ctx, registration, "user")
+}
+
+// CreateAdmin creates an admin account. Signup only creates customers, so
+// this is how the first admin is made.
+func (s *UserService) CreateAdmin(ctx context.Context, registration Registration) (models.User, error) {
+	return s.register(ctx, registration, "admin")
+}
+
+func (s *UserService) register(ctx context.Context, registration Registration, role string) (models.User, error) {
+	// Deleted accounts still own their email until they are purged
+	taken, err := s.store.Users().EmailTaken(ctx, registration.Email)
+	if err != nil {
+		return models.User{}, apperror.Internal("Internal Server Error").WithCause(err)
+	}
+	if taken {
+		return models.User{}, apperror.Conflict("User with given email already exists!")
+	}
+
+	user := models.User{Name: registration.Name, Email: registration.Email, Password: registration.Password}
+	hashedPassword, err := user.HashPassword()
+	if err != nil {
+		return models.User{}, apperror.Internal("Failed to process password").WithCause(err)
+	}
+	user.Password = hashedPassword
+	user.Role = role
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Create(ctx, &user); err != nil {
+			return err
+		}
+		cart := models.Cart{UserID: user.ID, Items: []models.CartItem{}}
+		if err := tx.Carts().Create(ctx, &cart); err != nil {
+			return err
+		}
+		return tx.PublishEvent(ctx, helpers.EventUserCreated, helpers.NewUserEventData(user))
+	})
+	if err != nil {
+		return models.User{}, apperror.Internal("Failed to register user").WithCause(err)
+	}
+	return user, nil
+}
+
+// Get returns an active user
+func (s *UserService) Get(ctx context.Context, id uint) (models.User, error) {
+	user, err := s.store.Users().FindByID(ctx, id)
+	if err != nil {
+		return models.User{}, lookupError(err, "User not found")
+	}
+	return user, nil
+}
+
+func (s *UserService) List(ctx context.Context) ([]models.User, error) {
+	users, err := s.store.Users().List(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch users").WithCause(err)
+	}
+	return users, nil
+}
+
+func (s *UserService) ListDeleted(ctx context.Context) ([]models.User, error) {
+	users, err := s.store.Users().ListDeleted(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch users").WithCause(err)
+	}
+	return users, nil
+}
+
+// UpdateProfile changes what users may edit about themselves
+func (s *UserService) UpdateProfile(ctx context.Context, id uint, name string) (models.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if name != "" {
+		user.Name = name
+	}
+	if err := s.store.Users().Save(ctx, &user); err != nil {
+		return models.User{}, apperror.Internal("Failed to update profile").WithCause(err)
+	}
+	return user, nil
+}
+
+// ChangePassword replaces the password of a user who knows the current one.
+// Users who have never set a password do not need oldPassword.
+func (s *UserService) ChangePassword(ctx context.Context, id uint, oldPassword, newPassword string) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !user.PasswordUnset && !user.ComparePassword(oldPassword) {
+		return apperror.Unauthorized("Invalid credentials!")
+	}
+
+	if err := setPassword(&user, newPassword); err != nil {
+		return err
+	}
+	if err := s.store.Users().Save(ctx, &user); err != nil {
+		return apperror.Internal("Failed to update password").WithCause(err)
+	}
+	return nil
+}
+
+// Update applies an admin's changes to a user
+func (s *UserService) Update(ctx context.Context, id uint, changes UserChanges) (models.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if changes.Email != "" && changes.Email != user.Email {
+		taken, err := s.store.Users().EmailTaken(ctx, changes.Email)
+		if err != nil {
+			return models.User{}, apperror.Internal("Internal Server Error").WithCause(err)
+		}
+		if taken {
+			return models.User{}, apperror.Conflict("Email already exists!")
+		}
+		user.Email = changes.Email
+	}
+	if changes.Name != "" {
+		user.Name = changes.Name
+	}
+	if changes.Password != "" {
+		if err := setPassword(&user, changes.Password); err != nil {
+			return models.User{}, err
+		}
+	}
+	if changes.Role != "" {
+		user.Role = changes.Role
+	}
+
+	if err := s.store.Users().Save(ctx, &user); err != nil {
+		return models.User{}, apperror.Internal("Failed to update user").WithCause(err)
+	}
+	return user, nil
+}
+
+// Delete soft-deletes a user. The cart and orders are kept so the user can
+// be restored. The purge job later removes the user and their cart, but
+// never users who have placed orders, so order history stays intact.
+func (s *UserService) Delete(ctx context.Context, id uint) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Delete(ctx, &user); err != nil {
+			return err
+		}
+		return tx.PublishEvent(ctx, helpers.EventUserDeleted, helpers.NewUserEventData(user))
+	})
+	if err != nil {
+		return apperror.Internal("Failed to delete user").WithCause(err)
+	}
+	return nil
+}
+
+// Restore reactivates a soft-deleted user
+func (s *UserService) Restore(ctx context.Context, id uint) (models.User, error) {
+	user, err := s.store.Users().FindDeleted(ctx, id)
+	if err != nil {
+		return models.User{}, lookupError(err, "Deleted user not found")
+	}
+	if err := s.store.Users().Restore(ctx, &user); err != nil {
+		return models.User{}, apperror.Internal("Failed to restore user").WithCause(err)
+	}
+	return user, nil
+}
+
+// ResetPassword sets a new password for the user with email and lifts any
+// sign-in lockout, for when the user cannot change it themselves
+func (s *UserService) ResetPassword(ctx context.Context, email, password string) (models.User, error) {
+	user, err := s.store.Users().FindByEmail(ctx, email)
+	if err != nil {
+		return models.User{}, lookupError(err, "User not found")
+	}
+	if err := setPassword(&user, password); err != nil {
+		return models.User{}, err
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	if err := s.store.Users().Save(ctx, &user); err != nil {
+		return models.User{}, apperror.Internal("Failed to update password").WithCause(err)
+	}
+	return user, nil
+}
+
+// setPassword stores the hash of password on user
+func setPassword(user *models.User, password string) error {
+	user.Password = password
+	hashedPassword, err := user.HashPassword()
+	if err != nil {
+		return apperror.Internal("Failed to process password").WithCause(err)
+	}
+	user.Password = hashedPassword
+	user.PasswordUnset = false
+	return nil
+}
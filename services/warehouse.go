@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// WarehouseUpdate holds the fields of a warehouse to change; nil fields are
+// left as they are
+type WarehouseUpdate struct {
+	Name      *string
+	Street    *string
+	City      *string
+	State     *string
+	ZipCode   *string
+	Country   *string
+	Priority  *int
+	IsDefault *bool
+	IsActive  *bool
+}
+
+// StockTransfer is stock of a product moved between two warehouses
+type StockTransfer struct {
+	ProductID       uint
+	FromWarehouseID uint
+	ToWarehouseID   uint
+	Quantity        int
+	Note            string
+}
+
+// WarehouseService manages the warehouses and the stock moved between them
+type WarehouseService struct {
+	store repository.Store
+}
+
+func NewWarehouseService(store repository.Store) *WarehouseService {
+	return &WarehouseService{store: store}
+}
+
+func (s *WarehouseService) List(ctx context.Context) ([]models.Warehouse, error) {
+	warehouses, err := s.store.Warehouses().List(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch warehouses").WithCause(err)
+	}
+	return warehouses, nil
+}
+
+// Create adds a warehouse. The first warehouse always becomes the default
+// one, and only one warehouse is ever the default.
+func (s *WarehouseService) Create(ctx context.Context, warehouse models.Warehouse) (models.Warehouse, error) {
+	warehouse.ID = 0
+	warehouse.IsActive = true
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		taken, err := tx.Warehouses().CodeTaken(ctx, warehouse.Code)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return apperror.Conflict("Warehouse code already exists")
+		}
+
+		count, err := tx.Warehouses().Count(ctx)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			warehouse.IsDefault = true
+		}
+
+		if err := tx.Warehouses().Create(ctx, &warehouse); err != nil {
+			return err
+		}
+		if warehouse.IsDefault {
+			return tx.Warehouses().ClearDefault(ctx, warehouse.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Warehouse{}, clientError(err, "Failed to create warehouse")
+	}
+	return warehouse, nil
+}
+
+// Update edits a warehouse. The default warehouse cannot be deactivated or
+// unset directly; another warehouse is made the default instead.
+func (s *WarehouseService) Update(ctx context.Context, id uint, update WarehouseUpdate) (models.Warehouse, error) {
+	warehouse, err := s.store.Warehouses().FindByID(ctx, id)
+	if err != nil {
+		return models.Warehouse{}, lookupError(err, "Warehouse not found")
+	}
+
+	makeDefault := update.IsDefault != nil && *update.IsDefault
+	deactivate := update.IsActive != nil && !*update.IsActive
+	if warehouse.IsDefault && ((update.IsDefault != nil && !*update.IsDefault) || deactivate) {
+		return models.Warehouse{}, apperror.BadRequest("Make another warehouse the default first")
+	}
+	if makeDefault && deactivate {
+		return models.Warehouse{}, apperror.BadRequest("The default warehouse must be active")
+	}
+
+	updates := map[string]interface{}{}
+	if update.Name != nil {
+		updates["name"] = *update.Name
+	}
+	if update.Street != nil {
+		updates["street"] = *update.Street
+	}
+	if update.City != nil {
+		updates["city"] = *update.City
+	}
+	if update.State != nil {
+		updates["state"] = *update.State
+	}
+	if update.ZipCode != nil {
+		updates["zip_code"] = *update.ZipCode
+	}
+	if update.Country != nil {
+		updates["country"] = *update.Country
+	}
+	if update.Priority != nil {
+		updates["priority"] = *update.Priority
+	}
+	if update.IsDefault != nil {
+		updates["is_default"] = *update.IsDefault
+	}
+	if update.IsActive != nil {
+		updates["is_active"] = *update.IsActive
+	}
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if len(updates) > 0 {
+			if err := tx.Warehouses().Update(ctx, &warehouse, updates); err != nil {
+				return err
+			}
+		}
+		if makeDefault {
+			return tx.Warehouses().ClearDefault(ctx, warehouse.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Warehouse{}, apperror.Internal("Failed to update warehouse").WithCause(err)
+	}
+
+	warehouse, err = s.store.Warehouses().FindByID(ctx, warehouse.ID)
+	if err != nil {
+		return models.Warehouse{}, apperror.Internal("Failed to fetch updated warehouse").WithCause(err)
+	}
+	return warehouse, nil
+}
+
+// Stock lists the stock levels held in a warehouse
+func (s *WarehouseService) Stock(ctx context.Context, id uint) ([]models.WarehouseStock, error) {
+	if _, err := s.store.Warehouses().FindByID(ctx, id); err != nil {
+		return nil, lookupError(err, "Warehouse not found")
+	}
+	stocks, err := s.store.Warehouses().ListStock(ctx, id)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch warehouse stock").WithCause(err)
+	}
+	return stocks, nil
+}
+
+// Transfer moves stock of a product from one warehouse to another
+func (s *WarehouseService) Transfer(ctx context.Context, transfer StockTransfer, adminID uint) ([]models.StockMovement, error) {
+	count, err := s.store.Warehouses().CountByIDs(ctx, []uint{transfer.FromWarehouseID, transfer.ToWarehouseID})
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch warehouses").WithCause(err)
+	}
+	if count != 2 {
+		return nil, apperror.NotFound("Warehouse not found")
+	}
+
+	var movements []models.StockMovement
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		reference := fmt.Sprintf("transfer:%d-%d", transfer.FromWarehouseID, transfer.ToWarehouseID)
+		movements, err = tx.Warehouses().TransferStock(ctx, transfer.ProductID, transfer.FromWarehouseID, transfer.ToWarehouseID,
+			transfer.Quantity, reference, transfer.Note, adminID)
+		return err
+	})
+	if errors.Is(err, helpers.ErrInsufficientStock) {
+		return nil, apperror.BadRequest("Insufficient stock in source warehouse").WithCode(apperror.CodeInsufficientStock)
+	}
+	if err != nil {
+		return nil, lookupError(err, "Product not found")
+	}
+	return movements, nil
+}
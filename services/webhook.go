@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/apperror"
+	"github.com/sajagsubedi/Ecommerce-Api/helpers"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"github.com/sajagsubedi/Ecommerce-Api/repository"
+)
+
+// WebhookChanges holds the fields of a webhook endpoint to set; nil fields
+// are left as they are
+type WebhookChanges struct {
+	URL         *string
+	Description *string
+	Events      []string
+	IsActive    *bool
+}
+
+// validate checks the URL scheme and event names
+func (changes WebhookChanges) validate() error {
+	if changes.URL != nil {
+		parsed, err := url.Parse(*changes.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return apperror.BadRequest("Webhook URL must be an http or https URL")
+		}
+	}
+	for _, event := range changes.Events {
+		if event != "*" && !helpers.IsEventType(event) {
+			return apperror.BadRequest("Unknown event type: " + event)
+		}
+	}
+	return nil
+}
+
+// WebhookService manages webhook endpoints and their delivery log
+type WebhookService struct {
+	store repository.Store
+}
+
+func NewWebhookService(store repository.Store) *WebhookService {
+	return &WebhookService{store: store}
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	endpoints, err := s.store.Webhooks().List(ctx)
+	if err != nil {
+		return nil, apperror.Internal("Failed to fetch webhooks").WithCause(err)
+	}
+	return endpoints, nil
+}
+
+func (s *WebhookService) get(ctx context.Context, id uint) (models.WebhookEndpoint, error) {
+	endpoint, err := s.store.Webhooks().FindByID(ctx, id)
+	if err != nil {
+		return models.WebhookEndpoint{}, lookupError(err, "Webhook not found")
+	}
+	return endpoint, nil
+}
+
+// Create registers an endpoint and returns it with its signing secret,
+// which is not shown again
+func (s *WebhookService) Create(ctx context.Context, changes WebhookChanges, adminID uint) (models.WebhookEndpoint, string, error) {
+	if changes.URL == nil || len(changes.Events) == 0 {
+		return models.WebhookEndpoint{}, "", apperror.BadRequest("url and events are required")
+	}
+	if err := changes.validate(); err != nil {
+		return models.WebhookEndpoint{}, "", err
+	}
+
+	secret, err := helpers.GenerateWebhookSecret()
+	if err != nil {
+		return models.WebhookEndpoint{}, "", apperror.Internal("Failed to generate webhook secret").WithCause(err)
+	}
+
+	endpoint := models.WebhookEndpoint{
+		URL:       *changes.URL,
+		Secret:    secret,
+		Events:    changes.Events,
+		IsActive:  true,
+		CreatedBy: adminID,
+	}
+	if changes.Description != nil {
+		endpoint.Description = *changes.Description
+	}
+	if changes.IsActive != nil {
+		endpoint.IsActive = *changes.IsActive
+	}
+
+	if err := s.store.Webhooks().Create(ctx, &endpoint); err != nil {
+		return models.WebhookEndpoint{}, "", apperror.Internal("Failed to create webhook").WithCause(err)
+	}
+	return endpoint, secret, nil
+}
+
+// Update changes the URL, description, events or state of an endpoint
+func (s *WebhookService) Update(ctx context.Context, id uint, changes WebhookChanges) (models.WebhookEndpoint, error) {
+	if changes.Events != nil && len(changes.Events) == 0 {
+		return models.WebhookEndpoint{}, apperror.BadRequest("A webhook must subscribe to at least one event")
+	}
+	if err := changes.validate(); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+
+	endpoint, err := s.get(ctx, id)
+	if err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+
+	updates := map[string]interface{}{}
+	if changes.URL != nil {
+		updates["url"] = *changes.URL
+	}
+	if changes.Description != nil {
+		updates["description"] = *changes.Description
+	}
+	if changes.Events != nil {
+		updates["events"] = models.StringList(changes.Events)
+	}
+	if changes.IsActive != nil {
+		updates["is_active"] = *changes.IsActive
+	}
+	if len(updates) > 0 {
+		if err := s.store.Webhooks().Update(ctx, &endpoint, updates); err != nil {
+			return models.WebhookEndpoint{}, apperror.Internal("Failed to update webhook").WithCause(err)
+		}
+	}
+
+	endpoint, err = s.store.Webhooks().FindByID(ctx, endpoint.ID)
+	if err != nil {
+		return models.WebhookEndpoint{}, apperror.Internal("Failed to fetch updated webhook").WithCause(err)
+	}
+	return endpoint, nil
+}
+
+// RotateSecret replaces the signing secret of an endpoint and returns the
+// new one
+func (s *WebhookService) RotateSecret(ctx context.Context, id uint) (string, error) {
+	endpoint, err := s.get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := helpers.GenerateWebhookSecret()
+	if err != nil {
+		return "", apperror.Internal("Failed to generate webhook secret").WithCause(err)
+	}
+	if err := s.store.Webhooks().Update(ctx, &endpoint, map[string]interface{}{"secret": secret}); err != nil {
+		return "", apperror.Internal("Failed to rotate webhook secret").WithCause(err)
+	}
+	return secret, nil
+}
+
+// Delete removes an endpoint together with its delivery log
+func (s *WebhookService) Delete(ctx context.Context, id uint) error {
+	endpoint, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Webhooks().Delete(ctx, &endpoint); err != nil {
+		return apperror.Internal("Failed to delete webhook").WithCause(err)
+	}
+	return nil
+}
+
+// Deliveries returns a page of the delivery log, newest first, with the
+// number of deliveries matching filter
+func (s *WebhookService) Deliveries(ctx context.Context, filter repository.DeliveryFilter, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	deliveries, total, err := s.store.Webhooks().ListDeliveries(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apperror.Internal("Failed to fetch webhook deliveries").WithCause(err)
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver queues a delivery again as a new entry of the log, keeping the
+// original attempt for reference
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID uint) (models.WebhookDelivery, error) {
+	original, err := s.store.Webhooks().FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, lookupError(err, "Webhook delivery not found")
+	}
+
+	delivery := models.WebhookDelivery{
+		EndpointID:    original.EndpointID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.store.Webhooks().CreateDelivery(ctx, &delivery); err != nil {
+		return models.WebhookDelivery{}, apperror.Internal("Failed to queue webhook delivery").WithCause(err)
+	}
+	return delivery, nil
+}
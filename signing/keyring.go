@@ -0,0 +1,160 @@
+// Package signing manages the asymmetric keys that sign access tokens:
+// loading them from the database, choosing the active one, rotating them
+// and publishing the public halves as a JWKS.
+package signing
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+)
+
+// A token naming an unknown key reloads the keys, but no more often than
+// this
+const unknownKeyReloadInterval = 30 * time.Second
+
+var ErrNoSigningKey = errors.New("no active JWT signing key")
+
+type loadedKey struct {
+	record  models.SigningKey
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// Keyring holds the keys that are currently published
+type Keyring struct {
+	mu       sync.RWMutex
+	db       *gorm.DB
+	keys     []*loadedKey
+	loadedAt time.Time
+}
+
+// Default is the keyring tokens are signed and verified with
+var Default = &Keyring{}
+
+// Load reads every key that has not expired
+func (k *Keyring) Load(ctx context.Context, db *gorm.DB) error {
+	var records []models.SigningKey
+	if err := db.WithContext(ctx).Where("expires_at > ?", time.Now()).Order("activates_at DESC, id DESC").Find(&records).Error; err != nil {
+		return err
+	}
+
+	keys := make([]*loadedKey, 0, len(records))
+	for _, record := range records {
+		private, err := ParsePrivateKey([]byte(record.PrivateKey))
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", record.Kid, err)
+		}
+		keys = append(keys, &loadedKey{record: record, private: private, public: private.Public()})
+	}
+
+	k.mu.Lock()
+	k.db = db
+	k.keys = keys
+	k.loadedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// signer returns the newest key that is allowed to sign at now
+func (k *Keyring) signer(now time.Time) *loadedKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		if !now.Before(key.record.ActivatesAt) && now.Before(key.record.RetiresAt) {
+			return key
+		}
+	}
+	return nil
+}
+
+// HasSigningKey reports whether a key can sign tokens right now
+func (k *Keyring) HasSigningKey() bool {
+	return k.signer(time.Now()) != nil
+}
+
+// Sign signs claims with the active key and names it in the kid header
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	key := k.signer(time.Now())
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(signingMethod(key.record.Algorithm), claims)
+	token.Header["kid"] = key.record.Kid
+	return token.SignedString(key.private)
+}
+
+func (k *Keyring) lookup(kid string, now time.Time) *loadedKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		if key.record.Kid == kid && now.Before(key.record.ExpiresAt) {
+			return key
+		}
+	}
+	return nil
+}
+
+// Keyfunc finds the key a token was signed with, for jwt.Parse. Keys another
+// instance created since the last load are picked up on demand.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key ID")
+	}
+
+	now := time.Now()
+	key := k.lookup(kid, now)
+	if key == nil {
+		k.mu.RLock()
+		db, stale := k.db, now.Sub(k.loadedAt) > unknownKeyReloadInterval
+		k.mu.RUnlock()
+
+		if db != nil && stale {
+			if err := k.Load(context.Background(), db); err != nil {
+				return nil, err
+			}
+			key = k.lookup(kid, now)
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.record.Algorithm {
+		return nil, fmt.Errorf("key %q does not sign with %s", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// JWKS returns the public keys that tokens may be signed with, including
+// keys about to be activated and retired keys still in their grace period
+func (k *Keyring) JWKS() (JSONWebKeySet, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	now := time.Now()
+	for _, key := range k.keys {
+		if !now.Before(key.record.ExpiresAt) {
+			continue
+		}
+		jwk, err := publicJWK(key.record.Kid, key.public)
+		if err != nil {
+			return set, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set, nil
+}
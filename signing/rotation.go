@@ -0,0 +1,315 @@
+package signing
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sajagsubedi/Ecommerce-Api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultKeyLifetime    = 30 * 24 * time.Hour
+	defaultKeyGrace       = 7*24*time.Hour + time.Hour
+	defaultKeyPublishLead = time.Hour
+	defaultKeyCheck       = time.Minute
+)
+
+// neverRetires marks keys imported from a file, which are only replaced by
+// importing another one
+var neverRetires = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	ErrLastSigningKey = errors.New("revoking the only signing key would stop sign-ins")
+	ErrKeysManaged    = errors.New("signing keys are managed through JWT_PRIVATE_KEY_FILE")
+)
+
+// configured is the policy the keys were bootstrapped with
+var configured Policy
+
+// Configured returns the policy passed to Bootstrap
+func Configured() Policy {
+	return configured
+}
+
+// Policy controls where signing keys come from and how they are rotated
+type Policy struct {
+	// Algorithm is used for generated keys; imported keys keep their own
+	Algorithm string
+	// KeyFile is a PEM private key to sign with, managed outside the API
+	KeyFile string
+	// Generate lets the API create and rotate its own keys
+	Generate bool
+	// Lifetime is how long a generated key signs tokens
+	Lifetime time.Duration
+	// Grace is how long a retired key still verifies tokens. It must cover
+	// the token lifetime.
+	Grace time.Duration
+	// PublishLead is how long a new key is in the JWKS before it signs, so
+	// other services can fetch it first
+	PublishLead time.Duration
+	// CheckInterval is how often keys are reloaded and rotation is checked
+	CheckInterval time.Duration
+}
+
+// PolicyFromEnv reads JWT_SIGNING_ALGORITHM ("RS256" or "EdDSA"),
+// JWT_PRIVATE_KEY_FILE, JWT_GENERATE_KEYS ("true"), and JWT_KEY_LIFETIME,
+// JWT_KEY_GRACE, JWT_KEY_PUBLISH_LEAD and JWT_KEY_CHECK_INTERVAL (Go
+// durations such as "720h")
+func PolicyFromEnv() (Policy, error) {
+	policy := Policy{
+		Algorithm:     AlgorithmRS256,
+		KeyFile:       os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		Generate:      os.Getenv("JWT_GENERATE_KEYS") == "true",
+		Lifetime:      defaultKeyLifetime,
+		Grace:         defaultKeyGrace,
+		PublishLead:   defaultKeyPublishLead,
+		CheckInterval: defaultKeyCheck,
+	}
+
+	if algorithm := os.Getenv("JWT_SIGNING_ALGORITHM"); algorithm != "" {
+		if algorithm != AlgorithmRS256 && algorithm != AlgorithmEdDSA {
+			return policy, fmt.Errorf("unsupported JWT_SIGNING_ALGORITHM %q", algorithm)
+		}
+		policy.Algorithm = algorithm
+	}
+	if lifetime, err := time.ParseDuration(os.Getenv("JWT_KEY_LIFETIME")); err == nil && lifetime > 0 {
+		policy.Lifetime = lifetime
+	}
+	if grace, err := time.ParseDuration(os.Getenv("JWT_KEY_GRACE")); err == nil && grace >= 0 {
+		policy.Grace = grace
+	}
+	if lead, err := time.ParseDuration(os.Getenv("JWT_KEY_PUBLISH_LEAD")); err == nil && lead >= 0 {
+		policy.PublishLead = lead
+	}
+	if interval, err := time.ParseDuration(os.Getenv("JWT_KEY_CHECK_INTERVAL")); err == nil && interval > 0 {
+		policy.CheckInterval = interval
+	}
+
+	if policy.KeyFile == "" && !policy.Generate {
+		return policy, errors.New("no JWT signing key configured: set JWT_PRIVATE_KEY_FILE or JWT_GENERATE_KEYS=true")
+	}
+	return policy, nil
+}
+
+// lockKeys serialises key changes between instances for the rest of tx
+func lockKeys(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext('signing_keys'))").Error
+}
+
+// retireOthers stops every other key from signing after at, keeping them
+// for verification during the grace period
+func retireOthers(tx *gorm.DB, keep uint, at time.Time, grace time.Duration) error {
+	return tx.Model(&models.SigningKey{}).
+		Where("id <> ? AND retires_at > ?", keep, at).
+		Updates(map[string]interface{}{
+			"retires_at": at,
+			"expires_at": at.Add(grace),
+		}).Error
+}
+
+// Bootstrap makes sure a key can sign tokens and loads the keyring. A key
+// file that changed since the last start replaces the previous key.
+func Bootstrap(ctx context.Context, db *gorm.DB, policy Policy) error {
+	configured = policy
+	if policy.KeyFile != "" {
+		if err := importKeyFile(ctx, db, policy); err != nil {
+			return err
+		}
+	}
+	if policy.Generate {
+		if err := RotateIfDue(ctx, db, policy); err != nil {
+			return err
+		}
+	}
+
+	if err := Default.Load(ctx, db); err != nil {
+		return err
+	}
+	if !Default.HasSigningKey() {
+		return ErrNoSigningKey
+	}
+	return nil
+}
+
+func importKeyFile(ctx context.Context, db *gorm.DB, policy Policy) error {
+	data, err := os.ReadFile(policy.KeyFile)
+	if err != nil {
+		return fmt.Errorf("read JWT_PRIVATE_KEY_FILE: %w", err)
+	}
+	private, err := ParsePrivateKey(data)
+	if err != nil {
+		return fmt.Errorf("parse JWT_PRIVATE_KEY_FILE: %w", err)
+	}
+	algorithm, err := algorithmFor(private)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockKeys(tx); err != nil {
+			return err
+		}
+
+		kid, err := KeyID(private.Public())
+		if err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&models.SigningKey{}).Where("kid = ?", kid).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		key, err := newKeyRecord(private, algorithm, time.Now(), neverRetires, neverRetires)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&key).Error; err != nil {
+			return err
+		}
+		return retireOthers(tx, key.ID, key.ActivatesAt, policy.Grace)
+	})
+}
+
+// newKeyRecord stores a private key with its schedule
+func newKeyRecord(private crypto.Signer, algorithm string, activates, retires, expires time.Time) (models.SigningKey, error) {
+	kid, err := KeyID(private.Public())
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+	privatePEM, err := EncodePrivateKey(private)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+	publicPEM, err := EncodePublicKey(private.Public())
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	return models.SigningKey{
+		Kid:         kid,
+		Algorithm:   algorithm,
+		PrivateKey:  privatePEM,
+		PublicKey:   publicPEM,
+		ActivatesAt: activates,
+		RetiresAt:   retires,
+		ExpiresAt:   expires,
+	}, nil
+}
+
+// Rotate generates a new key. It signs after the publish lead, or at once
+// when immediate, and every older key retires when it takes over.
+func Rotate(ctx context.Context, db *gorm.DB, policy Policy, immediate bool) (models.SigningKey, error) {
+	var key models.SigningKey
+	if !policy.Generate {
+		return key, ErrKeysManaged
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockKeys(tx); err != nil {
+			return err
+		}
+		var err error
+		key, err = rotate(tx, policy, immediate)
+		return err
+	})
+	if err != nil {
+		return key, err
+	}
+	return key, Default.Load(ctx, db)
+}
+
+func rotate(tx *gorm.DB, policy Policy, immediate bool) (models.SigningKey, error) {
+	private, err := GenerateKey(policy.Algorithm)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	activates := time.Now()
+	if !immediate {
+		activates = activates.Add(policy.PublishLead)
+	}
+	retires := activates.Add(policy.Lifetime)
+	key, err := newKeyRecord(private, policy.Algorithm, activates, retires, retires.Add(policy.Grace))
+	if err != nil {
+		return key, err
+	}
+	if err := tx.Create(&key).Error; err != nil {
+		return key, err
+	}
+	return key, retireOthers(tx, key.ID, activates, policy.Grace)
+}
+
+// RotateIfDue generates the next key once the active one is within the
+// publish lead of retiring, or at once when nothing can sign
+func RotateIfDue(ctx context.Context, db *gorm.DB, policy Policy) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockKeys(tx); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var active, upcoming int64
+		if err := tx.Model(&models.SigningKey{}).Where("activates_at <= ? AND retires_at > ?", now, now).Count(&active).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SigningKey{}).Where("retires_at > ?", now.Add(policy.PublishLead)).Count(&upcoming).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case active == 0:
+			_, err := rotate(tx, policy, true)
+			return err
+		case upcoming == 0:
+			_, err := rotate(tx, policy, false)
+			return err
+		}
+		return nil
+	})
+}
+
+// Revoke stops trusting a key at once, for example after it leaked. Tokens
+// it signed stop working. When it was the signing key a new one is generated
+// if the policy allows it.
+func Revoke(ctx context.Context, db *gorm.DB, policy Policy, kid string) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockKeys(tx); err != nil {
+			return err
+		}
+
+		var key models.SigningKey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("kid = ? AND expires_at > ?", kid, time.Now()).First(&key).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var others int64
+		if err := tx.Model(&models.SigningKey{}).Where("id <> ? AND activates_at <= ? AND retires_at > ?", key.ID, now, now).Count(&others).Error; err != nil {
+			return err
+		}
+		if others == 0 && !policy.Generate {
+			return ErrLastSigningKey
+		}
+
+		if err := tx.Model(&key).Updates(map[string]interface{}{"retires_at": now, "expires_at": now}).Error; err != nil {
+			return err
+		}
+		if others == 0 {
+			_, err := rotate(tx, policy, true)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return Default.Load(ctx, db)
+}